@@ -0,0 +1,64 @@
+package adjustment
+
+import (
+	"time"
+)
+
+// Direction tells whether an adjustment credits or debits the wallet.
+type Direction string
+
+const (
+	DirectionCredit Direction = "credit"
+	DirectionDebit  Direction = "debit"
+)
+
+// Status is the state of an adjustment in the maker-checker workflow.
+// Adjustments start as pending and are reviewed exactly once.
+type Status string
+
+const (
+	// StatusPending adjustments await the review of a second operator.
+	StatusPending Status = "pending"
+	// StatusApproved adjustments were approved and applied to the wallet.
+	StatusApproved Status = "approved"
+	// StatusRejected adjustments were rejected and never applied.
+	StatusRejected Status = "rejected"
+	// StatusFailed adjustments were approved but the wallet refused the change, e.g. for insufficient funds.
+	StatusFailed Status = "failed"
+)
+
+type Adjustment struct {
+	ID                string     `json:"id" db:"id"`
+	TenantID          string     `json:"tenant_id" db:"tenant_id"`
+	WalletID          string     `json:"wallet_id" db:"wallet_id"`
+	Direction         Direction  `json:"direction" db:"direction"`
+	Amount            float64    `json:"amount" db:"amount"`
+	Currency          string     `json:"currency" db:"currency"`
+	Reason            string     `json:"reason" db:"reason"`
+	EvidenceReference string     `json:"evidence_reference" db:"evidence_reference"`
+	Status            Status     `json:"status" db:"status"`
+	ProposedBy        string     `json:"proposed_by" db:"proposed_by"`
+	ProposedAt        time.Time  `json:"proposed_at" db:"proposed_at"`
+	ReviewedBy        string     `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt        *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	ReviewComment     string     `json:"review_comment,omitempty" db:"review_comment"`
+	FailureReason     string     `json:"failure_reason,omitempty" db:"failure_reason"`
+	// WalletVersion is the version of the wallet once an approved adjustment is applied, not stored.
+	WalletVersion int64 `json:"-" db:"-"`
+}
+
+// Proposal is the adjustment an operator asks a second operator to approve.
+type Proposal struct {
+	WalletID          string
+	Direction         Direction
+	Amount            float64
+	Reason            string
+	EvidenceReference string
+	ProposedBy        string
+}
+
+// Filter narrows down the adjustments returned by List. Empty fields match every adjustment.
+type Filter struct {
+	WalletID string
+	Status   Status
+}
@@ -0,0 +1,278 @@
+package adjustment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/database"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
+)
+
+const adjustmentColumns = `id, tenant_id, wallet_id, direction, amount, currency, reason, evidence_reference, status,
+              proposed_by, proposed_at, reviewed_by, reviewed_at, review_comment, failure_reason`
+
+type Repository interface {
+	// WithinTx runs fn in a database transaction, see database.RunInTx.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Create(ctx context.Context, adjustment *Adjustment) error
+	Get(ctx context.Context, id string) (*Adjustment, error)
+	List(ctx context.Context, filter Filter) ([]*Adjustment, error)
+	// Review moves a pending adjustment to status. It returns ErrNotPending when the adjustment
+	// was reviewed in the meantime, so an adjustment is never approved twice.
+	Review(ctx context.Context, id string, status Status, reviewedBy string, comment string) (*Adjustment, error)
+	// MarkFailed records that an approved adjustment could not be applied.
+	MarkFailed(ctx context.Context, id string, reason string) (*Adjustment, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.RunInTx(ctx, r.db, fn)
+}
+
+// currentTenantID returns the tenant every query must be scoped to.
+func currentTenantID(ctx context.Context) (string, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return t.ID, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAdjustment(row rowScanner) (*Adjustment, error) {
+	var (
+		adjustment    Adjustment
+		reviewedBy    sql.NullString
+		reviewedAt    sql.NullTime
+		reviewComment sql.NullString
+		failureReason sql.NullString
+	)
+
+	err := row.Scan(&adjustment.ID, &adjustment.TenantID, &adjustment.WalletID, &adjustment.Direction,
+		&adjustment.Amount, &adjustment.Currency, &adjustment.Reason, &adjustment.EvidenceReference,
+		&adjustment.Status, &adjustment.ProposedBy, &adjustment.ProposedAt, &reviewedBy, &reviewedAt,
+		&reviewComment, &failureReason)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment.ReviewedBy = reviewedBy.String
+	adjustment.ReviewComment = reviewComment.String
+	adjustment.FailureReason = failureReason.String
+
+	if reviewedAt.Valid {
+		adjustment.ReviewedAt = &reviewedAt.Time
+	}
+
+	return &adjustment, nil
+}
+
+func (r *repository) Create(ctx context.Context, adjustment *Adjustment) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	adjustment.ID = uuid.New().String()
+	adjustment.TenantID = tenantID
+
+	query := `INSERT INTO adjustments (id, tenant_id, wallet_id, direction, amount, currency, reason,
+                  evidence_reference, status, proposed_by, proposed_at)
+              VALUES (@id, @tenant_id, @wallet_id, @direction, @amount, @currency, @reason,
+                  @evidence_reference, @status, @proposed_by, @proposed_at)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "adjustment.Repository/Create", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("id", adjustment.ID),
+		sql.Named("tenant_id", adjustment.TenantID),
+		sql.Named("wallet_id", adjustment.WalletID),
+		sql.Named("direction", string(adjustment.Direction)),
+		sql.Named("amount", adjustment.Amount),
+		sql.Named("currency", adjustment.Currency),
+		sql.Named("reason", adjustment.Reason),
+		sql.Named("evidence_reference", adjustment.EvidenceReference),
+		sql.Named("status", string(adjustment.Status)),
+		sql.Named("proposed_by", adjustment.ProposedBy),
+		sql.Named("proposed_at", adjustment.ProposedAt),
+	)
+	if err != nil {
+		return errors.New("failed to insert adjustment into database: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *repository) Get(ctx context.Context, id string) (_ *Adjustment, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + adjustmentColumns + `
+              FROM adjustments WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "adjustment.Repository/Get", query)
+	defer func() { tracing.End(span, err) }()
+
+	adjustment, err := scanAdjustment(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAdjustmentNotFound
+		}
+		return nil, errors.New("failed to retrieve adjustment: " + err.Error())
+	}
+
+	return adjustment, nil
+}
+
+func (r *repository) List(ctx context.Context, filter Filter) (_ []*Adjustment, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []string{"tenant_id = @tenant_id"}
+	args := []interface{}{sql.Named("tenant_id", tenantID)}
+
+	if filter.WalletID != "" {
+		conditions = append(conditions, "wallet_id = @wallet_id")
+		args = append(args, sql.Named("wallet_id", filter.WalletID))
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "status = @status")
+		args = append(args, sql.Named("status", string(filter.Status)))
+	}
+
+	query := `SELECT ` + adjustmentColumns + `
+              FROM adjustments WHERE ` + strings.Join(conditions, " AND ") + `
+              ORDER BY proposed_at DESC`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "adjustment.Repository/List", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.New("failed to list adjustments: " + err.Error())
+	}
+	defer rows.Close()
+
+	adjustments := make([]*Adjustment, 0)
+
+	for rows.Next() {
+		adjustment, err := scanAdjustment(rows)
+		if err != nil {
+			return nil, errors.New("failed to scan adjustment: " + err.Error())
+		}
+
+		adjustments = append(adjustments, adjustment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list adjustments: " + err.Error())
+	}
+
+	return adjustments, nil
+}
+
+func (r *repository) Review(
+	ctx context.Context,
+	id string,
+	status Status,
+	reviewedBy string,
+	comment string,
+) (_ *Adjustment, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE adjustments
+              SET status = @status, reviewed_by = @reviewed_by, reviewed_at = @reviewed_at,
+                  review_comment = @review_comment
+              OUTPUT ` + outputColumns() + `
+              WHERE id = @id AND tenant_id = @tenant_id AND status = @pending`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "adjustment.Repository/Review", query)
+	defer func() { tracing.End(span, err) }()
+
+	adjustment, err := scanAdjustment(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("status", string(status)),
+		sql.Named("reviewed_by", reviewedBy),
+		sql.Named("reviewed_at", time.Now().UTC()),
+		sql.Named("review_comment", comment),
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+		sql.Named("pending", string(StatusPending)),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotPending
+		}
+		return nil, errors.New("failed to review adjustment: " + err.Error())
+	}
+
+	return adjustment, nil
+}
+
+func (r *repository) MarkFailed(ctx context.Context, id string, reason string) (_ *Adjustment, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE adjustments
+              SET status = @status, failure_reason = @failure_reason
+              OUTPUT ` + outputColumns() + `
+              WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "adjustment.Repository/MarkFailed", query)
+	defer func() { tracing.End(span, err) }()
+
+	adjustment, err := scanAdjustment(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("status", string(StatusFailed)),
+		sql.Named("failure_reason", reason),
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAdjustmentNotFound
+		}
+		return nil, errors.New("failed to mark adjustment as failed: " + err.Error())
+	}
+
+	return adjustment, nil
+}
+
+// outputColumns returns adjustmentColumns prefixed for an OUTPUT clause.
+func outputColumns() string {
+	columns := strings.Split(adjustmentColumns, ",")
+	for i, column := range columns {
+		columns[i] = "inserted." + strings.TrimSpace(column)
+	}
+
+	return strings.Join(columns, ", ")
+}
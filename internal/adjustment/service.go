@@ -0,0 +1,351 @@
+package adjustment
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+var (
+	ErrAdjustmentNotFound = errors.New("adjustment not found")
+	ErrNotPending         = errors.New("adjustment is not pending")
+	ErrSameOperator       = errors.New("adjustment cannot be reviewed by the operator who proposed it")
+	ErrOperatorRequired   = errors.New("operator is required")
+	ErrInvalidDirection   = errors.New("invalid adjustment direction")
+	ErrReasonRequired     = errors.New("reason is required")
+	ErrReasonTooLong      = errors.New("reason too long")
+	ErrEvidenceTooLong    = errors.New("evidence reference too long")
+	ErrCommentTooLong     = errors.New("comment too long")
+)
+
+const (
+	// maxTextLength is the length of the reason and review comment columns.
+	maxTextLength = 500
+	// maxEvidenceReferenceLength is the length of the evidence reference column.
+	maxEvidenceReferenceLength = 255
+)
+
+// refusals are the wallet errors failing an approved adjustment.
+var refusals = []error{
+	wallet.ErrWalletNotFound,
+	wallet.ErrInvalidAmount,
+	wallet.ErrInsufficientFunds,
+	wallet.ErrWalletFrozen,
+}
+
+// Service implements the maker-checker workflow of balance adjustments: an operator proposes an adjustment,
+// a different operator approves or rejects it, and only approved adjustments change the wallet balance.
+type Service interface {
+	Propose(ctx context.Context, proposal Proposal) (*Adjustment, error)
+	Get(ctx context.Context, id string) (*Adjustment, error)
+	List(ctx context.Context, filter Filter) ([]*Adjustment, error)
+	Approve(ctx context.Context, id string, reviewedBy string, comment string) (*Adjustment, error)
+	Reject(ctx context.Context, id string, reviewedBy string, comment string) (*Adjustment, error)
+}
+
+// ResourceType is the audit log resource type of adjustments. Adjustments are audited as
+// `adjustment.<status>`, e.g. `adjustment.approved`.
+const ResourceType = "adjustment"
+
+type service struct {
+	repo     Repository
+	wallets  wallet.Service
+	auditLog audit.Recorder
+}
+
+type serviceOption func(*service)
+
+// WithAuditLog sets the audit log recording every step of the workflow in the same database transaction.
+func WithAuditLog(auditLog audit.Recorder) serviceOption {
+	return func(s *service) {
+		s.auditLog = auditLog
+	}
+}
+
+func NewService(repo Repository, wallets wallet.Service, options ...serviceOption) Service {
+	s := &service{
+		repo:     repo,
+		wallets:  wallets,
+		auditLog: audit.NopRecorder{},
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *service) Propose(ctx context.Context, proposal Proposal) (*Adjustment, error) {
+	if proposal.ProposedBy == "" {
+		return nil, ErrOperatorRequired
+	}
+
+	if proposal.Direction != DirectionCredit && proposal.Direction != DirectionDebit {
+		return nil, ErrInvalidDirection
+	}
+
+	if proposal.Amount <= 0 || !wallet.WholeCents(proposal.Amount) {
+		return nil, wallet.ErrInvalidAmount
+	}
+
+	if strings.TrimSpace(proposal.Reason) == "" {
+		return nil, ErrReasonRequired
+	}
+
+	if utf8.RuneCountInString(proposal.Reason) > maxTextLength {
+		return nil, ErrReasonTooLong
+	}
+
+	if utf8.RuneCountInString(proposal.EvidenceReference) > maxEvidenceReferenceLength {
+		return nil, ErrEvidenceTooLong
+	}
+
+	target, err := s.wallets.GetWallet(ctx, proposal.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment := &Adjustment{
+		WalletID:          target.ID,
+		Direction:         proposal.Direction,
+		Amount:            proposal.Amount,
+		Currency:          target.Currency,
+		Reason:            proposal.Reason,
+		EvidenceReference: proposal.EvidenceReference,
+		Status:            StatusPending,
+		ProposedBy:        proposal.ProposedBy,
+		ProposedAt:        time.Now().UTC(),
+	}
+
+	err = s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, adjustment); err != nil {
+			return err
+		}
+
+		return s.auditLog.Record(ctx, auditAction(adjustment), ResourceType, adjustment.ID, nil, adjustment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Adjustment proposed",
+		zap.String("adjustment_id", adjustment.ID),
+		zap.String("wallet_id", adjustment.WalletID),
+		zap.String("direction", string(adjustment.Direction)),
+		zap.Float64("amount", adjustment.Amount),
+		zap.String("proposed_by", adjustment.ProposedBy),
+	)
+
+	return adjustment, nil
+}
+
+func (s *service) Get(ctx context.Context, id string) (*Adjustment, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *service) List(ctx context.Context, filter Filter) ([]*Adjustment, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Approve reviews the adjustment and books it in one database transaction, so an adjustment is never approved
+// without being applied. When the wallet refuses the change the adjustment is marked failed instead and the
+// wallet error is returned.
+func (s *service) Approve(ctx context.Context, id string, reviewedBy string, comment string) (*Adjustment, error) {
+	before, err := s.checkReviewable(ctx, id, reviewedBy, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	var adjustment *Adjustment
+
+	err = s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		adjustment, err = s.repo.Review(ctx, id, StatusApproved, reviewedBy, comment)
+		if err != nil {
+			return err
+		}
+
+		if err := s.apply(ctx, adjustment); err != nil {
+			return err
+		}
+
+		return s.auditLog.Record(ctx, auditAction(adjustment), ResourceType, adjustment.ID, before, adjustment)
+	})
+	if err != nil {
+		if !isRefusal(err) {
+			return nil, err
+		}
+
+		if markErr := s.markFailed(ctx, before, reviewedBy, comment, err); markErr != nil {
+			return nil, errors.New("failed to record failed adjustment: " + markErr.Error())
+		}
+
+		logging.FromContext(ctx, nil).Warn(
+			"Approved adjustment could not be applied",
+			zap.String("adjustment_id", before.ID),
+			zap.String("wallet_id", before.WalletID),
+			logger.ErrorField(err),
+		)
+
+		return nil, err
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Adjustment approved",
+		zap.String("adjustment_id", adjustment.ID),
+		zap.String("wallet_id", adjustment.WalletID),
+		zap.String("direction", string(adjustment.Direction)),
+		zap.Float64("amount", adjustment.Amount),
+		zap.String("proposed_by", adjustment.ProposedBy),
+		zap.String("reviewed_by", adjustment.ReviewedBy),
+	)
+
+	return adjustment, nil
+}
+
+func (s *service) Reject(ctx context.Context, id string, reviewedBy string, comment string) (*Adjustment, error) {
+	adjustment, err := s.review(ctx, id, StatusRejected, reviewedBy, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Adjustment rejected",
+		zap.String("adjustment_id", adjustment.ID),
+		zap.String("wallet_id", adjustment.WalletID),
+		zap.String("proposed_by", adjustment.ProposedBy),
+		zap.String("reviewed_by", adjustment.ReviewedBy),
+	)
+
+	return adjustment, nil
+}
+
+// checkReviewable enforces that only pending adjustments are reviewed, and never by the operator who proposed them.
+func (s *service) checkReviewable(
+	ctx context.Context,
+	id string,
+	reviewedBy string,
+	comment string,
+) (*Adjustment, error) {
+	if reviewedBy == "" {
+		return nil, ErrOperatorRequired
+	}
+
+	if utf8.RuneCountInString(comment) > maxTextLength {
+		return nil, ErrCommentTooLong
+	}
+
+	adjustment, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if adjustment.Status != StatusPending {
+		return nil, ErrNotPending
+	}
+
+	if strings.EqualFold(adjustment.ProposedBy, reviewedBy) {
+		return nil, ErrSameOperator
+	}
+
+	return adjustment, nil
+}
+
+func (s *service) review(
+	ctx context.Context,
+	id string,
+	status Status,
+	reviewedBy string,
+	comment string,
+) (*Adjustment, error) {
+	before, err := s.checkReviewable(ctx, id, reviewedBy, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	var adjustment *Adjustment
+
+	err = s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		adjustment, err = s.repo.Review(ctx, id, status, reviewedBy, comment)
+		if err != nil {
+			return err
+		}
+
+		return s.auditLog.Record(ctx, auditAction(adjustment), ResourceType, adjustment.ID, before, adjustment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+// markFailed records the review of an approved adjustment the wallet refused, once the approval was rolled back.
+func (s *service) markFailed(
+	ctx context.Context,
+	before *Adjustment,
+	reviewedBy string,
+	comment string,
+	cause error,
+) error {
+	return s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.Review(ctx, before.ID, StatusFailed, reviewedBy, comment); err != nil {
+			return err
+		}
+
+		adjustment, err := s.repo.MarkFailed(ctx, before.ID, cause.Error())
+		if err != nil {
+			return err
+		}
+
+		return s.auditLog.Record(ctx, auditAction(adjustment), ResourceType, adjustment.ID, before, adjustment)
+	})
+}
+
+func auditAction(adjustment *Adjustment) string {
+	if adjustment.Status == StatusPending {
+		return "adjustment.proposed"
+	}
+
+	return "adjustment." + string(adjustment.Status)
+}
+
+// apply books the adjustment through the wallet service, bypassing the limits of the tenant that apply to the
+// deposits and withdrawals of customers.
+func (s *service) apply(ctx context.Context, adjustment *Adjustment) error {
+	direction := wallet.DirectionCredit
+	if adjustment.Direction == DirectionDebit {
+		direction = wallet.DirectionDebit
+	}
+
+	transaction, err := s.wallets.Adjust(ctx, adjustment.WalletID, direction, adjustment.Amount, adjustment.Reason)
+	if err != nil {
+		return err
+	}
+
+	adjustment.WalletVersion = transaction.WalletVersion
+
+	return nil
+}
+
+// isRefusal tells whether the wallet refused the change of an adjustment, which then fails. Other errors roll
+// the approval back, the adjustment stays pending.
+func isRefusal(err error) bool {
+	for _, refusal := range refusals {
+		if errors.Is(err, refusal) {
+			return true
+		}
+	}
+
+	return false
+}
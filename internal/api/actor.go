@@ -0,0 +1,163 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/api/httpv1"
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+)
+
+// operatorSignatureMaxAge is how far the time of an operator signature may be from the clock of the server.
+const operatorSignatureMaxAge = 5 * time.Minute
+
+// IdentifyClient stores the actor of a public API request in its context for the audit log: the user named
+// by the gateway in userHeader, otherwise the API key the client authenticated with. User IDs longer than the
+// audit log stores are refused.
+func IdentifyClient(apiKeyHeader string, userHeader string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			actor := audit.Actor{Type: audit.ActorAnonymous, IP: remoteIP(r)}
+
+			userID := r.Header.Get(userHeader)
+			if utf8.RuneCountInString(userID) > audit.MaxActorIDLength {
+				httpv1.WriteProblem(w, r, httpv1.ProblemValidationFailed, actorTooLong(userHeader))
+				return
+			}
+
+			if userID != "" {
+				actor.Type = audit.ActorUser
+				actor.ID = userID
+			} else if apiKey := r.Header.Get(apiKeyHeader); apiKey != "" {
+				actor.Type = audit.ActorAPIKey
+				actor.ID = audit.APIKeyFingerprint(apiKey)
+			}
+
+			next.ServeHTTP(w, r.WithContext(audit.NewContext(r.Context(), actor)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// IdentifyOperator stores the operator named in operatorHeader as the actor of an admin request, refusing operator
+// IDs longer than the audit log stores. With a signing key, the operator must be vouched for by the authenticating
+// proxy with a signature in signatureHeader, see SignOperator, otherwise the request is refused.
+func IdentifyOperator(
+	operatorHeader string,
+	signatureHeader string,
+	signingKey string,
+) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			operatorID := r.Header.Get(operatorHeader)
+			if utf8.RuneCountInString(operatorID) > audit.MaxActorIDLength {
+				httpv1.WriteProblem(w, r, httpv1.ProblemValidationFailed, actorTooLong(operatorHeader))
+				return
+			}
+
+			if signingKey != "" && !verifyOperator(signingKey, operatorID, r.Header.Get(signatureHeader), time.Now()) {
+				httpv1.WriteProblem(w, r, httpv1.ProblemUnauthorized, "Operator is not authenticated")
+				return
+			}
+
+			actor := audit.Actor{
+				Type: audit.ActorOperator,
+				ID:   operatorID,
+				IP:   remoteIP(r),
+			}
+
+			next.ServeHTTP(w, r.WithContext(audit.NewContext(r.Context(), actor)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RequireAuthenticatedOperator refuses the requests when operators are not authenticated, i.e. without a signing
+// key, for the endpoints whose rules rely on who the operator is, like the four-eyes review of adjustments.
+func RequireAuthenticatedOperator(signingKey string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if signingKey == "" {
+				httpv1.WriteProblem(w, r, httpv1.ProblemForbidden, "Operator authentication is not configured")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// SignOperator returns the signature vouching for operatorID at the given time, `t=<unix time>,v1=<signature>`,
+// the signature being the hex HMAC-SHA256 of `<unix time>.<operator ID>` with signingKey.
+func SignOperator(signingKey string, operatorID string, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(operatorMAC(signingKey, timestamp, operatorID))
+}
+
+// verifyOperator tells whether signature vouches for operatorID, and was made recently enough not to be replayed.
+func verifyOperator(signingKey string, operatorID string, signature string, now time.Time) bool {
+	if operatorID == "" {
+		return false
+	}
+
+	var timestamp, mac string
+
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			mac = value
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	if age := now.Sub(time.Unix(seconds, 0)); age > operatorSignatureMaxAge || age < -operatorSignatureMaxAge {
+		return false
+	}
+
+	decoded, err := hex.DecodeString(mac)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(decoded, operatorMAC(signingKey, timestamp, operatorID))
+}
+
+func operatorMAC(signingKey string, timestamp string, operatorID string) []byte {
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(timestamp + "." + operatorID))
+
+	return mac.Sum(nil)
+}
+
+func actorTooLong(header string) string {
+	return "Header " + header + " must be at most " + strconv.Itoa(audit.MaxActorIDLength) + " characters"
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
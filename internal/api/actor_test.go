@@ -0,0 +1,99 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+)
+
+func TestIdentifyOperator_Signature(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		operator  string
+		signature string
+		status    int
+	}{
+		{"valid", "alice", SignOperator("key", "alice", now), http.StatusOK},
+		{"missing", "alice", "", http.StatusUnauthorized},
+		{"other operator", "bob", SignOperator("key", "alice", now), http.StatusUnauthorized},
+		{"other key", "alice", SignOperator("other", "alice", now), http.StatusUnauthorized},
+		{"expired", "alice", SignOperator("key", "alice", now.Add(-10*time.Minute)), http.StatusUnauthorized},
+		{"no operator", "", SignOperator("key", "", now), http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var actor audit.Actor
+
+			handler := IdentifyOperator("X-Operator-ID", "X-Operator-Signature", "key")(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					actor = audit.ActorFromContext(r.Context())
+				}),
+			)
+
+			request := httptest.NewRequest(http.MethodPost, "/adjustments", nil)
+			request.Header.Set("X-Operator-ID", tc.operator)
+			request.Header.Set("X-Operator-Signature", tc.signature)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tc.status {
+				t.Fatalf("status = %d, want %d", recorder.Code, tc.status)
+			}
+
+			if tc.status == http.StatusOK && actor.ID != tc.operator {
+				t.Errorf("actor = %q, want %q", actor.ID, tc.operator)
+			}
+		})
+	}
+}
+
+func TestRequireAuthenticatedOperator_WithoutSigningKey(t *testing.T) {
+	handler := RequireAuthenticatedOperator("")(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("handler called without operator authentication")
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/adjustments", nil))
+
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusForbidden)
+	}
+}
+
+func TestIdentifyActor_RefusesLongIDs(t *testing.T) {
+	tooLong := strings.Repeat("é", audit.MaxActorIDLength+1)
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("handler called with an actor ID longer than the audit log stores")
+	})
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		header  string
+	}{
+		{"user", IdentifyClient("X-API-Key", "X-User-ID")(next), "X-User-ID"},
+		{"operator", IdentifyOperator("X-Operator-ID", "X-Operator-Signature", "")(next), "X-Operator-ID"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/", nil)
+			request.Header.Set(tc.header, tooLong)
+
+			recorder := httptest.NewRecorder()
+			tc.handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
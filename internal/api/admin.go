@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/api/httpv1"
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+)
+
+// NewConfigHandler serves the runtime configuration with the secrets redacted.
+func NewConfigHandler(cfg *config.ServerConfig) http.HandlerFunc {
+	redacted := config.Redacted(cfg)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		httpv1.WriteJSON(w, http.StatusOK, redacted)
+	}
+}
@@ -0,0 +1,163 @@
+package httpv1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/adjustment"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+)
+
+type ProposeAdjustmentRequest struct {
+	WalletID          string  `json:"wallet_id"`
+	Direction         string  `json:"direction"`
+	Amount            float64 `json:"amount"`
+	Reason            string  `json:"reason"`
+	EvidenceReference string  `json:"evidence_reference"`
+}
+
+type ReviewAdjustmentRequest struct {
+	Comment string `json:"comment"`
+}
+
+// NewProposeAdjustmentHandler creates a pending adjustment on behalf of the operator named in operatorHeader.
+func NewProposeAdjustmentHandler(
+	svc adjustment.Service,
+	log logger.StructuredLogger,
+	operatorHeader string,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		operator := r.Header.Get(operatorHeader)
+		if operator == "" {
+			WriteProblem(w, r, ProblemUnauthorized, "Header "+operatorHeader+" is required")
+			return
+		}
+
+		var req ProposeAdjustmentRequest
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&req); err != nil {
+			logging.FromContext(r.Context(), log).Error("Failed to decode adjustment request body", logger.ErrorField(err))
+			WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
+			return
+		}
+
+		switch {
+		case req.WalletID == "":
+			WriteProblem(w, r, ProblemValidationFailed, "Wallet ID is required")
+			return
+		case req.EvidenceReference == "":
+			WriteProblem(w, r, ProblemValidationFailed, "Evidence reference is required")
+			return
+		}
+
+		proposed, err := svc.Propose(r.Context(), adjustment.Proposal{
+			WalletID:          req.WalletID,
+			Direction:         adjustment.Direction(strings.ToLower(req.Direction)),
+			Amount:            req.Amount,
+			Reason:            req.Reason,
+			EvidenceReference: req.EvidenceReference,
+			ProposedBy:        operator,
+		})
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		w.Header().Set("Location", "/adjustments/"+proposed.ID)
+		WriteJSON(w, http.StatusCreated, proposed)
+	}
+}
+
+func NewGetAdjustmentHandler(svc adjustment.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		found, err := svc.Get(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, found)
+	}
+}
+
+// NewListAdjustmentsHandler lists the adjustments, optionally filtered by the `wallet_id` and `status` query parameters.
+func NewListAdjustmentsHandler(svc adjustment.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		adjustments, err := svc.List(r.Context(), adjustment.Filter{
+			WalletID: query.Get("wallet_id"),
+			Status:   adjustment.Status(query.Get("status")),
+		})
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, adjustments)
+	}
+}
+
+// NewApproveAdjustmentHandler approves and applies a pending adjustment on behalf of the operator named
+// in operatorHeader, who must differ from the proposer. The response carries the new version of the wallet
+// as ETag.
+func NewApproveAdjustmentHandler(
+	svc adjustment.Service,
+	log logger.StructuredLogger,
+	operatorHeader string,
+) http.HandlerFunc {
+	return newReviewAdjustmentHandler(svc.Approve, log, operatorHeader)
+}
+
+// NewRejectAdjustmentHandler rejects a pending adjustment on behalf of the operator named in operatorHeader,
+// who must differ from the proposer.
+func NewRejectAdjustmentHandler(
+	svc adjustment.Service,
+	log logger.StructuredLogger,
+	operatorHeader string,
+) http.HandlerFunc {
+	return newReviewAdjustmentHandler(svc.Reject, log, operatorHeader)
+}
+
+type reviewFunc func(ctx context.Context, id string, reviewedBy string, comment string) (*adjustment.Adjustment, error)
+
+func newReviewAdjustmentHandler(review reviewFunc, log logger.StructuredLogger, operatorHeader string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		operator := r.Header.Get(operatorHeader)
+		if operator == "" {
+			WriteProblem(w, r, ProblemUnauthorized, "Header "+operatorHeader+" is required")
+			return
+		}
+
+		var req ReviewAdjustmentRequest
+		if r.ContentLength != 0 {
+			decoder := json.NewDecoder(r.Body)
+			decoder.DisallowUnknownFields()
+
+			if err := decoder.Decode(&req); err != nil {
+				logging.FromContext(r.Context(), log).Error("Failed to decode review request body", logger.ErrorField(err))
+				WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
+				return
+			}
+		}
+
+		reviewed, err := review(r.Context(), chi.URLParam(r, "id"), operator, req.Comment)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		if reviewed.WalletVersion > 0 {
+			w.Header().Set("ETag", WalletETag(reviewed.WalletVersion))
+		}
+
+		WriteJSON(w, http.StatusOK, reviewed)
+	}
+}
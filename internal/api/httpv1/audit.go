@@ -0,0 +1,106 @@
+package httpv1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+// NewListAuditHandler lists the audit entries of the tenant in chain order. The entries can be filtered by the
+// `resource_type`, `resource_id`, `action`, `actor_id`, `from` and `to` query parameters, and are paginated with
+// `limit` and `after`, the sequence of the last entry seen. Full pages link the next page in the `Link` header.
+func NewListAuditHandler(auditLog audit.Log, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		filter := audit.Filter{
+			ResourceType: query.Get("resource_type"),
+			ResourceID:   query.Get("resource_id"),
+			Action:       query.Get("action"),
+			ActorID:      query.Get("actor_id"),
+		}
+
+		var err error
+
+		if filter.From, err = parseTimeParam(query.Get("from"), false); err != nil {
+			WriteProblem(w, r, ProblemValidationFailed, "from must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+			return
+		}
+
+		if filter.To, err = parseTimeParam(query.Get("to"), true); err != nil {
+			WriteProblem(w, r, ProblemValidationFailed, "to must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+			return
+		}
+
+		if filter.AfterSequence, err = parseIntParam(query.Get("after")); err != nil || filter.AfterSequence < 0 {
+			WriteProblem(w, r, ProblemValidationFailed, "after must be a non-negative sequence number")
+			return
+		}
+
+		limit, err := parseIntParam(query.Get("limit"))
+		if err != nil || limit < 0 || limit > audit.MaxListLimit {
+			WriteProblem(w, r, ProblemValidationFailed, "limit must be between 1 and "+strconv.Itoa(audit.MaxListLimit))
+			return
+		}
+
+		filter.Limit = int(limit)
+		if filter.Limit == 0 {
+			filter.Limit = audit.DefaultListLimit
+		}
+
+		entries, err := auditLog.List(r.Context(), filter)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		if len(entries) == filter.Limit {
+			query.Set("after", strconv.FormatInt(entries[len(entries)-1].Sequence, 10))
+			w.Header().Set("Link", "<"+r.URL.Path+"?"+query.Encode()+`>; rel="next"`)
+		}
+
+		WriteJSON(w, http.StatusOK, entries)
+	}
+}
+
+// NewVerifyAuditHandler verifies the hash chain of the tenant's audit log.
+func NewVerifyAuditHandler(auditLog audit.Log, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t, err := tenant.FromContext(r.Context())
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		verification, err := auditLog.Verify(r.Context(), t.ID)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, verification)
+	}
+}
+
+// parseTimeParam parses an optional bound of a period, the zero time when value is empty.
+func parseTimeParam(value string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return wallet.ParseBound(value, end)
+}
+
+func parseIntParam(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
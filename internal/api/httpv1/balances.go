@@ -0,0 +1,58 @@
+package httpv1
+
+import (
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/snapshot"
+)
+
+// NewEndOfDayBalancesHandler reports the balances of the tenant's wallets at the end of the `date` query parameter,
+// yesterday by default. With `format=csv` the balances are returned as a CSV report.
+func NewEndOfDayBalancesHandler(snapshotter *snapshot.Snapshotter, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		format := query.Get("format")
+		if format != "" && format != snapshot.FormatJSON && format != snapshot.FormatCSV {
+			WriteProblem(w, r, ProblemValidationFailed, "Format must be json or csv")
+			return
+		}
+
+		day := snapshot.Day(time.Now()).AddDate(0, 0, -1)
+
+		if value := query.Get("date"); value != "" {
+			var err error
+
+			if day, err = time.Parse(time.DateOnly, value); err != nil {
+				WriteProblem(w, r, ProblemValidationFailed, "date must be a date (YYYY-MM-DD)")
+				return
+			}
+		}
+
+		report, err := snapshotter.EndOfDay(r.Context(), day)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		if format != snapshot.FormatCSV {
+			WriteJSON(w, http.StatusOK, report)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": "balances-" + report.Date + ".csv",
+		}))
+		w.WriteHeader(http.StatusOK)
+
+		if err := snapshot.WriteReport(w, report, snapshot.FormatCSV); err != nil {
+			logging.FromContext(r.Context(), log).Error("Failed to write balances report", logger.ErrorField(err))
+		}
+	}
+}
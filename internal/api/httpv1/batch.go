@@ -0,0 +1,143 @@
+package httpv1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/batch"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+)
+
+type SubmitBatchRequest struct {
+	Atomic     bool              `json:"atomic"`
+	Operations []batch.Operation `json:"operations"`
+}
+
+// NewSubmitBatchHandler queues the operations of the request body for asynchronous processing and answers
+// 202 Accepted with the pending batch. The body is either a JSON SubmitBatchRequest or, with a `text/csv`
+// Content-Type, a CSV file of operations, atomic when the `atomic` query parameter is true.
+func NewSubmitBatchHandler(svc batch.Service, log logger.StructuredLogger, maxFileSize int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxFileSize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				WriteProblem(w, r, ProblemFileTooLarge, "The batch exceeds the maximum size")
+				return
+			}
+
+			logging.FromContext(r.Context(), log).Error("Failed to read batch", logger.ErrorField(err))
+			WriteProblem(w, r, ProblemInvalidRequest, "Failed to read the batch")
+			return
+		}
+
+		var req SubmitBatchRequest
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "text/csv" {
+			if value := r.URL.Query().Get("atomic"); value != "" {
+				if req.Atomic, err = strconv.ParseBool(value); err != nil {
+					WriteProblem(w, r, ProblemValidationFailed, "atomic must be true or false")
+					return
+				}
+			}
+
+			if req.Operations, err = batch.ParseCSV(content); err != nil {
+				writeBatchError(w, r, log, err)
+				return
+			}
+		} else {
+			decoder := json.NewDecoder(bytes.NewReader(content))
+			decoder.DisallowUnknownFields()
+
+			if err := decoder.Decode(&req); err != nil {
+				WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
+				return
+			}
+		}
+
+		submitted, err := svc.Submit(r.Context(), req.Operations, req.Atomic)
+		if err != nil {
+			writeBatchError(w, r, log, err)
+			return
+		}
+
+		w.Header().Set("Location", "/v1/batches/"+submitted.ID)
+		WriteJSON(w, http.StatusAccepted, submitted)
+	}
+}
+
+// NewGetBatchHandler returns the batch with its progress, to be polled until it is completed or failed.
+func NewGetBatchHandler(svc batch.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		found, err := svc.Get(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, found)
+	}
+}
+
+// NewBatchResultHandler returns the outcome of every operation of a finished batch as a CSV file, or as JSON with
+// `format=json`, optionally filtered by the `status` query parameter.
+func NewBatchResultHandler(svc batch.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		format := query.Get("format")
+		if format != "" && format != "json" && format != "csv" {
+			WriteProblem(w, r, ProblemValidationFailed, "Format must be json or csv")
+			return
+		}
+
+		batchID := chi.URLParam(r, "id")
+
+		items, err := svc.ListItems(r.Context(), batchID, batch.ItemStatus(query.Get("status")))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		if format == "json" {
+			WriteJSON(w, http.StatusOK, items)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": "batch-" + batchID + ".csv",
+		}))
+		w.WriteHeader(http.StatusOK)
+
+		if err := batch.WriteItemsCSV(w, items); err != nil {
+			logging.FromContext(r.Context(), log).Error("Failed to write batch result", logger.ErrorField(err))
+		}
+	}
+}
+
+// writeBatchError reports the invalid file row or operation of a batch in the problem detail.
+func writeBatchError(w http.ResponseWriter, r *http.Request, log logger.StructuredLogger, err error) {
+	var parseErr *batch.ParseError
+	if errors.As(err, &parseErr) {
+		WriteProblem(w, r, ProblemInvalidFile, parseErr.Error())
+		return
+	}
+
+	var operationErr *batch.OperationError
+	if errors.As(err, &operationErr) {
+		WriteProblem(w, r, ProblemValidationFailed, operationErr.Error())
+		return
+	}
+
+	WriteError(w, r, log, err)
+}
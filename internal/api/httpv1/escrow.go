@@ -0,0 +1,202 @@
+package httpv1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/escrow"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+)
+
+// CreateEscrowRequest holds Amount of the payer wallet in escrow for the payee wallet.
+type CreateEscrowRequest struct {
+	PayerWalletID string     `json:"payer_wallet_id"`
+	PayeeWalletID string     `json:"payee_wallet_id"`
+	Amount        float64    `json:"amount"`
+	Reference     string     `json:"reference"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	OnExpiry      string     `json:"on_expiry"`
+}
+
+// SettleEscrowRequest releases or refunds an escrow, the reason is recorded in its history.
+type SettleEscrowRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SplitEscrowRequest pays PayeeAmount of an escrow to the payee and the rest back to the payer.
+type SplitEscrowRequest struct {
+	PayeeAmount float64 `json:"payee_amount"`
+	Reason      string  `json:"reason"`
+}
+
+func NewCreateEscrowHandler(svc escrow.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req CreateEscrowRequest
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&req); err != nil {
+			logging.FromContext(r.Context(), log).Error("Failed to decode escrow request body", logger.ErrorField(err))
+			WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
+			return
+		}
+
+		switch {
+		case req.PayerWalletID == "":
+			WriteProblem(w, r, ProblemValidationFailed, "payer_wallet_id is required")
+			return
+		case req.PayeeWalletID == "":
+			WriteProblem(w, r, ProblemValidationFailed, "payee_wallet_id is required")
+			return
+		}
+
+		created, err := svc.Create(r.Context(), escrow.Instruction{
+			PayerWalletID: req.PayerWalletID,
+			PayeeWalletID: req.PayeeWalletID,
+			Amount:        req.Amount,
+			Reference:     req.Reference,
+			ExpiresAt:     req.ExpiresAt,
+			OnExpiry:      escrow.ExpiryAction(strings.ToLower(req.OnExpiry)),
+		})
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		w.Header().Set("Location", "/v1/escrows/"+created.ID)
+		WriteJSON(w, http.StatusCreated, created)
+	}
+}
+
+func NewGetEscrowHandler(svc escrow.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		found, err := svc.Get(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, found)
+	}
+}
+
+// NewListEscrowsHandler lists the escrows, optionally filtered by the `wallet_id` and `status` query parameters.
+func NewListEscrowsHandler(svc escrow.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		escrows, err := svc.List(r.Context(), escrow.Filter{
+			WalletID: query.Get("wallet_id"),
+			Status:   escrow.Status(query.Get("status")),
+		})
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, escrows)
+	}
+}
+
+// NewReleaseEscrowHandler pays a held escrow to the payee and returns it.
+func NewReleaseEscrowHandler(svc escrow.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeSettleEscrowRequest(w, r, log)
+		if !ok {
+			return
+		}
+
+		released, err := svc.Release(r.Context(), chi.URLParam(r, "id"), req.Reason)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, released)
+	}
+}
+
+// NewRefundEscrowHandler pays a held escrow back to the payer and returns it.
+func NewRefundEscrowHandler(svc escrow.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeSettleEscrowRequest(w, r, log)
+		if !ok {
+			return
+		}
+
+		refunded, err := svc.Refund(r.Context(), chi.URLParam(r, "id"), req.Reason)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, refunded)
+	}
+}
+
+// NewSplitEscrowHandler splits a held escrow between the payee and the payer and returns it.
+func NewSplitEscrowHandler(svc escrow.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req SplitEscrowRequest
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&req); err != nil {
+			logging.FromContext(r.Context(), log).Error("Failed to decode escrow request body", logger.ErrorField(err))
+			WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
+			return
+		}
+
+		split, err := svc.Split(r.Context(), chi.URLParam(r, "id"), req.PayeeAmount, req.Reason)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, split)
+	}
+}
+
+// NewListEscrowEventsHandler lists the state history of an escrow, oldest first.
+func NewListEscrowEventsHandler(svc escrow.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		events, err := svc.ListEvents(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, events)
+	}
+}
+
+// decodeSettleEscrowRequest decodes the optional body of a release or refund. It writes the problem and returns
+// false when the body is invalid.
+func decodeSettleEscrowRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+	log logger.StructuredLogger,
+) (SettleEscrowRequest, bool) {
+	var req SettleEscrowRequest
+
+	if r.ContentLength == 0 {
+		return req, true
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&req); err != nil {
+		logging.FromContext(r.Context(), log).Error("Failed to decode escrow request body", logger.ErrorField(err))
+		WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
+
+		return req, false
+	}
+
+	return req, true
+}
@@ -0,0 +1,52 @@
+package httpv1
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WalletETag returns the strong entity tag of the wallet at version.
+func WalletETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ParseWalletETag returns the wallet version of a strong entity tag returned by WalletETag. Weak tags are
+// rejected, as If-Match compares entity tags strongly.
+func ParseWalletETag(tag string) (int64, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// noneMatch tells whether the If-None-Match header of r lets the representation with etag be sent, comparing the
+// entity tags weakly. When it does not, the client already has the representation.
+func noneMatch(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeWallet writes the wallet with its ETag.
+func writeWallet(w http.ResponseWriter, status int, response WalletResponse, version int64) {
+	w.Header().Set("ETag", WalletETag(version))
+	WriteJSON(w, status, response)
+}
@@ -0,0 +1,24 @@
+package httpv1
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/interest"
+)
+
+// NewAccruedInterestHandler returns the interest the wallet accrued and was not paid yet. Wallets without a
+// product accrue nothing.
+func NewAccruedInterestHandler(accruer *interest.Accruer, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		summary, err := accruer.Accrued(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, summary)
+	}
+}
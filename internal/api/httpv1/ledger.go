@@ -0,0 +1,28 @@
+package httpv1
+
+import (
+	"net/http"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/ledger"
+)
+
+// NewLedgerInvariantsHandler checks the ledger invariants across all tenants. It answers `200 OK` when the
+// ledger is balanced and `409 Conflict` with the imbalances otherwise.
+func NewLedgerInvariantsHandler(walletLedger ledger.Ledger, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report, err := walletLedger.CheckInvariants(r.Context())
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		status := http.StatusOK
+		if !report.Balanced {
+			status = http.StatusConflict
+		}
+
+		WriteJSON(w, status, report)
+	}
+}
@@ -0,0 +1,198 @@
+package httpv1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/adjustment"
+	"tribe-payments-wallet-golang-interview-assignment/internal/batch"
+	"tribe-payments-wallet-golang-interview-assignment/internal/escrow"
+	internalHTTP "tribe-payments-wallet-golang-interview-assignment/internal/http"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/schedule"
+	"tribe-payments-wallet-golang-interview-assignment/internal/settlement"
+	"tribe-payments-wallet-golang-interview-assignment/internal/snapshot"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+const (
+	ContentTypeProblemJSON = "application/problem+json"
+
+	problemTypePrefix = "urn:problem-type:wallet:"
+)
+
+// ProblemType describes a class of errors with a stable, machine-readable code.
+// Clients must rely on Code, Title and Detail are meant for humans.
+type ProblemType struct {
+	Status int
+	Code   string
+	Title  string
+}
+
+var (
+	ProblemInvalidRequest     = ProblemType{http.StatusBadRequest, "invalid_request", "Invalid request"}
+	ProblemValidationFailed   = ProblemType{http.StatusBadRequest, "validation_failed", "Validation failed"}
+	ProblemInvalidAmount      = ProblemType{http.StatusBadRequest, "invalid_amount", "Invalid amount"}
+	ProblemInsufficientFunds  = ProblemType{http.StatusBadRequest, "insufficient_funds", "Insufficient funds"}
+	ProblemCurrencyNotAllowed = ProblemType{http.StatusBadRequest, "currency_not_allowed", "Currency not allowed"}
+	ProblemLimitExceeded      = ProblemType{http.StatusBadRequest, "limit_exceeded", "Limit exceeded"}
+	ProblemUnauthorized       = ProblemType{http.StatusUnauthorized, "unauthorized", "Unauthorized"}
+	ProblemForbidden          = ProblemType{http.StatusForbidden, "forbidden", "Forbidden"}
+	ProblemNotFound           = ProblemType{http.StatusNotFound, "not_found", "Not found"}
+	ProblemWalletNotFound     = ProblemType{http.StatusNotFound, "wallet_not_found", "Wallet not found"}
+	ProblemAdjustmentNotFound = ProblemType{http.StatusNotFound, "adjustment_not_found", "Adjustment not found"}
+	ProblemAdjustmentReviewed = ProblemType{http.StatusConflict, "adjustment_reviewed", "Adjustment already reviewed"}
+	ProblemSameOperator       = ProblemType{http.StatusForbidden, "same_operator", "Same operator"}
+	ProblemMethodNotAllowed   = ProblemType{http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed"}
+	ProblemRateLimited        = ProblemType{http.StatusTooManyRequests, "rate_limited", "Too many requests"}
+	ProblemInternal           = ProblemType{http.StatusInternalServerError, "internal_error", "Internal server error"}
+
+	ProblemTransactionNotFound     = ProblemType{http.StatusNotFound, "transaction_not_found", "Transaction not found"}
+	ProblemNotReversible           = ProblemType{http.StatusConflict, "not_reversible", "Transaction not reversible"}
+	ProblemAlreadyReversed         = ProblemType{http.StatusConflict, "already_reversed", "Transaction already reversed"}
+	ProblemReversalExceedsOriginal = ProblemType{http.StatusBadRequest, "reversal_exceeds_original", "Reversal exceeds original amount"} //nolint:lll
+
+	ProblemWalletFrozen = ProblemType{http.StatusConflict, "wallet_frozen", "Wallet frozen"}
+
+	ProblemInvalidFile            = ProblemType{http.StatusBadRequest, "invalid_file", "Invalid file"}
+	ProblemFileTooLarge           = ProblemType{http.StatusRequestEntityTooLarge, "file_too_large", "File too large"}
+	ProblemSettlementNotFound     = ProblemType{http.StatusNotFound, "settlement_not_found", "Settlement import not found"}
+	ProblemSettlementItemNotFound = ProblemType{http.StatusNotFound, "settlement_item_not_found", "Settlement item not found"}   //nolint:lll
+	ProblemDuplicateSettlement    = ProblemType{http.StatusConflict, "duplicate_settlement", "Settlement file already imported"} //nolint:lll
+	ProblemSettlementItemClosed   = ProblemType{http.StatusConflict, "settlement_item_closed", "Settlement item closed"}
+	ProblemDepositAlreadyMatched  = ProblemType{http.StatusConflict, "deposit_already_matched", "Deposit already matched"}
+
+	ProblemPreconditionFailed = ProblemType{http.StatusPreconditionFailed, "precondition_failed", "Precondition failed"}
+
+	ProblemBatchNotFound    = ProblemType{http.StatusNotFound, "batch_not_found", "Batch not found"}
+	ProblemBatchNotFinished = ProblemType{http.StatusConflict, "batch_not_finished", "Batch not finished"}
+
+	ProblemScheduleNotFound  = ProblemType{http.StatusNotFound, "schedule_not_found", "Schedule not found"}
+	ProblemScheduleNotActive = ProblemType{http.StatusConflict, "schedule_not_active", "Schedule not active"}
+
+	ProblemUnknownProduct = ProblemType{http.StatusBadRequest, "unknown_product", "Unknown product"}
+
+	ProblemEscrowNotFound = ProblemType{http.StatusNotFound, "escrow_not_found", "Escrow not found"}
+	ProblemEscrowNotHeld  = ProblemType{http.StatusConflict, "escrow_not_held", "Escrow not held"}
+	ProblemEscrowExpired  = ProblemType{http.StatusConflict, "escrow_expired", "Escrow expired"}
+)
+
+// errorProblems maps the domain errors to the problem reported to clients.
+// Errors missing from the table are reported as ProblemInternal.
+var errorProblems = []struct {
+	err     error
+	problem ProblemType
+	detail  string
+}{
+	{wallet.ErrWalletNotFound, ProblemWalletNotFound, "The wallet does not exist."},
+	{wallet.ErrInvalidAmount, ProblemInvalidAmount, "The amount must be greater than zero, with at most two decimals."},
+	{wallet.ErrInsufficientFunds, ProblemInsufficientFunds, "The wallet balance is lower than the requested amount."},
+	{wallet.ErrCurrencyNotAllowed, ProblemCurrencyNotAllowed, "The currency is not allowed for the tenant."},
+	{wallet.ErrLimitExceeded, ProblemLimitExceeded, "The amount exceeds a limit of the tenant."},
+	{wallet.ErrTransactionNotFound, ProblemTransactionNotFound, "The transaction does not exist."},
+	{wallet.ErrNotReversible, ProblemNotReversible, "Only deposits and withdrawals can be reversed."},
+	{wallet.ErrAlreadyReversed, ProblemAlreadyReversed, "The transaction was already fully reversed."},
+	{wallet.ErrReversalExceedsOriginal, ProblemReversalExceedsOriginal, "The reversals would exceed the original amount."},
+	{wallet.ErrWalletFrozen, ProblemWalletFrozen, "The wallet is frozen and rejects movements until it is unfrozen."},
+	{wallet.ErrInvalidPeriod, ProblemValidationFailed, "The period must end after it starts and span at most 366 days."},
+	{wallet.ErrFutureAsOf, ProblemValidationFailed, "as_of must not be in the future."},
+	{wallet.ErrVersionMismatch, ProblemPreconditionFailed, "The wallet changed since it was read or does not exist, If-Match no longer matches its ETag."}, //nolint:lll
+	{adjustment.ErrAdjustmentNotFound, ProblemAdjustmentNotFound, "The adjustment does not exist."},
+	{adjustment.ErrNotPending, ProblemAdjustmentReviewed, "The adjustment was already approved or rejected."},
+	{adjustment.ErrSameOperator, ProblemSameOperator, "The adjustment must be reviewed by another operator."},
+	{adjustment.ErrOperatorRequired, ProblemUnauthorized, "The operator is required."},
+	{adjustment.ErrInvalidDirection, ProblemValidationFailed, "The direction must be credit or debit."},
+	{adjustment.ErrReasonRequired, ProblemValidationFailed, "The reason is required."},
+	{adjustment.ErrReasonTooLong, ProblemValidationFailed, "The reason must be at most 500 characters."},
+	{adjustment.ErrEvidenceTooLong, ProblemValidationFailed, "The evidence reference must be at most 255 characters."},
+	{adjustment.ErrCommentTooLong, ProblemValidationFailed, "The comment must be at most 500 characters."},
+	{settlement.ErrImportNotFound, ProblemSettlementNotFound, "The settlement import does not exist."},
+	{settlement.ErrItemNotFound, ProblemSettlementItemNotFound, "The settlement item does not exist."},
+	{settlement.ErrDuplicateImport, ProblemDuplicateSettlement, "The same file was already imported."},
+	{settlement.ErrItemNotOpen, ProblemSettlementItemClosed, "The settlement item was already matched or resolved."},
+	{settlement.ErrAlreadyMatched, ProblemDepositAlreadyMatched, "The deposit is matched to another settlement item."},
+	{settlement.ErrNotDeposit, ProblemValidationFailed, "Only deposits can settle a settlement item."},
+	{settlement.ErrDepositReversed, ProblemValidationFailed, "A fully reversed deposit cannot settle a settlement item."},
+	{settlement.ErrCurrencyMismatch, ProblemValidationFailed, "The deposit currency differs from the settlement item."},
+	{settlement.ErrNoteRequired, ProblemValidationFailed, "The note is required."},
+	{settlement.ErrFileNameRequired, ProblemValidationFailed, "The file name is required."},
+	{settlement.ErrInvalidItemStatus, ProblemValidationFailed, "The status is not a settlement item status."},
+	{settlement.ErrUnsupportedFormat, ProblemValidationFailed, "The format must be csv or camt053."},
+	{settlement.ErrEmptyStatement, ProblemInvalidFile, "The file has no credit lines."},
+	{settlement.ErrOperatorRequired, ProblemUnauthorized, "The operator is required."},
+	{snapshot.ErrDayNotOver, ProblemValidationFailed, "The day is not over yet."},
+	{batch.ErrBatchNotFound, ProblemBatchNotFound, "The batch does not exist."},
+	{batch.ErrBatchNotFinished, ProblemBatchNotFinished, "The batch is still pending or processing."},
+	{batch.ErrEmptyBatch, ProblemValidationFailed, "The batch has no operations."},
+	{batch.ErrTooManyOperations, ProblemValidationFailed, "The batch has too many operations."},
+	{batch.ErrInvalidItemStatus, ProblemValidationFailed, "The status is not a batch item status."},
+	{wallet.ErrSameWallet, ProblemValidationFailed, "The source and destination wallets must differ."},
+	{wallet.ErrCurrencyMismatch, ProblemValidationFailed, "The wallets must have the same currency."},
+	{schedule.ErrScheduleNotFound, ProblemScheduleNotFound, "The schedule does not exist."},
+	{schedule.ErrScheduleNotActive, ProblemScheduleNotActive, "The schedule was completed or cancelled."},
+	{schedule.ErrInvalidRecurrence, ProblemValidationFailed, "The recurrence must be a cron expression of five fields, or @yearly, @monthly, @weekly, @daily or @hourly."}, //nolint:lll
+	{schedule.ErrInvalidStart, ProblemValidationFailed, "start_at of a one-off schedule must be in the future."},
+	{schedule.ErrInvalidEnd, ProblemValidationFailed, "end_at is only allowed for recurring schedules."},
+	{schedule.ErrNoOccurrence, ProblemValidationFailed, "The recurrence has no occurrence between start_at and end_at."},
+	{schedule.ErrInvalidPolicy, ProblemValidationFailed, "on_insufficient_funds must be skip or retry."},
+	{schedule.ErrReferenceTooLong, ProblemValidationFailed, "The reference must be at most 140 characters."},
+	{tenant.ErrUnknownProduct, ProblemUnknownProduct, "The product is not offered by the tenant."},
+	{wallet.ErrInvalidCreditLimit, ProblemValidationFailed, "The credit limit must not be negative, with at most two decimals."}, //nolint:lll
+	{escrow.ErrEscrowNotFound, ProblemEscrowNotFound, "The escrow does not exist."},
+	{escrow.ErrEscrowNotHeld, ProblemEscrowNotHeld, "The escrow was already released, refunded, split or expired."},
+	{escrow.ErrEscrowExpired, ProblemEscrowExpired, "The escrow expired and is settled with its expiry action."},
+	{escrow.ErrInvalidExpiry, ProblemValidationFailed, "expires_at must be in the future."},
+	{escrow.ErrInvalidExpiryAction, ProblemValidationFailed, "on_expiry must be refund or release."},
+	{escrow.ErrInvalidSplit, ProblemValidationFailed, "payee_amount must be between zero and the escrow amount, with at most two decimals."}, //nolint:lll
+	{escrow.ErrReferenceTooLong, ProblemValidationFailed, "The reference must be at most 140 characters."},
+	{escrow.ErrReasonTooLong, ProblemValidationFailed, "The reason must be at most 500 characters."},
+}
+
+// Problem is an RFC 7807 problem details response body.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// WriteProblem writes an `application/problem+json` response of the given type.
+func WriteProblem(w http.ResponseWriter, r *http.Request, problemType ProblemType, detail string) {
+	problem := Problem{
+		Type:      problemTypePrefix + problemType.Code,
+		Title:     problemType.Title,
+		Status:    problemType.Status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      problemType.Code,
+		RequestID: internalHTTP.RequestIDFromContext(r.Context()),
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblemJSON)
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+// WriteError writes the problem mapped to err. Errors without a mapping are logged and reported as internal errors.
+func WriteError(w http.ResponseWriter, r *http.Request, log logger.StructuredLogger, err error) {
+	for _, mapping := range errorProblems {
+		if errors.Is(err, mapping.err) {
+			WriteProblem(w, r, mapping.problem, mapping.detail)
+			return
+		}
+	}
+
+	logging.FromContext(r.Context(), log).Error(
+		"Unexpected error",
+		logger.ErrorField(err),
+	)
+
+	WriteProblem(w, r, ProblemInternal, "")
+}
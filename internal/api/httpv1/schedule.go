@@ -0,0 +1,176 @@
+package httpv1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/schedule"
+)
+
+// CreateScheduleRequest schedules a one-off transfer at StartAt, or a recurring transfer when Recurrence is set.
+type CreateScheduleRequest struct {
+	FromWalletID        string     `json:"from_wallet_id"`
+	ToWalletID          string     `json:"to_wallet_id"`
+	Amount              float64    `json:"amount"`
+	Reference           string     `json:"reference"`
+	StartAt             *time.Time `json:"start_at"`
+	Recurrence          string     `json:"recurrence"`
+	EndAt               *time.Time `json:"end_at"`
+	OnInsufficientFunds string     `json:"on_insufficient_funds"`
+}
+
+// UpdateScheduleRequest changes the fields that are set.
+type UpdateScheduleRequest struct {
+	Amount              *float64   `json:"amount"`
+	Reference           *string    `json:"reference"`
+	StartAt             *time.Time `json:"start_at"`
+	Recurrence          *string    `json:"recurrence"`
+	EndAt               *time.Time `json:"end_at"`
+	OnInsufficientFunds *string    `json:"on_insufficient_funds"`
+}
+
+func NewCreateScheduleHandler(svc schedule.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req CreateScheduleRequest
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&req); err != nil {
+			logging.FromContext(r.Context(), log).Error("Failed to decode schedule request body", logger.ErrorField(err))
+			WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
+			return
+		}
+
+		switch {
+		case req.FromWalletID == "":
+			WriteProblem(w, r, ProblemValidationFailed, "from_wallet_id is required")
+			return
+		case req.ToWalletID == "":
+			WriteProblem(w, r, ProblemValidationFailed, "to_wallet_id is required")
+			return
+		case req.StartAt == nil && req.Recurrence == "":
+			WriteProblem(w, r, ProblemValidationFailed, "start_at or recurrence is required")
+			return
+		}
+
+		instruction := schedule.Instruction{
+			FromWalletID:        req.FromWalletID,
+			ToWalletID:          req.ToWalletID,
+			Amount:              req.Amount,
+			Reference:           req.Reference,
+			Recurrence:          req.Recurrence,
+			EndAt:               req.EndAt,
+			OnInsufficientFunds: schedule.Policy(strings.ToLower(req.OnInsufficientFunds)),
+		}
+
+		if req.StartAt != nil {
+			instruction.StartAt = *req.StartAt
+		}
+
+		created, err := svc.Create(r.Context(), instruction)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		w.Header().Set("Location", "/v1/schedules/"+created.ID)
+		WriteJSON(w, http.StatusCreated, created)
+	}
+}
+
+func NewGetScheduleHandler(svc schedule.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		found, err := svc.Get(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, found)
+	}
+}
+
+// NewListSchedulesHandler lists the schedules, optionally filtered by the `wallet_id` and `status` query parameters.
+func NewListSchedulesHandler(svc schedule.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		schedules, err := svc.List(r.Context(), schedule.Filter{
+			WalletID: query.Get("wallet_id"),
+			Status:   schedule.Status(query.Get("status")),
+		})
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, schedules)
+	}
+}
+
+func NewUpdateScheduleHandler(svc schedule.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req UpdateScheduleRequest
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&req); err != nil {
+			logging.FromContext(r.Context(), log).Error("Failed to decode schedule request body", logger.ErrorField(err))
+			WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
+			return
+		}
+
+		changes := schedule.Changes{
+			Amount:     req.Amount,
+			Reference:  req.Reference,
+			StartAt:    req.StartAt,
+			Recurrence: req.Recurrence,
+			EndAt:      req.EndAt,
+		}
+
+		if req.OnInsufficientFunds != nil {
+			policy := schedule.Policy(strings.ToLower(*req.OnInsufficientFunds))
+			changes.OnInsufficientFunds = &policy
+		}
+
+		updated, err := svc.Update(r.Context(), chi.URLParam(r, "id"), changes)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, updated)
+	}
+}
+
+// NewCancelScheduleHandler cancels an active schedule and returns it, its runs are kept.
+func NewCancelScheduleHandler(svc schedule.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cancelled, err := svc.Cancel(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, cancelled)
+	}
+}
+
+// NewListScheduleRunsHandler lists the attempts to run the occurrences of a schedule, newest first.
+func NewListScheduleRunsHandler(svc schedule.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		runs, err := svc.ListRuns(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, runs)
+	}
+}
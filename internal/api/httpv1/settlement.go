@@ -0,0 +1,197 @@
+package httpv1
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/settlement"
+)
+
+type ResolveSettlementItemRequest struct {
+	TransactionID string `json:"transaction_id"`
+	Note          string `json:"note"`
+}
+
+// settlementFormats maps the content types of settlement files to their format.
+var settlementFormats = map[string]settlement.Format{
+	"text/csv":        settlement.FormatCSV,
+	"application/xml": settlement.FormatCamt053,
+	"text/xml":        settlement.FormatCamt053,
+}
+
+// NewImportSettlementHandler imports the settlement file sent as the request body on behalf of the operator named
+// in operatorHeader. The format is taken from the `format` query parameter, or from the Content-Type, and the file
+// name from the `file_name` query parameter, or from the Content-Disposition header.
+func NewImportSettlementHandler(
+	svc settlement.Service,
+	log logger.StructuredLogger,
+	operatorHeader string,
+	maxFileSize int64,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		operator := r.Header.Get(operatorHeader)
+		if operator == "" {
+			WriteProblem(w, r, ProblemUnauthorized, "Header "+operatorHeader+" is required")
+			return
+		}
+
+		format := settlement.Format(r.URL.Query().Get("format"))
+		if format == "" {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			format = settlementFormats[mediaType]
+		}
+
+		if format != settlement.FormatCSV && format != settlement.FormatCamt053 {
+			WriteProblem(w, r, ProblemValidationFailed, "Format must be csv or camt053")
+			return
+		}
+
+		fileName := r.URL.Query().Get("file_name")
+		if fileName == "" {
+			if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Disposition")); err == nil {
+				fileName = params["filename"]
+			}
+		}
+
+		content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxFileSize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				WriteProblem(w, r, ProblemFileTooLarge, "The file exceeds the maximum size")
+				return
+			}
+
+			logging.FromContext(r.Context(), log).Error("Failed to read settlement file", logger.ErrorField(err))
+			WriteProblem(w, r, ProblemInvalidRequest, "Failed to read the file")
+			return
+		}
+
+		statement, err := settlement.Parse(format, content)
+		if err != nil {
+			var parseErr *settlement.ParseError
+			if errors.As(err, &parseErr) {
+				WriteProblem(w, r, ProblemInvalidFile, parseErr.Error())
+				return
+			}
+
+			WriteError(w, r, log, err)
+			return
+		}
+
+		imported, err := svc.Import(r.Context(), statement, fileName, operator)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		w.Header().Set("Location", "/settlements/"+imported.ID)
+		WriteJSON(w, http.StatusCreated, imported)
+	}
+}
+
+// NewListSettlementsHandler lists the settlement imports of the tenant, newest first, with their item counts.
+func NewListSettlementsHandler(svc settlement.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		imports, err := svc.ListImports(r.Context())
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, imports)
+	}
+}
+
+func NewGetSettlementHandler(svc settlement.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		found, err := svc.GetImport(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, found)
+	}
+}
+
+// NewListSettlementItemsHandler lists the items of an import in file order, optionally filtered by the `status`
+// query parameter. With `format=csv` the items are returned as a CSV report.
+func NewListSettlementItemsHandler(svc settlement.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		format := query.Get("format")
+		if format != "" && format != "json" && format != "csv" {
+			WriteProblem(w, r, ProblemValidationFailed, "Format must be json or csv")
+			return
+		}
+
+		importID := chi.URLParam(r, "id")
+
+		items, err := svc.ListItems(r.Context(), importID, settlement.Status(query.Get("status")))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		if format != "csv" {
+			WriteJSON(w, http.StatusOK, items)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": "settlement-" + importID + ".csv",
+		}))
+		w.WriteHeader(http.StatusOK)
+
+		if err := settlement.WriteItemsCSV(w, items); err != nil {
+			logging.FromContext(r.Context(), log).Error("Failed to write settlement report", logger.ErrorField(err))
+		}
+	}
+}
+
+// NewResolveSettlementItemHandler resolves an unmatched or ambiguous item on behalf of the operator named in
+// operatorHeader, matching it to the deposit `transaction_id`, or dismissing it when `transaction_id` is omitted.
+func NewResolveSettlementItemHandler(
+	svc settlement.Service,
+	log logger.StructuredLogger,
+	operatorHeader string,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		operator := r.Header.Get(operatorHeader)
+		if operator == "" {
+			WriteProblem(w, r, ProblemUnauthorized, "Header "+operatorHeader+" is required")
+			return
+		}
+
+		var req ResolveSettlementItemRequest
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&req); err != nil {
+			logging.FromContext(r.Context(), log).Error("Failed to decode resolve request body", logger.ErrorField(err))
+			WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
+			return
+		}
+
+		resolved, err := svc.Resolve(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "itemID"), settlement.Resolution{
+			TransactionID: req.TransactionID,
+			Note:          req.Note,
+			ResolvedBy:    operator,
+		})
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, resolved)
+	}
+}
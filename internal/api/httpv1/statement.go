@@ -0,0 +1,59 @@
+package httpv1
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/statement"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+// NewStatementHandler returns the statement of the wallet for the period given by the `from` and `to` query
+// parameters, as JSON, CSV or PDF according to the `format` query parameter.
+func NewStatementHandler(svc wallet.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		format, err := statement.ParseFormat(query.Get("format"))
+		if err != nil {
+			WriteProblem(w, r, ProblemValidationFailed, "Format must be json, csv or pdf")
+			return
+		}
+
+		from, to, err := statement.ParsePeriod(query.Get("from"), query.Get("to"))
+		if err != nil {
+			WriteProblem(w, r, ProblemValidationFailed, "from and to must be dates (YYYY-MM-DD) or RFC 3339 timestamps")
+			return
+		}
+
+		walletStatement, err := svc.Statement(r.Context(), chi.URLParam(r, "id"), from, to)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		// NOTE: Rendering into a buffer first turns rendering failures into a proper error response.
+		var body bytes.Buffer
+		if err := statement.Write(&body, walletStatement, format); err != nil {
+			logging.FromContext(r.Context(), log).Error("Failed to render statement", logger.ErrorField(err))
+			WriteProblem(w, r, ProblemInternal, "")
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+
+		if format != statement.FormatJSON {
+			w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+				"filename": statement.FileName(walletStatement, format),
+			}))
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = body.WriteTo(w)
+	}
+}
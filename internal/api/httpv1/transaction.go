@@ -0,0 +1,111 @@
+package httpv1
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+// ReverseRequest reverses Amount of the transaction, or everything not reversed yet when Amount is omitted.
+type ReverseRequest struct {
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+}
+
+type TransactionResponse struct {
+	ID             string  `json:"id"`
+	WalletID       string  `json:"wallet_id"`
+	Type           string  `json:"type"`
+	Direction      string  `json:"direction"`
+	Amount         float64 `json:"amount"`
+	Currency       string  `json:"currency"`
+	BalanceAfter   float64 `json:"balance_after"`
+	ReversalOf     string  `json:"reversal_of,omitempty"`
+	ReversedAmount float64 `json:"reversed_amount"`
+	Reason         string  `json:"reason,omitempty"`
+	Reference      string  `json:"reference,omitempty"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// TransactionLocation returns the URL of the transaction resource.
+func TransactionLocation(transaction *wallet.Transaction) string {
+	return "/v1/transactions/" + transaction.ID
+}
+
+func newTransactionResponse(transaction *wallet.Transaction) TransactionResponse {
+	return TransactionResponse{
+		ID:             transaction.ID,
+		WalletID:       transaction.WalletID,
+		Type:           string(transaction.Type),
+		Direction:      string(transaction.Direction),
+		Amount:         transaction.Amount,
+		Currency:       transaction.Currency,
+		BalanceAfter:   transaction.BalanceAfter,
+		ReversalOf:     transaction.ReversalOf,
+		ReversedAmount: transaction.ReversedAmount,
+		Reason:         transaction.Reason,
+		Reference:      transaction.Reference,
+		CreatedAt:      transaction.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func NewGetTransactionHandler(svc wallet.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		transactionID := chi.URLParam(r, "id")
+		if transactionID == "" {
+			WriteProblem(w, r, ProblemValidationFailed, "Transaction ID is required")
+			return
+		}
+
+		transaction, err := svc.GetTransaction(r.Context(), transactionID)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, newTransactionResponse(transaction))
+	}
+}
+
+func NewReverseHandler(svc wallet.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		transactionID := chi.URLParam(r, "id")
+		if transactionID == "" {
+			WriteProblem(w, r, ProblemValidationFailed, "Transaction ID is required")
+			return
+		}
+
+		var req ReverseRequest
+		if r.ContentLength != 0 {
+			decoder := json.NewDecoder(r.Body)
+			decoder.DisallowUnknownFields() // Prevent unknown fields
+
+			if err := decoder.Decode(&req); err != nil {
+				logging.FromContext(r.Context(), log).Error("Failed to decode reverse request body", logger.ErrorField(err))
+				WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
+				return
+			}
+		}
+
+		if req.Amount < 0 {
+			WriteProblem(w, r, ProblemInvalidAmount, "Amount must be greater than zero")
+			return
+		}
+
+		reversal, err := svc.Reverse(r.Context(), transactionID, req.Amount, req.Reason)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		w.Header().Set("Location", TransactionLocation(reversal))
+		w.Header().Set("ETag", WalletETag(reversal.WalletVersion))
+		WriteJSON(w, http.StatusCreated, newTransactionResponse(reversal))
+	}
+}
@@ -5,106 +5,143 @@ import (
 	"net/http"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/sumup-oss/go-pkgs/logger"
 
 	"encoding/json"
-	"errors"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
 	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
 
 	"github.com/go-chi/chi/v5"
 )
 
+// CreateWalletRequest opens a wallet, Product is the optional product of the tenant, e.g. a savings wallet.
 type CreateWalletRequest struct {
 	Currency string `json:"currency"`
+	Product  string `json:"product"`
 }
 
 type WalletResponse struct {
-	ID        string  `json:"id"`
-	Balance   float64 `json:"balance"`
-	Currency  string  `json:"currency"`
-	CreatedAt string  `json:"created_at"`
-	UpdatedAt string  `json:"updated_at"`
+	ID      string  `json:"id"`
+	Balance float64 `json:"balance"`
+	// CreditLimit is how far below zero the balance may go, AvailableBalance the balance plus the credit limit.
+	CreditLimit      float64 `json:"credit_limit"`
+	AvailableBalance float64 `json:"available_balance"`
+	Currency         string  `json:"currency"`
+	Status           string  `json:"status"`
+	StatusReason     string  `json:"status_reason,omitempty"`
+	Product          string  `json:"product,omitempty"`
+	CreatedAt        string  `json:"created_at"`
+	UpdatedAt        string  `json:"updated_at"`
+	// AsOf is the instant the balance was computed at, set when the wallet was requested with `as_of`.
+	AsOf string `json:"as_of,omitempty"`
+}
+
+func newWalletResponse(model *wallet.Wallet) WalletResponse {
+	return WalletResponse{
+		ID:               model.ID,
+		Balance:          model.Balance,
+		CreditLimit:      model.CreditLimit,
+		AvailableBalance: model.AvailableBalance(),
+		Currency:         model.Currency,
+		Status:           string(model.Status),
+		StatusReason:     model.StatusReason,
+		Product:          model.Product,
+		CreatedAt:        model.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        model.UpdatedAt.Format(time.RFC3339),
+	}
 }
 
 type OperationRequest struct {
 	Balance float64 `json:"balance"`
 }
 
+// DepositRequest deposits Balance, Reference is the optional payment reference matched against settlement files.
+type DepositRequest struct {
+	Balance   float64 `json:"balance"`
+	Reference string  `json:"reference"`
+}
+
 func NewCreateWalletHandler(svc wallet.Service, log logger.StructuredLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Body == nil {
-			WriteError(w, http.StatusBadRequest, "Request body is required")
+			WriteProblem(w, r, ProblemInvalidRequest, "Request body is required")
 			return
 		}
 
 		var req CreateWalletRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			log.Error("Failed to decode request body")
-			WriteError(w, http.StatusBadRequest, "Invalid request body")
+			logging.FromContext(r.Context(), log).Error("Failed to decode request body")
+			WriteProblem(w, r, ProblemInvalidRequest, "Invalid request body")
 			return
 		}
 
 		if req.Currency == "" {
-			WriteError(w, http.StatusBadRequest, "Currency is required")
+			WriteProblem(w, r, ProblemValidationFailed, "Currency is required")
 			return
 		}
 
 		req.Currency = strings.ToUpper(req.Currency)
 		if len(req.Currency) != 3 {
-			WriteError(w, http.StatusBadRequest, "Currency must be a 3-letter ISO code")
+			WriteProblem(w, r, ProblemValidationFailed, "Currency must be a 3-letter ISO code")
 			return
 		}
 
-		newWallet, err := svc.CreateWallet(r.Context(), req.Currency)
+		newWallet, err := svc.CreateWallet(r.Context(), req.Currency, req.Product)
 		if err != nil {
-			log.Error("Failed to create wallet")
-			WriteError(w, http.StatusInternalServerError, "Failed to create wallet")
+			WriteError(w, r, log, err)
 			return
 		}
 
-		response := WalletResponse{
-			ID:        newWallet.ID,
-			Balance:   newWallet.Balance,
-			Currency:  newWallet.Currency,
-			CreatedAt: newWallet.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: newWallet.UpdatedAt.Format(time.RFC3339),
-		}
-
-		WriteJSON(w, http.StatusCreated, response)
+		writeWallet(w, http.StatusCreated, newWalletResponse(newWallet), newWallet.Version)
 	}
 }
 
+// NewGetWalletHandler returns the wallet with its version as ETag, or 304 Not Modified when the ETag matches
+// If-None-Match. With `as_of`, the wallet is returned with its balance at that instant and without ETag.
 func NewGetWalletHandler(svc wallet.Service, log logger.StructuredLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		walletID := chi.URLParam(r, "id")
 		if walletID == "" {
-			WriteError(w, http.StatusBadRequest, "Wallet ID is required")
+			WriteProblem(w, r, ProblemValidationFailed, "Wallet ID is required")
 			return
 		}
 
-		foundWallet, err := svc.GetWallet(r.Context(), walletID)
-		if err != nil {
-			// Add this debug line
-			log.Error(fmt.Sprintf("Error getting wallet: %v", err))
+		asOfParam := r.URL.Query().Get("as_of")
+		if asOfParam == "" {
+			foundWallet, err := svc.GetWallet(r.Context(), walletID)
+			if err != nil {
+				WriteError(w, r, log, err)
+				return
+			}
 
-			if errors.Is(err, wallet.ErrWalletNotFound) {
-				WriteError(w, http.StatusNotFound, "Wallet not found")
+			if etag := WalletETag(foundWallet.Version); !noneMatch(r, etag) {
+				w.Header().Set("ETag", etag)
+				w.WriteHeader(http.StatusNotModified)
 				return
 			}
-			log.Error("Failed to get wallet")
-			WriteError(w, http.StatusInternalServerError, "Failed to get wallet")
+
+			writeWallet(w, http.StatusOK, newWalletResponse(foundWallet), foundWallet.Version)
 			return
 		}
 
-		response := WalletResponse{
-			ID:        foundWallet.ID,
-			Balance:   foundWallet.Balance,
-			Currency:  foundWallet.Currency,
-			CreatedAt: foundWallet.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: foundWallet.UpdatedAt.Format(time.RFC3339),
+		// A date stands for the end of that day, so that `as_of=2025-01-31` includes every movement of January 31st.
+		asOf, err := wallet.ParseBound(asOfParam, true)
+		if err != nil {
+			WriteProblem(w, r, ProblemValidationFailed, "as_of must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+			return
 		}
 
+		foundWallet, err := svc.GetWalletAsOf(r.Context(), walletID, asOf)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		response := newWalletResponse(foundWallet)
+		response.AsOf = asOf.Format(time.RFC3339)
+
 		WriteJSON(w, http.StatusOK, response)
 	}
 }
@@ -113,38 +150,40 @@ func NewDepositHandler(svc wallet.Service, log logger.StructuredLogger) http.Han
 	return func(w http.ResponseWriter, r *http.Request) {
 		walletID := chi.URLParam(r, "id")
 		if walletID == "" {
-			WriteError(w, http.StatusBadRequest, "Wallet ID is required")
+			WriteProblem(w, r, ProblemValidationFailed, "Wallet ID is required")
 			return
 		}
 
-		var req OperationRequest
+		var req DepositRequest
 		decoder := json.NewDecoder(r.Body)
 		decoder.DisallowUnknownFields() // Prevent unknown fields
 
 		if err := decoder.Decode(&req); err != nil {
-			log.Error(fmt.Sprintf("Failed to decode deposit request body: %v", err))
-			WriteError(w, http.StatusBadRequest, "Invalid JSON format or unknown fields")
+			logging.FromContext(r.Context(), log).Error(fmt.Sprintf("Failed to decode deposit request body: %v", err))
+			WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
 			return
 		}
 
 		if req.Balance <= 0 {
-			WriteError(w, http.StatusBadRequest, "Amount must be greater than zero")
+			WriteProblem(w, r, ProblemInvalidAmount, "Amount must be greater than zero")
 			return
 		}
 
-		if err := svc.Deposit(r.Context(), walletID, req.Balance); err != nil {
-			switch {
-			case errors.Is(err, wallet.ErrInvalidAmount):
-				WriteError(w, http.StatusBadRequest, "Invalid amount")
-			case errors.Is(err, wallet.ErrWalletNotFound):
-				WriteError(w, http.StatusNotFound, "Wallet not found")
-			default:
-				log.Error("Failed to process deposit")
-				WriteError(w, http.StatusInternalServerError, "Failed to process deposit")
-			}
+		reference := strings.TrimSpace(req.Reference)
+		if utf8.RuneCountInString(reference) > wallet.MaxReferenceLength {
+			WriteProblem(w, r, ProblemValidationFailed,
+				fmt.Sprintf("Reference must be at most %d characters", wallet.MaxReferenceLength))
 			return
 		}
 
+		transaction, err := svc.Deposit(r.Context(), walletID, req.Balance, reference)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		w.Header().Set("Location", TransactionLocation(transaction))
+		w.Header().Set("ETag", WalletETag(transaction.WalletVersion))
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
@@ -153,7 +192,7 @@ func NewWithdrawHandler(svc wallet.Service, log logger.StructuredLogger) http.Ha
 	return func(w http.ResponseWriter, r *http.Request) {
 		walletID := chi.URLParam(r, "id")
 		if walletID == "" {
-			WriteError(w, http.StatusBadRequest, "Wallet ID is required")
+			WriteProblem(w, r, ProblemValidationFailed, "Wallet ID is required")
 			return
 		}
 
@@ -162,31 +201,24 @@ func NewWithdrawHandler(svc wallet.Service, log logger.StructuredLogger) http.Ha
 		decoder.DisallowUnknownFields() // Prevent unknown fields
 
 		if err := decoder.Decode(&req); err != nil {
-			log.Error(fmt.Sprintf("Failed to decode withdraw request body: %v", err))
-			WriteError(w, http.StatusBadRequest, "Invalid JSON format or unknown fields")
+			logging.FromContext(r.Context(), log).Error(fmt.Sprintf("Failed to decode withdraw request body: %v", err))
+			WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
 			return
 		}
 
 		if req.Balance <= 0 {
-			WriteError(w, http.StatusBadRequest, "Amount must be greater than zero")
+			WriteProblem(w, r, ProblemInvalidAmount, "Amount must be greater than zero")
 			return
 		}
 
-		if err := svc.Withdraw(r.Context(), walletID, req.Balance); err != nil {
-			switch {
-			case errors.Is(err, wallet.ErrInvalidAmount):
-				WriteError(w, http.StatusBadRequest, "Invalid amount")
-			case errors.Is(err, wallet.ErrInsufficientFunds):
-				WriteError(w, http.StatusBadRequest, "Insufficient funds")
-			case errors.Is(err, wallet.ErrWalletNotFound):
-				WriteError(w, http.StatusNotFound, "Wallet not found")
-			default:
-				log.Error("Failed to process withdrawal")
-				WriteError(w, http.StatusInternalServerError, "Failed to process withdrawal")
-			}
+		transaction, err := svc.Withdraw(r.Context(), walletID, req.Balance)
+		if err != nil {
+			WriteError(w, r, log, err)
 			return
 		}
 
+		w.Header().Set("Location", TransactionLocation(transaction))
+		w.Header().Set("ETag", WalletETag(transaction.WalletVersion))
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
@@ -197,6 +229,108 @@ func WriteJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
-func WriteError(w http.ResponseWriter, status int, message string) {
-	WriteJSON(w, status, map[string]string{"error": message})
+type FreezeWalletRequest struct {
+	Reason string `json:"reason"`
+}
+
+// NewFreezeWalletHandler freezes the wallet on behalf of the operator named in operatorHeader.
+func NewFreezeWalletHandler(svc wallet.Service, log logger.StructuredLogger, operatorHeader string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(operatorHeader) == "" {
+			WriteProblem(w, r, ProblemUnauthorized, "Header "+operatorHeader+" is required")
+			return
+		}
+
+		var req FreezeWalletRequest
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&req); err != nil {
+			logging.FromContext(r.Context(), log).Error("Failed to decode freeze request body", logger.ErrorField(err))
+			WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
+			return
+		}
+
+		if strings.TrimSpace(req.Reason) == "" {
+			WriteProblem(w, r, ProblemValidationFailed, "Reason is required")
+			return
+		}
+
+		frozen, err := svc.Freeze(r.Context(), chi.URLParam(r, "id"), req.Reason)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		writeWallet(w, http.StatusOK, newWalletResponse(frozen), frozen.Version)
+	}
+}
+
+// NewUnfreezeWalletHandler unfreezes the wallet on behalf of the operator named in operatorHeader.
+func NewUnfreezeWalletHandler(svc wallet.Service, log logger.StructuredLogger, operatorHeader string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(operatorHeader) == "" {
+			WriteProblem(w, r, ProblemUnauthorized, "Header "+operatorHeader+" is required")
+			return
+		}
+
+		unfrozen, err := svc.Unfreeze(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		writeWallet(w, http.StatusOK, newWalletResponse(unfrozen), unfrozen.Version)
+	}
+}
+
+// CreditLimitRequest sets the credit limit of a wallet, 0 removes its overdraft.
+type CreditLimitRequest struct {
+	CreditLimit *float64 `json:"credit_limit"`
+}
+
+// NewSetCreditLimitHandler sets the credit limit of the wallet on behalf of the operator named in operatorHeader.
+func NewSetCreditLimitHandler(svc wallet.Service, log logger.StructuredLogger, operatorHeader string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(operatorHeader) == "" {
+			WriteProblem(w, r, ProblemUnauthorized, "Header "+operatorHeader+" is required")
+			return
+		}
+
+		var req CreditLimitRequest
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&req); err != nil {
+			logging.FromContext(r.Context(), log).Error("Failed to decode credit limit request body", logger.ErrorField(err))
+			WriteProblem(w, r, ProblemInvalidRequest, "Invalid JSON format or unknown fields")
+			return
+		}
+
+		if req.CreditLimit == nil {
+			WriteProblem(w, r, ProblemValidationFailed, "credit_limit is required")
+			return
+		}
+
+		updated, err := svc.SetCreditLimit(r.Context(), chi.URLParam(r, "id"), *req.CreditLimit)
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		writeWallet(w, http.StatusOK, newWalletResponse(updated), updated.Version)
+	}
+}
+
+// NewOverdraftHandler returns the use of the credit limit of the wallet with its latest alerts.
+func NewOverdraftHandler(svc wallet.Service, log logger.StructuredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		overdraft, err := svc.Overdraft(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			WriteError(w, r, log, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, overdraft)
+	}
 }
@@ -5,16 +5,18 @@ import (
 
 	"github.com/sumup-oss/go-pkgs/errors"
 	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/api/httpv1"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
 )
 
 func WritePanicResponse(log logger.StructuredLogger) stdHTTP.HandlerFunc {
 	return func(w stdHTTP.ResponseWriter, r *stdHTTP.Request) {
-		w.WriteHeader(stdHTTP.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
+		httpv1.WriteProblem(w, r, httpv1.ProblemInternal, "")
 
 		err := errors.New("internal server error")
 		if err != nil {
-			log.Error(
+			logging.FromContext(r.Context(), log).Error(
 				"error",
 				logger.ErrorField(err),
 			)
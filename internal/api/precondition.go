@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/api/httpv1"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+// IfMatch makes the wallet operations of the request conditional on the If-Match header: the wallet they update
+// must still be at the version of the ETag, otherwise they fail with 412 Precondition Failed. `*` matches any
+// existing wallet, so it fails with 412 as well when the wallet does not exist. Only a single ETag is supported.
+func IfMatch(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		header := strings.TrimSpace(r.Header.Get("If-Match"))
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if header == "*" {
+			next.ServeHTTP(w, r.WithContext(wallet.NewVersionContext(r.Context(), wallet.AnyVersion)))
+			return
+		}
+
+		if strings.Contains(header, ",") {
+			httpv1.WriteProblem(w, r, httpv1.ProblemValidationFailed, `If-Match must be "*" or a single ETag`)
+			return
+		}
+
+		version, ok := httpv1.ParseWalletETag(header)
+		if !ok {
+			httpv1.WriteProblem(w, r, httpv1.ProblemPreconditionFailed, "If-Match does not match the ETag of the wallet.")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(wallet.NewVersionContext(r.Context(), version)))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// RejectIfMatch refuses the If-Match header on operations that may update several wallets, e.g. settling an escrow
+// pays the payee and refunds the payer, so no single ETag could be matched.
+func RejectIfMatch(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Match") != "" {
+			detail := "If-Match is not supported, the operation may update several wallets"
+			httpv1.WriteProblem(w, r, httpv1.ProblemValidationFailed, detail)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+func TestIfMatch_MissingWallet(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    error
+	}{
+		{"without If-Match", "", wallet.ErrWalletNotFound},
+		{"any version", "*", wallet.ErrVersionMismatch},
+		{"single ETag", `"7"`, wallet.ErrVersionMismatch},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got error
+
+			handler := IfMatch(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = wallet.CheckFound(r.Context(), wallet.ErrWalletNotFound)
+			}))
+
+			request := httptest.NewRequest(http.MethodPost, "/wallets/id/withdraw", nil)
+			if tc.ifMatch != "" {
+				request.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+
+			if !errors.Is(got, tc.want) {
+				t.Errorf("error = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package api
+
+import (
+	stdHTTP "net/http"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/api/httpv1"
+)
+
+func WriteRateLimitResponse(w stdHTTP.ResponseWriter, r *stdHTTP.Request) {
+	httpv1.WriteProblem(w, r, httpv1.ProblemRateLimited, "Retry after the number of seconds in the Retry-After header")
+}
@@ -1,24 +1,173 @@
 package api
 
 import (
+	"net/http"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/adjustment"
 	"tribe-payments-wallet-golang-interview-assignment/internal/api/httpv1"
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/batch"
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/escrow"
+	"tribe-payments-wallet-golang-interview-assignment/internal/health"
+	"tribe-payments-wallet-golang-interview-assignment/internal/interest"
+	"tribe-payments-wallet-golang-interview-assignment/internal/ledger"
+	"tribe-payments-wallet-golang-interview-assignment/internal/schedule"
+	"tribe-payments-wallet-golang-interview-assignment/internal/settlement"
+	"tribe-payments-wallet-golang-interview-assignment/internal/snapshot"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
 	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/sumup-oss/go-pkgs/logger"
 )
 
 func RegisterRoutes(
 	mux *chi.Mux,
 	log logger.StructuredLogger,
+	readiness *health.Readiness,
+	tenants *tenant.Registry,
+	tenancyCfg config.Tenancy,
 	walletService wallet.Service,
+	batchService batch.Service,
+	batchCfg config.Batch,
+	scheduleService schedule.Service,
+	interestAccruer *interest.Accruer,
+	escrowService escrow.Service,
 ) {
+	mux.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		httpv1.WriteProblem(w, r, httpv1.ProblemNotFound, "")
+	})
+	mux.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		httpv1.WriteProblem(w, r, httpv1.ProblemMethodNotAllowed, "")
+	})
+
+	// NOTE: The probes are also served on the admin listener, which load balancers usually cannot reach. Only the
+	// admin listener details the checks.
 	mux.Get("/live", Health)
+	mux.Get("/ready", readiness.StatusHandler)
 
 	mux.Route("/v1", func(r chi.Router) {
+		r.Use(
+			ResolveTenant(log, tenants, tenancyCfg),
+			IdentifyClient(tenancyCfg.APIKeyHeader, tenancyCfg.UserHeader),
+		)
+
 		r.Post("/wallets", httpv1.NewCreateWalletHandler(walletService, log))
 		r.Get("/wallets/{id}", httpv1.NewGetWalletHandler(walletService, log))
-		r.Post("/wallets/{id}/deposit", httpv1.NewDepositHandler(walletService, log))
-		r.Post("/wallets/{id}/withdraw", httpv1.NewWithdrawHandler(walletService, log))
+		r.With(IfMatch).Post("/wallets/{id}/deposit", httpv1.NewDepositHandler(walletService, log))
+		r.With(IfMatch).Post("/wallets/{id}/withdraw", httpv1.NewWithdrawHandler(walletService, log))
+		r.Get("/wallets/{id}/statement", httpv1.NewStatementHandler(walletService, log))
+		r.Get("/wallets/{id}/interest", httpv1.NewAccruedInterestHandler(interestAccruer, log))
+		r.Get("/wallets/{id}/overdraft", httpv1.NewOverdraftHandler(walletService, log))
+		r.Get("/transactions/{id}", httpv1.NewGetTransactionHandler(walletService, log))
+		r.With(IfMatch).Post("/transactions/{id}/reverse", httpv1.NewReverseHandler(walletService, log))
+		r.Post("/batches", httpv1.NewSubmitBatchHandler(batchService, log, batchCfg.MaxFileSize))
+		r.Get("/batches/{id}", httpv1.NewGetBatchHandler(batchService, log))
+		r.Get("/batches/{id}/result", httpv1.NewBatchResultHandler(batchService, log))
+		r.Get("/schedules", httpv1.NewListSchedulesHandler(scheduleService, log))
+		r.Post("/schedules", httpv1.NewCreateScheduleHandler(scheduleService, log))
+		r.Get("/schedules/{id}", httpv1.NewGetScheduleHandler(scheduleService, log))
+		r.Patch("/schedules/{id}", httpv1.NewUpdateScheduleHandler(scheduleService, log))
+		r.Delete("/schedules/{id}", httpv1.NewCancelScheduleHandler(scheduleService, log))
+		r.Get("/schedules/{id}/runs", httpv1.NewListScheduleRunsHandler(scheduleService, log))
+		r.Get("/escrows", httpv1.NewListEscrowsHandler(escrowService, log))
+		r.With(IfMatch).Post("/escrows", httpv1.NewCreateEscrowHandler(escrowService, log))
+		r.Get("/escrows/{id}", httpv1.NewGetEscrowHandler(escrowService, log))
+		r.With(RejectIfMatch).Post("/escrows/{id}/release", httpv1.NewReleaseEscrowHandler(escrowService, log))
+		r.With(RejectIfMatch).Post("/escrows/{id}/refund", httpv1.NewRefundEscrowHandler(escrowService, log))
+		r.With(RejectIfMatch).Post("/escrows/{id}/split", httpv1.NewSplitEscrowHandler(escrowService, log))
+		r.Get("/escrows/{id}/events", httpv1.NewListEscrowEventsHandler(escrowService, log))
+	})
+}
+
+// RegisterAdminRoutes registers the operational endpoints served on the admin listener.
+func RegisterAdminRoutes(
+	mux *chi.Mux,
+	log logger.StructuredLogger,
+	tenants *tenant.Registry,
+	readiness *health.Readiness,
+	metricsHandler http.Handler,
+	logLevelHandler http.Handler,
+	cfg *config.ServerConfig,
+	walletService wallet.Service,
+	adjustmentService adjustment.Service,
+	settlementService settlement.Service,
+	snapshotter *snapshot.Snapshotter,
+	auditLog audit.Log,
+	walletLedger ledger.Ledger,
+) {
+	mux.Get("/live", Health)
+	mux.Get("/ready", readiness.Handler)
+	mux.Method(http.MethodGet, "/metrics", metricsHandler)
+	mux.Get("/config", NewConfigHandler(cfg))
+	mux.Method(http.MethodGet, "/log/level", logLevelHandler)
+	mux.Method(http.MethodPut, "/log/level", logLevelHandler)
+	mux.Mount("/debug", middleware.Profiler())
+
+	mux.Route("/adjustments", func(r chi.Router) {
+		r.Use(
+			ResolveTenant(log, tenants, cfg.Tenancy),
+			RequireAuthenticatedOperator(cfg.OperatorSigningKey),
+			IdentifyOperator(cfg.OperatorHeader, cfg.OperatorSignatureHeader, cfg.OperatorSigningKey),
+		)
+
+		r.Get("/", httpv1.NewListAdjustmentsHandler(adjustmentService, log))
+		r.Post("/", httpv1.NewProposeAdjustmentHandler(adjustmentService, log, cfg.OperatorHeader))
+		r.Get("/{id}", httpv1.NewGetAdjustmentHandler(adjustmentService, log))
+		r.With(IfMatch).Post(
+			"/{id}/approve",
+			httpv1.NewApproveAdjustmentHandler(adjustmentService, log, cfg.OperatorHeader),
+		)
+		r.Post("/{id}/reject", httpv1.NewRejectAdjustmentHandler(adjustmentService, log, cfg.OperatorHeader))
+	})
+
+	mux.Route("/settlements", func(r chi.Router) {
+		r.Use(
+			ResolveTenant(log, tenants, cfg.Tenancy),
+			IdentifyOperator(cfg.OperatorHeader, cfg.OperatorSignatureHeader, cfg.OperatorSigningKey),
+		)
+
+		r.Get("/", httpv1.NewListSettlementsHandler(settlementService, log))
+		r.Post("/", httpv1.NewImportSettlementHandler(
+			settlementService,
+			log,
+			cfg.OperatorHeader,
+			cfg.Settlement.MaxFileSize,
+		))
+		r.Get("/{id}", httpv1.NewGetSettlementHandler(settlementService, log))
+		r.Get("/{id}/items", httpv1.NewListSettlementItemsHandler(settlementService, log))
+		r.Post(
+			"/{id}/items/{itemID}/resolve",
+			httpv1.NewResolveSettlementItemHandler(settlementService, log, cfg.OperatorHeader),
+		)
+	})
+
+	mux.Route("/wallets/{id}", func(r chi.Router) {
+		r.Use(
+			ResolveTenant(log, tenants, cfg.Tenancy),
+			IdentifyOperator(cfg.OperatorHeader, cfg.OperatorSignatureHeader, cfg.OperatorSigningKey),
+			IfMatch,
+		)
+
+		r.Post("/freeze", httpv1.NewFreezeWalletHandler(walletService, log, cfg.OperatorHeader))
+		r.Post("/unfreeze", httpv1.NewUnfreezeWalletHandler(walletService, log, cfg.OperatorHeader))
+		r.Put("/credit-limit", httpv1.NewSetCreditLimitHandler(walletService, log, cfg.OperatorHeader))
+	})
+
+	mux.Route("/balances", func(r chi.Router) {
+		r.Use(ResolveTenant(log, tenants, cfg.Tenancy))
+
+		r.Get("/", httpv1.NewEndOfDayBalancesHandler(snapshotter, log))
+	})
+
+	mux.Get("/ledger/invariants", httpv1.NewLedgerInvariantsHandler(walletLedger, log))
+
+	mux.Route("/audit", func(r chi.Router) {
+		r.Use(ResolveTenant(log, tenants, cfg.Tenancy))
+
+		r.Get("/", httpv1.NewListAuditHandler(auditLog, log))
+		r.Get("/verify", httpv1.NewVerifyAuditHandler(auditLog, log))
 	})
 }
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/api/httpv1"
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+)
+
+// ResolveTenant stores the tenant of the request in its context.
+// The tenant is taken from the API key when one is presented, otherwise from the tenant header,
+// otherwise the configured default tenant is used. Tenants with API keys can only be reached with one of them.
+func ResolveTenant(
+	log logger.StructuredLogger,
+	registry *tenant.Registry,
+	cfg config.Tenancy,
+) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			apiKey := r.Header.Get(cfg.APIKeyHeader)
+			tenantID := r.Header.Get(cfg.Header)
+
+			var (
+				t   *tenant.Tenant
+				err error
+			)
+
+			switch {
+			case apiKey != "":
+				t, err = registry.ByAPIKey(apiKey)
+				if err != nil {
+					httpv1.WriteProblem(w, r, httpv1.ProblemUnauthorized, "Invalid API key")
+					return
+				}
+
+				if tenantID != "" && tenantID != t.ID {
+					logging.FromContext(r.Context(), log).Warn("Tenant header does not match API key", zap.String("tenant_id", tenantID))
+					httpv1.WriteProblem(w, r, httpv1.ProblemForbidden, "Tenant does not match API key")
+					return
+				}
+			case tenantID != "":
+				t, err = registry.ByID(tenantID)
+				if err != nil {
+					httpv1.WriteProblem(w, r, httpv1.ProblemUnauthorized, "Unknown tenant")
+					return
+				}
+			case cfg.DefaultTenant != "":
+				t, err = registry.ByID(cfg.DefaultTenant)
+				if err != nil {
+					httpv1.WriteProblem(w, r, httpv1.ProblemUnauthorized, "Tenant is required")
+					return
+				}
+			default:
+				httpv1.WriteProblem(w, r, httpv1.ProblemUnauthorized, "Tenant is required")
+				return
+			}
+
+			if apiKey == "" && t.RequiresAPIKey() {
+				httpv1.WriteProblem(w, r, httpv1.ProblemUnauthorized, "API key is required")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(tenant.NewContext(r.Context(), t)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
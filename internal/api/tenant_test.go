@@ -0,0 +1,262 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/api/httpv1"
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/ledger"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+// memoryWalletRepository keeps wallets and transactions in memory. Like the queries of wallet.Repository, every
+// read and update only sees the rows of the tenant of ctx.
+type memoryWalletRepository struct {
+	wallet.Repository
+
+	wallets      map[string]*wallet.Wallet
+	transactions map[string]*wallet.Transaction
+}
+
+func (r *memoryWalletRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *memoryWalletRepository) AfterCommit(_ context.Context, fn func()) {
+	fn()
+}
+
+func (r *memoryWalletRepository) Get(ctx context.Context, id string) (*wallet.Wallet, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	found, ok := r.wallets[id]
+	if !ok || found.TenantID != t.ID {
+		return nil, wallet.ErrWalletNotFound
+	}
+
+	copied := *found
+
+	return &copied, nil
+}
+
+func (r *memoryWalletRepository) GetForUpdate(ctx context.Context, id string) (*wallet.Wallet, error) {
+	return r.Get(ctx, id)
+}
+
+func (r *memoryWalletRepository) AddToBalance(
+	ctx context.Context,
+	id string,
+	amount float64,
+	_ bool,
+) (*wallet.Wallet, error) {
+	if _, err := r.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	r.wallets[id].Balance += amount
+	r.wallets[id].Version++
+
+	return r.Get(ctx, id)
+}
+
+func (r *memoryWalletRepository) CreateTransaction(ctx context.Context, transaction *wallet.Transaction) error {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	transaction.ID = "transaction-" + transaction.WalletID
+	transaction.TenantID = t.ID
+	transaction.CreatedAt = time.Now().UTC()
+	r.transactions[transaction.ID] = transaction
+
+	return nil
+}
+
+func (r *memoryWalletRepository) GetTransaction(ctx context.Context, id string) (*wallet.Transaction, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	found, ok := r.transactions[id]
+	if !ok || found.TenantID != t.ID {
+		return nil, wallet.ErrTransactionNotFound
+	}
+
+	return found, nil
+}
+
+func (r *memoryWalletRepository) ListTransactions(
+	ctx context.Context,
+	walletID string,
+	_ time.Time,
+	_ time.Time,
+) ([]*wallet.Transaction, error) {
+	if _, err := r.Get(ctx, walletID); err != nil {
+		return nil, err
+	}
+
+	return []*wallet.Transaction{}, nil
+}
+
+func (r *memoryWalletRepository) BalanceAt(context.Context, string, time.Time) (float64, error) {
+	return 0, nil
+}
+
+// memoryLedger accepts every balanced journal.
+type memoryLedger struct {
+	ledger.Ledger
+}
+
+func (memoryLedger) WalletAccount(_ context.Context, walletID string, currency string) (*ledger.Account, error) {
+	return &ledger.Account{ID: "account-" + walletID, Type: ledger.AccountWallet, Currency: currency}, nil
+}
+
+func (memoryLedger) SystemAccount(
+	_ context.Context,
+	accountType ledger.AccountType,
+	currency string,
+) (*ledger.Account, error) {
+	return &ledger.Account{ID: "account-" + string(accountType), Type: accountType, Currency: currency}, nil
+}
+
+func (memoryLedger) Post(_ context.Context, journal *ledger.Journal) error {
+	if err := journal.Validate(); err != nil {
+		return err
+	}
+
+	journal.ID = "journal"
+
+	return nil
+}
+
+// newTenantTestRouter serves the wallet routes of the public API for the tenants brand-a and brand-b. The returned
+// repository holds one EUR wallet of brand-a with a balance of 100 and a deposit transaction.
+func newTenantTestRouter(t *testing.T) (http.Handler, *memoryWalletRepository) {
+	t.Helper()
+
+	tenants, err := tenant.NewRegistry(&tenant.Tenant{ID: "brand-a"}, &tenant.Tenant{ID: "brand-b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &memoryWalletRepository{
+		wallets: map[string]*wallet.Wallet{
+			"wallet-a": {ID: "wallet-a", TenantID: "brand-a", Balance: 100, Currency: "EUR", Status: wallet.StatusActive,
+				Version: 1},
+		},
+		transactions: map[string]*wallet.Transaction{
+			"transaction-a": {ID: "transaction-a", TenantID: "brand-a", WalletID: "wallet-a",
+				Type: wallet.TransactionDeposit, Direction: wallet.DirectionCredit, Amount: 100, Currency: "EUR"},
+		},
+	}
+
+	walletService := wallet.NewService(repo, memoryLedger{})
+	tenancyCfg := config.Tenancy{Header: "X-Tenant-ID", APIKeyHeader: "X-API-Key", UserHeader: "X-User-ID"}
+
+	mux := chi.NewMux()
+	mux.Route("/v1", func(r chi.Router) {
+		r.Use(
+			ResolveTenant(nil, tenants, tenancyCfg),
+			IdentifyClient(tenancyCfg.APIKeyHeader, tenancyCfg.UserHeader),
+		)
+
+		r.Get("/wallets/{id}", httpv1.NewGetWalletHandler(walletService, nil))
+		r.With(IfMatch).Post("/wallets/{id}/deposit", httpv1.NewDepositHandler(walletService, nil))
+		r.With(IfMatch).Post("/wallets/{id}/withdraw", httpv1.NewWithdrawHandler(walletService, nil))
+		r.Get("/wallets/{id}/statement", httpv1.NewStatementHandler(walletService, nil))
+		r.Get("/transactions/{id}", httpv1.NewGetTransactionHandler(walletService, nil))
+	})
+
+	return mux, repo
+}
+
+var tenantTestRequests = []struct {
+	name   string
+	method string
+	path   string
+	body   string
+	code   string
+	status int
+}{
+	{"get wallet", http.MethodGet, "/v1/wallets/wallet-a", "", "wallet_not_found", http.StatusOK},
+	{"deposit", http.MethodPost, "/v1/wallets/wallet-a/deposit", `{"balance": 10}`, "wallet_not_found",
+		http.StatusNoContent},
+	{"withdraw", http.MethodPost, "/v1/wallets/wallet-a/withdraw", `{"balance": 10}`, "wallet_not_found",
+		http.StatusNoContent},
+	{"list movements", http.MethodGet, "/v1/wallets/wallet-a/statement?from=2025-01-01&to=2025-01-31",
+		"", "wallet_not_found", http.StatusOK},
+	{"get transaction", http.MethodGet, "/v1/transactions/transaction-a", "", "transaction_not_found",
+		http.StatusOK},
+}
+
+func serveTenantRequest(router http.Handler, tenantID string, method string, path string, body string) *http.Response {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("X-Tenant-ID", tenantID)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder.Result()
+}
+
+func TestResolveTenant_OtherTenantGetsNotFound(t *testing.T) {
+	for _, tc := range tenantTestRequests {
+		t.Run(tc.name, func(t *testing.T) {
+			router, repo := newTenantTestRouter(t)
+
+			response := serveTenantRequest(router, "brand-b", tc.method, tc.path, tc.body)
+			defer response.Body.Close()
+
+			if response.StatusCode != http.StatusNotFound {
+				t.Fatalf("status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+
+			var problem httpv1.Problem
+			if err := json.NewDecoder(response.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+
+			if problem.Code != tc.code {
+				t.Errorf("code = %q, want %q", problem.Code, tc.code)
+			}
+
+			if balance := repo.wallets["wallet-a"].Balance; balance != 100 {
+				t.Errorf("balance = %v, want 100", balance)
+			}
+		})
+	}
+}
+
+func TestResolveTenant_OwnTenantSucceeds(t *testing.T) {
+	for _, tc := range tenantTestRequests {
+		t.Run(tc.name, func(t *testing.T) {
+			router, _ := newTenantTestRouter(t)
+
+			response := serveTenantRequest(router, "brand-a", tc.method, tc.path, tc.body)
+			defer response.Body.Close()
+
+			if response.StatusCode != tc.status {
+				t.Fatalf("status = %d, want %d", response.StatusCode, tc.status)
+			}
+
+			// Movements return the version of the wallet they changed, one past the version it started at.
+			if etag := response.Header.Get("ETag"); tc.method == http.MethodPost && etag != `"2"` {
+				t.Errorf("ETag = %s, want \"2\"", etag)
+			}
+		})
+	}
+}
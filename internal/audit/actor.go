@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// ActorType tells how the actor of an audited action was identified.
+type ActorType string
+
+const (
+	// ActorAPIKey is a client authenticated with a tenant API key. The actor ID is the key fingerprint,
+	// never the key itself.
+	ActorAPIKey ActorType = "api_key"
+	// ActorUser is an end user identified by the gateway in front of the public API.
+	ActorUser ActorType = "user"
+	// ActorOperator is an operator calling the admin endpoints.
+	ActorOperator ActorType = "operator"
+	// ActorAnonymous is a client of the public API of a tenant without API keys.
+	ActorAnonymous ActorType = "anonymous"
+	// ActorSystem is the service itself, e.g. a scheduled task.
+	ActorSystem ActorType = "system"
+)
+
+// MaxActorIDLength is the length of the actor ID column, and of the columns naming operators in other tables.
+const MaxActorIDLength = 128
+
+// Actor is who performed an audited action, and from where.
+type Actor struct {
+	Type ActorType `json:"type"`
+	ID   string    `json:"id,omitempty"`
+	IP   string    `json:"ip,omitempty"`
+}
+
+type actorContextKey struct{}
+
+// NewContext returns a copy of ctx carrying the actor.
+func NewContext(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in ctx. Actions without an actor are attributed to the system.
+func ActorFromContext(ctx context.Context) Actor {
+	actor, ok := ctx.Value(actorContextKey{}).(Actor)
+	if !ok {
+		return Actor{Type: ActorSystem}
+	}
+
+	return actor
+}
+
+// APIKeyFingerprint identifies an API key in the audit log without disclosing it.
+func APIKeyFingerprint(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+
+	return hex.EncodeToString(sum[:8])
+}
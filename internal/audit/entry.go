@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GenesisHash is the previous hash of the first entry of every tenant's chain.
+var GenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// Entry is an audit log entry. Entries of a tenant form a hash chain: each entry's Hash covers its content
+// and the Hash of the entry before it, so editing or deleting an entry breaks every following link.
+type Entry struct {
+	TenantID     string          `json:"tenant_id"`
+	Sequence     int64           `json:"sequence"`
+	OccurredAt   time.Time       `json:"occurred_at"`
+	Actor        Actor           `json:"actor"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	Before       json.RawMessage `json:"before,omitempty"`
+	After        json.RawMessage `json:"after,omitempty"`
+	PreviousHash string          `json:"previous_hash"`
+	Hash         string          `json:"hash"`
+}
+
+// ComputeHash returns the hash of the entry content chained to PreviousHash.
+// Fields are hashed in a fixed order, separated by newlines, with the time in UTC at microsecond precision
+// as stored by the database.
+func (e *Entry) ComputeHash() string {
+	fields := []string{
+		e.PreviousHash,
+		e.TenantID,
+		strconv.FormatInt(e.Sequence, 10),
+		e.OccurredAt.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		string(e.Actor.Type),
+		e.Actor.ID,
+		e.Actor.IP,
+		e.Action,
+		e.ResourceType,
+		e.ResourceID,
+		string(e.Before),
+		string(e.After),
+	}
+
+	sum := sha256.New()
+	for _, field := range fields {
+		// The length prefix keeps fields containing newlines from shifting content between fields.
+		sum.Write([]byte(strconv.Itoa(len(field)) + ":" + field + "\n"))
+	}
+
+	return hex.EncodeToString(sum.Sum(nil))
+}
@@ -0,0 +1,378 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/database"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
+)
+
+const (
+	// DefaultListLimit is the number of entries List returns when Filter.Limit is not set.
+	DefaultListLimit = 100
+	// MaxListLimit is the maximum number of entries List returns.
+	MaxListLimit = 1000
+
+	entryColumns = `tenant_id, sequence, occurred_at, actor_type, actor_id, actor_ip, action, resource_type,
+              resource_id, before_state, after_state, previous_hash, hash`
+)
+
+// Filter narrows down the entries returned by List. Empty fields match every entry.
+type Filter struct {
+	ResourceType  string
+	ResourceID    string
+	Action        string
+	ActorID       string
+	From          time.Time
+	To            time.Time
+	AfterSequence int64
+	Limit         int
+}
+
+// Verification is the outcome of verifying the hash chain of a tenant.
+type Verification struct {
+	TenantID string `json:"tenant_id"`
+	Entries  int64  `json:"entries"`
+	Valid    bool   `json:"valid"`
+	// BrokenAt is the sequence of the first entry failing verification.
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Problem  string `json:"problem,omitempty"`
+}
+
+// Recorder appends entries to the audit log.
+type Recorder interface {
+	// Record appends an entry for the action on the resource, attributed to the actor of ctx. It runs in the
+	// database transaction of ctx, so the entry is only kept when the audited mutation is committed.
+	Record(ctx context.Context, action string, resourceType string, resourceID string, before, after interface{}) error
+}
+
+// NopRecorder discards the entries, for services running without an audit log.
+type NopRecorder struct{}
+
+func (NopRecorder) Record(context.Context, string, string, string, interface{}, interface{}) error {
+	return nil
+}
+
+// Log is the append-only audit log.
+type Log interface {
+	Recorder
+	// List returns the entries of the tenant of ctx in chain order.
+	List(ctx context.Context, filter Filter) ([]*Entry, error)
+	// Verify recomputes the hash chain of the tenant.
+	Verify(ctx context.Context, tenantID string) (*Verification, error)
+	// Tenants returns the tenants having audit entries.
+	Tenants(ctx context.Context) ([]string, error)
+}
+
+type auditLog struct {
+	db *sql.DB
+}
+
+func NewLog(db *sql.DB) Log {
+	return &auditLog{db: db}
+}
+
+func (l *auditLog) Record(
+	ctx context.Context,
+	action string,
+	resourceType string,
+	resourceID string,
+	before, after interface{},
+) error {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	beforeState, err := marshalState(before)
+	if err != nil {
+		return err
+	}
+
+	afterState, err := marshalState(after)
+	if err != nil {
+		return err
+	}
+
+	entry := &Entry{
+		TenantID:     t.ID,
+		OccurredAt:   time.Now().UTC().Truncate(time.Microsecond),
+		Actor:        ActorFromContext(ctx),
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Before:       beforeState,
+		After:        afterState,
+	}
+
+	return database.RunInTx(ctx, l.db, func(ctx context.Context) error {
+		return l.append(ctx, entry)
+	})
+}
+
+// append links the entry to the last entry of the tenant. The last entry is read with an update lock held
+// until the transaction ends, so concurrent writers of a tenant append one after the other.
+func (l *auditLog) append(ctx context.Context, entry *Entry) (err error) {
+	query := `SELECT TOP 1 sequence, hash FROM audit_log WITH (UPDLOCK, HOLDLOCK)
+              WHERE tenant_id = @tenant_id ORDER BY sequence DESC`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "audit.Log/Record", query)
+	defer func() { tracing.End(span, err) }()
+
+	err = database.Conn(ctx, l.db).QueryRowContext(ctx, query,
+		sql.Named("tenant_id", entry.TenantID),
+	).Scan(&entry.Sequence, &entry.PreviousHash)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		entry.Sequence = 0
+		entry.PreviousHash = GenesisHash
+	case err != nil:
+		return errors.New("failed to read last audit entry: " + err.Error())
+	}
+
+	entry.Sequence++
+	entry.Hash = entry.ComputeHash()
+
+	insert := `INSERT INTO audit_log (` + entryColumns + `)
+               VALUES (@tenant_id, @sequence, @occurred_at, @actor_type, @actor_id, @actor_ip, @action,
+                   @resource_type, @resource_id, @before_state, @after_state, @previous_hash, @hash)`
+
+	_, err = database.Conn(ctx, l.db).ExecContext(ctx, insert,
+		sql.Named("tenant_id", entry.TenantID),
+		sql.Named("sequence", entry.Sequence),
+		sql.Named("occurred_at", entry.OccurredAt),
+		sql.Named("actor_type", string(entry.Actor.Type)),
+		sql.Named("actor_id", entry.Actor.ID),
+		sql.Named("actor_ip", entry.Actor.IP),
+		sql.Named("action", entry.Action),
+		sql.Named("resource_type", entry.ResourceType),
+		sql.Named("resource_id", entry.ResourceID),
+		sql.Named("before_state", nullString(string(entry.Before))),
+		sql.Named("after_state", nullString(string(entry.After))),
+		sql.Named("previous_hash", entry.PreviousHash),
+		sql.Named("hash", entry.Hash),
+	)
+	if err != nil {
+		return errors.New("failed to insert audit entry: " + err.Error())
+	}
+
+	return nil
+}
+
+func (l *auditLog) List(ctx context.Context, filter Filter) (_ []*Entry, err error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+
+	conditions := []string{"tenant_id = @tenant_id", "sequence > @after_sequence"}
+	args := []interface{}{
+		sql.Named("tenant_id", t.ID),
+		sql.Named("after_sequence", filter.AfterSequence),
+		sql.Named("limit", limit),
+	}
+
+	for _, condition := range []struct {
+		column string
+		value  string
+	}{
+		{"resource_type", filter.ResourceType},
+		{"resource_id", filter.ResourceID},
+		{"action", filter.Action},
+		{"actor_id", filter.ActorID},
+	} {
+		if condition.value != "" {
+			conditions = append(conditions, condition.column+" = @"+condition.column)
+			args = append(args, sql.Named(condition.column, condition.value))
+		}
+	}
+
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "occurred_at >= @from")
+		args = append(args, sql.Named("from", filter.From.UTC()))
+	}
+
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "occurred_at < @to")
+		args = append(args, sql.Named("to", filter.To.UTC()))
+	}
+
+	query := `SELECT TOP (@limit) ` + entryColumns + `
+              FROM audit_log WHERE ` + strings.Join(conditions, " AND ") + `
+              ORDER BY sequence`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "audit.Log/List", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, l.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.New("failed to list audit entries: " + err.Error())
+	}
+	defer rows.Close()
+
+	entries := make([]*Entry, 0)
+
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list audit entries: " + err.Error())
+	}
+
+	return entries, nil
+}
+
+func (l *auditLog) Verify(ctx context.Context, tenantID string) (_ *Verification, err error) {
+	query := `SELECT ` + entryColumns + `
+              FROM audit_log WHERE tenant_id = @tenant_id ORDER BY sequence`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "audit.Log/Verify", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, l.db).QueryContext(ctx, query, sql.Named("tenant_id", tenantID))
+	if err != nil {
+		return nil, errors.New("failed to read audit entries: " + err.Error())
+	}
+	defer rows.Close()
+
+	verification := &Verification{TenantID: tenantID, Valid: true}
+	previous := &Entry{Hash: GenesisHash}
+
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		verification.Entries++
+
+		if verification.Valid {
+			if problem := verifyLink(previous, entry); problem != "" {
+				verification.Valid = false
+				verification.BrokenAt = entry.Sequence
+				verification.Problem = problem
+			}
+		}
+
+		previous = entry
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to read audit entries: " + err.Error())
+	}
+
+	return verification, nil
+}
+
+// verifyLink returns why entry does not follow previous in the chain, or an empty string.
+func verifyLink(previous *Entry, entry *Entry) string {
+	switch {
+	case entry.Sequence != previous.Sequence+1:
+		return "sequence gap: an entry was deleted or inserted"
+	case entry.PreviousHash != previous.Hash:
+		return "previous hash does not match the hash of the previous entry"
+	case entry.Hash != entry.ComputeHash():
+		return "hash does not match the entry content: the entry was modified"
+	default:
+		return ""
+	}
+}
+
+func (l *auditLog) Tenants(ctx context.Context) (_ []string, err error) {
+	query := `SELECT DISTINCT tenant_id FROM audit_log ORDER BY tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "audit.Log/Tenants", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, l.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.New("failed to list audited tenants: " + err.Error())
+	}
+	defer rows.Close()
+
+	tenants := make([]string, 0)
+
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, errors.New("failed to scan audited tenant: " + err.Error())
+		}
+
+		tenants = append(tenants, tenantID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list audited tenants: " + err.Error())
+	}
+
+	return tenants, nil
+}
+
+func scanEntry(rows *sql.Rows) (*Entry, error) {
+	var (
+		entry       Entry
+		actorID     sql.NullString
+		actorIP     sql.NullString
+		beforeState sql.NullString
+		afterState  sql.NullString
+	)
+
+	err := rows.Scan(&entry.TenantID, &entry.Sequence, &entry.OccurredAt, &entry.Actor.Type, &actorID, &actorIP,
+		&entry.Action, &entry.ResourceType, &entry.ResourceID, &beforeState, &afterState, &entry.PreviousHash,
+		&entry.Hash)
+	if err != nil {
+		return nil, errors.New("failed to scan audit entry: " + err.Error())
+	}
+
+	entry.Actor.ID = actorID.String
+	entry.Actor.IP = actorIP.String
+
+	if beforeState.Valid {
+		entry.Before = json.RawMessage(beforeState.String)
+	}
+
+	if afterState.Valid {
+		entry.After = json.RawMessage(afterState.String)
+	}
+
+	return &entry, nil
+}
+
+func marshalState(state interface{}) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return nil, errors.New("failed to marshal audited state: " + err.Error())
+	}
+
+	return raw, nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,111 @@
+// Package batch runs lists of deposits and withdrawals submitted at once, e.g. payouts to thousands of wallets.
+// Batches are queued in the database and processed asynchronously by a Processor, with a status per operation.
+package batch
+
+import (
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+)
+
+// OperationType is the wallet operation of a batch item.
+type OperationType string
+
+const (
+	OperationDeposit  OperationType = "deposit"
+	OperationWithdraw OperationType = "withdraw"
+)
+
+// Status is the processing state of a batch.
+type Status string
+
+const (
+	// StatusPending batches wait for a Processor.
+	StatusPending Status = "pending"
+	// StatusProcessing batches are being processed.
+	StatusProcessing Status = "processing"
+	// StatusCompleted batches ran every operation, some of which may have failed unless the batch is atomic.
+	StatusCompleted Status = "completed"
+	// StatusFailed batches are atomic batches rolled back because an operation failed, or batches given up after
+	// their last attempt failed for another reason than an operation, e.g. the database being unavailable.
+	StatusFailed Status = "failed"
+)
+
+// Finished tells whether the batch will not change anymore.
+func (s Status) Finished() bool {
+	return s == StatusCompleted || s == StatusFailed
+}
+
+// ItemStatus is the outcome of an operation of a batch.
+type ItemStatus string
+
+const (
+	ItemPending   ItemStatus = "pending"
+	ItemSucceeded ItemStatus = "succeeded"
+	ItemFailed    ItemStatus = "failed"
+	// ItemRolledBack operations were undone, or not run, because another operation of their atomic batch failed.
+	ItemRolledBack ItemStatus = "rolled_back"
+)
+
+// Operation is a deposit or withdrawal submitted in a batch.
+type Operation struct {
+	Type     OperationType `json:"type"`
+	WalletID string        `json:"wallet_id"`
+	Amount   float64       `json:"amount"`
+	// Reference is the payment reference of deposits.
+	Reference string `json:"reference,omitempty"`
+}
+
+// Progress counts the items of a batch by status.
+type Progress struct {
+	Pending    int `json:"pending"`
+	Succeeded  int `json:"succeeded"`
+	Failed     int `json:"failed"`
+	RolledBack int `json:"rolled_back"`
+}
+
+func (p *Progress) add(status ItemStatus, count int) {
+	switch status {
+	case ItemPending:
+		p.Pending += count
+	case ItemSucceeded:
+		p.Succeeded += count
+	case ItemFailed:
+		p.Failed += count
+	case ItemRolledBack:
+		p.RolledBack += count
+	}
+}
+
+type Batch struct {
+	ID       string `json:"id" db:"id"`
+	TenantID string `json:"tenant_id" db:"tenant_id"`
+	Status   Status `json:"status" db:"status"`
+	// Atomic batches apply all their operations or none of them.
+	Atomic     bool `json:"atomic" db:"atomic"`
+	Operations int  `json:"operations" db:"operations"`
+	// Attempts counts the runs of the batch by a Processor, including the current one.
+	Attempts int      `json:"attempts" db:"attempts"`
+	Progress Progress `json:"progress"`
+	// Error tells why the batch failed.
+	Error       string      `json:"error,omitempty" db:"error"`
+	SubmittedBy audit.Actor `json:"submitted_by"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+	StartedAt   *time.Time  `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt  *time.Time  `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// Item is an operation of a batch with its outcome.
+type Item struct {
+	BatchID string `json:"batch_id" db:"batch_id"`
+	// Line is the position of the operation in the batch, starting at 1.
+	Line          int           `json:"line" db:"line"`
+	Type          OperationType `json:"type" db:"type"`
+	WalletID      string        `json:"wallet_id" db:"wallet_id"`
+	Amount        float64       `json:"amount" db:"amount"`
+	Reference     string        `json:"reference,omitempty" db:"reference"`
+	Status        ItemStatus    `json:"status" db:"status"`
+	TransactionID string        `json:"transaction_id,omitempty" db:"transaction_id"`
+	Error         string        `json:"error,omitempty" db:"error"`
+	ProcessedAt   *time.Time    `json:"processed_at,omitempty" db:"processed_at"`
+}
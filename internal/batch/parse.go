@@ -0,0 +1,89 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidFile = errors.New("invalid batch file")
+
+// ParseError tells which row of a CSV batch file is invalid, the header being row 1.
+type ParseError struct {
+	Row     int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return "row " + strconv.Itoa(e.Row) + ": " + e.Message
+}
+
+func (e *ParseError) Unwrap() error {
+	return ErrInvalidFile
+}
+
+// csvColumns are the columns of CSV batch files. The header names the columns, in any order.
+var csvColumns = struct {
+	operationType, walletID, amount, reference string
+}{"type", "wallet_id", "amount", "reference"}
+
+// ParseCSV reads the operations of a CSV batch file with a header. The operations are validated on submission.
+func ParseCSV(content []byte) ([]Operation, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, &ParseError{Row: 1, Message: "missing header"}
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, required := range []string{csvColumns.operationType, csvColumns.walletID, csvColumns.amount} {
+		if _, ok := columns[required]; !ok {
+			return nil, &ParseError{Row: 1, Message: "missing column " + required}
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	operations := make([]Operation, 0)
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, &ParseError{Row: row, Message: err.Error()}
+		}
+
+		amount, err := strconv.ParseFloat(field(record, csvColumns.amount), 64)
+		if err != nil {
+			return nil, &ParseError{Row: row, Message: "invalid amount"}
+		}
+
+		operations = append(operations, Operation{
+			Type:      OperationType(strings.ToLower(field(record, csvColumns.operationType))),
+			WalletID:  field(record, csvColumns.walletID),
+			Amount:    amount,
+			Reference: field(record, csvColumns.reference),
+		})
+	}
+
+	return operations, nil
+}
@@ -0,0 +1,349 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+const (
+	// DefaultLease is how long a batch may go without heartbeat before another processor takes it over.
+	DefaultLease = time.Minute
+	// DefaultMaxAttempts is how many times a batch is run before it fails.
+	DefaultMaxAttempts = 5
+)
+
+// ErrRateLimited fails the withdrawals over the rate limit of their wallet.
+var ErrRateLimited = errors.New("withdrawal rate limit exceeded")
+
+// operationErrors are the errors failing an operation. Other errors abort the run, the batch is resumed
+// once its lease expires until it runs out of attempts.
+var operationErrors = []error{
+	wallet.ErrWalletNotFound,
+	wallet.ErrInvalidAmount,
+	wallet.ErrInsufficientFunds,
+	wallet.ErrCurrencyNotAllowed,
+	wallet.ErrLimitExceeded,
+	wallet.ErrWalletFrozen,
+	ErrRateLimited,
+}
+
+// WithdrawLimiter rate limits the withdrawals per wallet, sharing the quota of the withdraw API route.
+type WithdrawLimiter interface {
+	// Allow counts a withdrawal from the wallet and tells whether it is within the limit.
+	Allow(ctx context.Context, walletID string) (bool, error)
+}
+
+// Processor runs the queued batches of every tenant through the wallet service. Each operation is recorded with
+// its outcome in the database transaction of the operation, so an operation runs exactly once even when several
+// processors run or one dies while processing a batch.
+type Processor struct {
+	repo    Repository
+	wallets wallet.Service
+	tenants *tenant.Registry
+	lease   time.Duration
+
+	maxAttempts     int
+	withdrawLimiter WithdrawLimiter
+}
+
+type ProcessorOption func(*Processor)
+
+// WithLease sets how long a batch may go without heartbeat before it is taken over, DefaultLease otherwise.
+func WithLease(lease time.Duration) ProcessorOption {
+	return func(p *Processor) {
+		p.lease = lease
+	}
+}
+
+// WithMaxAttempts sets how many times a batch is run before it fails, DefaultMaxAttempts otherwise.
+// A run is an attempt when it is interrupted by another error than a failed operation, or when its processor dies.
+func WithMaxAttempts(maxAttempts int) ProcessorOption {
+	return func(p *Processor) {
+		p.maxAttempts = maxAttempts
+	}
+}
+
+// WithWithdrawLimiter fails the withdrawals the limiter rejects with ErrRateLimited. When the limiter fails,
+// the withdrawal is let through like the requests to the API.
+func WithWithdrawLimiter(limiter WithdrawLimiter) ProcessorOption {
+	return func(p *Processor) {
+		p.withdrawLimiter = limiter
+	}
+}
+
+// NewProcessor creates a Processor running the operations with the limits of the tenants of the registry.
+func NewProcessor(
+	repo Repository,
+	wallets wallet.Service,
+	tenants *tenant.Registry,
+	options ...ProcessorOption,
+) *Processor {
+	p := &Processor{
+		repo:        repo,
+		wallets:     wallets,
+		tenants:     tenants,
+		lease:       DefaultLease,
+		maxAttempts: DefaultMaxAttempts,
+	}
+
+	for _, opt := range options {
+		opt(p)
+	}
+
+	return p
+}
+
+// ProcessNext claims the oldest pending batch and runs it. It returns false when no batch is waiting.
+func (p *Processor) ProcessNext(ctx context.Context) (bool, error) {
+	batch, err := p.repo.Claim(ctx, p.lease)
+	if err != nil || batch == nil {
+		return false, err
+	}
+
+	log := logging.FromContext(ctx, nil).With(
+		zap.String("batch_id", batch.ID),
+		zap.String("tenant_id", batch.TenantID),
+	)
+
+	ctx = logging.NewContext(ctx, log)
+	ctx = audit.NewContext(ctx, batch.SubmittedBy)
+
+	batchTenant, err := p.tenants.ByID(batch.TenantID)
+	if err != nil {
+		// The tenant only scopes the batch queries, it was removed from the configuration.
+		ctx = tenant.NewContext(ctx, &tenant.Tenant{ID: batch.TenantID})
+
+		return true, p.finish(ctx, batch, StatusFailed, ItemFailed, "unknown tenant "+batch.TenantID)
+	}
+
+	ctx = tenant.NewContext(ctx, batchTenant)
+
+	if batch.Attempts > p.maxAttempts {
+		// The processor running the last attempt died before recording its failure.
+		return true, p.giveUp(ctx, batch, "gave up after "+strconv.Itoa(p.maxAttempts)+" attempts")
+	}
+
+	if batch.Atomic {
+		err = p.runAtomic(ctx, batch)
+	} else {
+		err = p.runEach(ctx, batch)
+	}
+
+	if err != nil {
+		if batch.Attempts < p.maxAttempts || ctx.Err() != nil {
+			return true, err
+		}
+
+		if err := p.giveUp(ctx, batch, err.Error()); err != nil {
+			return true, err
+		}
+
+		log.Warn("Batch failed after its last attempt", logger.ErrorField(err))
+
+		return true, nil
+	}
+
+	finished, err := p.repo.Get(ctx, batch.ID)
+	if err != nil {
+		return true, err
+	}
+
+	log.Info(
+		"Batch processed",
+		zap.String("status", string(finished.Status)),
+		zap.Int("succeeded", finished.Progress.Succeeded),
+		zap.Int("failed", finished.Progress.Failed),
+		zap.Int("rolled_back", finished.Progress.RolledBack),
+	)
+
+	return true, nil
+}
+
+// runEach runs every pending operation in its own database transaction. Failed operations do not stop the batch.
+func (p *Processor) runEach(ctx context.Context, batch *Batch) error {
+	items, err := p.repo.ListItems(ctx, batch.ID, ItemPending)
+	if err != nil {
+		return err
+	}
+
+	heartbeat := p.heartbeat(ctx, batch.ID)
+
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := heartbeat(); err != nil {
+			return err
+		}
+
+		err := p.repo.WithinTx(ctx, func(ctx context.Context) error {
+			transaction, err := p.run(ctx, item)
+			if err != nil {
+				return err
+			}
+
+			return p.repo.CompleteItem(ctx, item.BatchID, item.Line, transaction.ID)
+		})
+
+		switch {
+		case err == nil, errors.Is(err, ErrItemProcessed):
+		case isOperationError(err):
+			if err := p.repo.FailItem(ctx, item.BatchID, item.Line, err.Error()); err != nil &&
+				!errors.Is(err, ErrItemProcessed) {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+
+	return p.repo.Finish(ctx, batch.ID, StatusCompleted, "")
+}
+
+// runAtomic runs every operation in a single database transaction, rolled back as soon as an operation fails.
+// The wallets of the batch stay locked until the batch ends.
+func (p *Processor) runAtomic(ctx context.Context, batch *Batch) error {
+	items, err := p.repo.ListItems(ctx, batch.ID, ItemPending)
+	if err != nil {
+		return err
+	}
+
+	heartbeat := p.heartbeat(ctx, batch.ID)
+
+	var failed *Item
+
+	err = p.repo.WithinTx(ctx, func(ctx context.Context) error {
+		for _, item := range items {
+			if err := heartbeat(); err != nil {
+				return err
+			}
+
+			transaction, err := p.run(ctx, item)
+			if err != nil {
+				if isOperationError(err) {
+					failed = item
+				}
+
+				return err
+			}
+
+			if err := p.repo.CompleteItem(ctx, item.BatchID, item.Line, transaction.ID); err != nil {
+				return err
+			}
+		}
+
+		return p.repo.Finish(ctx, batch.ID, StatusCompleted, "")
+	})
+
+	if err == nil || failed == nil {
+		return err
+	}
+
+	failure := err.Error()
+
+	return p.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := p.repo.FailItem(ctx, failed.BatchID, failed.Line, failure); err != nil {
+			return err
+		}
+
+		return p.finish(ctx, batch, StatusFailed, ItemRolledBack, "line "+strconv.Itoa(failed.Line)+": "+failure)
+	})
+}
+
+// finish closes the pending items of the batch with itemStatus and ends the batch with status.
+func (p *Processor) finish(
+	ctx context.Context,
+	batch *Batch,
+	status Status,
+	itemStatus ItemStatus,
+	message string,
+) error {
+	return p.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := p.repo.CloseItems(ctx, batch.ID, itemStatus); err != nil {
+			return err
+		}
+
+		return p.repo.Finish(ctx, batch.ID, status, message)
+	})
+}
+
+// giveUp fails the batch whose attempts failed for another reason than an operation. The pending operations of
+// an atomic batch were rolled back, those of other batches are not run.
+func (p *Processor) giveUp(ctx context.Context, batch *Batch, message string) error {
+	itemStatus := ItemFailed
+	if batch.Atomic {
+		itemStatus = ItemRolledBack
+	}
+
+	return p.finish(ctx, batch, StatusFailed, itemStatus, message)
+}
+
+func (p *Processor) run(ctx context.Context, item *Item) (*wallet.Transaction, error) {
+	switch item.Type {
+	case OperationDeposit:
+		return p.wallets.Deposit(ctx, item.WalletID, item.Amount, item.Reference)
+	case OperationWithdraw:
+		if !p.allowWithdraw(ctx, item.WalletID) {
+			return nil, ErrRateLimited
+		}
+
+		return p.wallets.Withdraw(ctx, item.WalletID, item.Amount)
+	}
+
+	return nil, ErrInvalidOperation
+}
+
+func (p *Processor) allowWithdraw(ctx context.Context, walletID string) bool {
+	if p.withdrawLimiter == nil {
+		return true
+	}
+
+	allowed, err := p.withdrawLimiter.Allow(ctx, walletID)
+	if err != nil {
+		logging.FromContext(ctx, nil).Error(
+			"Failed to apply withdrawal rate limit",
+			zap.String("wallet_id", walletID),
+			logger.ErrorField(err),
+		)
+
+		return true
+	}
+
+	return allowed
+}
+
+// heartbeat returns a function renewing the lease of the batch when a third of it has elapsed.
+func (p *Processor) heartbeat(ctx context.Context, batchID string) func() error {
+	last := time.Now()
+
+	return func() error {
+		if time.Since(last) < p.lease/3 {
+			return nil
+		}
+
+		last = time.Now()
+
+		return p.repo.Heartbeat(ctx, batchID)
+	}
+}
+
+func isOperationError(err error) bool {
+	for _, operationErr := range operationErrors {
+		if errors.Is(err, operationErr) {
+			return true
+		}
+	}
+
+	return errors.Is(err, ErrInvalidOperation)
+}
@@ -0,0 +1,48 @@
+package batch
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/spreadsheet"
+)
+
+// WriteItemsCSV writes the result file of a batch, one line per operation in batch order.
+func WriteItemsCSV(w io.Writer, items []*Item) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{
+		"line", "type", "wallet_id", "amount", "reference", "status", "transaction_id", "error", "processed_at",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		processedAt := ""
+		if item.ProcessedAt != nil {
+			processedAt = item.ProcessedAt.UTC().Format(time.RFC3339)
+		}
+
+		err := writer.Write([]string{
+			strconv.Itoa(item.Line),
+			string(item.Type),
+			spreadsheet.Cell(item.WalletID),
+			strconv.FormatFloat(item.Amount, 'f', 2, 64),
+			spreadsheet.Cell(item.Reference),
+			string(item.Status),
+			item.TransactionID,
+			spreadsheet.Cell(item.Error),
+			processedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,493 @@
+package batch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/database"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+var ErrItemProcessed = errors.New("batch item already processed")
+
+const batchColumns = `id, tenant_id, status, atomic, operations, attempts, error, actor_type, actor_id, actor_ip,
+                  created_at, started_at, finished_at`
+
+// batchOutputColumns are the batchColumns of an OUTPUT clause.
+const batchOutputColumns = `inserted.id, inserted.tenant_id, inserted.status, inserted.atomic, inserted.operations,
+                  inserted.attempts, inserted.error, inserted.actor_type, inserted.actor_id, inserted.actor_ip,
+                  inserted.created_at, inserted.started_at, inserted.finished_at`
+
+const itemColumns = `batch_id, line, type, wallet_id, amount, reference, status, transaction_id, error, processed_at`
+
+// itemsPerInsert keeps the parameters of a multi-row insert below the limit of 2100 of SQL Server.
+const itemsPerInsert = 400
+
+type Repository interface {
+	// WithinTx runs fn in a database transaction, see database.RunInTx.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// Create records the pending batch with an item per operation.
+	Create(ctx context.Context, batch *Batch, operations []Operation) error
+	Get(ctx context.Context, id string) (*Batch, error)
+	// ListItems returns the items of the batch in batch order, only those with status unless it is empty.
+	ListItems(ctx context.Context, batchID string, status ItemStatus) ([]*Item, error)
+	// Claim marks the oldest pending batch of any tenant as processing and returns it, nil when there is none.
+	// Processing batches without heartbeat for lease are claimed again, their processor is assumed dead.
+	// Every claim counts as an attempt of the batch.
+	Claim(ctx context.Context, lease time.Duration) (*Batch, error)
+	// Heartbeat tells that the batch is still being processed. It runs outside of the transaction of ctx,
+	// so atomic batches keep their lease while their transaction is open.
+	Heartbeat(ctx context.Context, id string) error
+	// CompleteItem records the transaction of a succeeded item. It returns ErrItemProcessed when the item is
+	// not pending anymore, so the operation must be rolled back.
+	CompleteItem(ctx context.Context, batchID string, line int, transactionID string) error
+	FailItem(ctx context.Context, batchID string, line int, message string) error
+	// CloseItems sets the status of the pending items of the batch.
+	CloseItems(ctx context.Context, batchID string, status ItemStatus) error
+	Finish(ctx context.Context, id string, status Status, message string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.RunInTx(ctx, r.db, fn)
+}
+
+// currentTenantID returns the tenant every query must be scoped to.
+func currentTenantID(ctx context.Context) (string, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return t.ID, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBatch(row rowScanner) (*Batch, error) {
+	var (
+		batch      Batch
+		message    sql.NullString
+		actorID    sql.NullString
+		actorIP    sql.NullString
+		startedAt  sql.NullTime
+		finishedAt sql.NullTime
+	)
+
+	err := row.Scan(&batch.ID, &batch.TenantID, &batch.Status, &batch.Atomic, &batch.Operations, &batch.Attempts,
+		&message, &batch.SubmittedBy.Type, &actorID, &actorIP, &batch.CreatedAt, &startedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	batch.Error = message.String
+	batch.SubmittedBy.ID = actorID.String
+	batch.SubmittedBy.IP = actorIP.String
+
+	if startedAt.Valid {
+		batch.StartedAt = &startedAt.Time
+	}
+
+	if finishedAt.Valid {
+		batch.FinishedAt = &finishedAt.Time
+	}
+
+	return &batch, nil
+}
+
+func scanItem(row rowScanner) (*Item, error) {
+	var (
+		item          Item
+		reference     sql.NullString
+		transactionID sql.NullString
+		message       sql.NullString
+		processedAt   sql.NullTime
+	)
+
+	err := row.Scan(&item.BatchID, &item.Line, &item.Type, &item.WalletID, &item.Amount, &reference, &item.Status,
+		&transactionID, &message, &processedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Reference = reference.String
+	item.TransactionID = transactionID.String
+	item.Error = message.String
+
+	if processedAt.Valid {
+		item.ProcessedAt = &processedAt.Time
+	}
+
+	return &item, nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func (r *repository) Create(ctx context.Context, batch *Batch, operations []Operation) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	batch.ID = uuid.New().String()
+	batch.TenantID = tenantID
+
+	query := `INSERT INTO batches (id, tenant_id, status, atomic, operations, actor_type, actor_id, actor_ip,
+                  created_at)
+              VALUES (@id, @tenant_id, @status, @atomic, @operations, @actor_type, @actor_id, @actor_ip, @created_at)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "batch.Repository/Create", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("id", batch.ID),
+		sql.Named("tenant_id", batch.TenantID),
+		sql.Named("status", string(batch.Status)),
+		sql.Named("atomic", batch.Atomic),
+		sql.Named("operations", batch.Operations),
+		sql.Named("actor_type", string(batch.SubmittedBy.Type)),
+		sql.Named("actor_id", nullString(batch.SubmittedBy.ID)),
+		sql.Named("actor_ip", nullString(batch.SubmittedBy.IP)),
+		sql.Named("created_at", batch.CreatedAt),
+	)
+	if err != nil {
+		return errors.New("failed to insert batch into database: " + err.Error())
+	}
+
+	for start := 0; start < len(operations); start += itemsPerInsert {
+		end := min(start+itemsPerInsert, len(operations))
+
+		if err := r.createItems(ctx, batch, operations[start:end], start+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createItems inserts the items of the operations in one statement, numbering them from firstLine.
+func (r *repository) createItems(ctx context.Context, batch *Batch, operations []Operation, firstLine int) error {
+	var values strings.Builder
+
+	args := []interface{}{
+		sql.Named("batch_id", batch.ID),
+		sql.Named("tenant_id", batch.TenantID),
+		sql.Named("status", string(ItemPending)),
+	}
+
+	for i, operation := range operations {
+		n := strconv.Itoa(i)
+
+		if i > 0 {
+			values.WriteString(", ")
+		}
+
+		values.WriteString("(@batch_id, @line" + n + ", @tenant_id, @type" + n + ", @wallet_id" + n + ", @amount" + n +
+			", @reference" + n + ", @status)")
+
+		args = append(args,
+			sql.Named("line"+n, firstLine+i),
+			sql.Named("type"+n, string(operation.Type)),
+			sql.Named("wallet_id"+n, operation.WalletID),
+			sql.Named("amount"+n, operation.Amount),
+			sql.Named("reference"+n, nullString(operation.Reference)),
+		)
+	}
+
+	query := `INSERT INTO batch_items (batch_id, line, tenant_id, type, wallet_id, amount, reference, status)
+              VALUES ` + values.String()
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.New("failed to insert batch items into database: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *repository) Get(ctx context.Context, id string) (_ *Batch, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + batchColumns + `
+              FROM batches WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "batch.Repository/Get", query)
+	defer func() { tracing.End(span, err) }()
+
+	batch, err := scanBatch(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBatchNotFound
+		}
+		return nil, errors.New("failed to retrieve batch: " + err.Error())
+	}
+
+	// NOTE: The items of an atomic batch being processed are locked by its transaction until it ends.
+	if batch.Atomic && batch.Status == StatusProcessing {
+		batch.Progress.Pending = batch.Operations
+
+		return batch, nil
+	}
+
+	if err := r.countItems(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func (r *repository) countItems(ctx context.Context, batch *Batch) error {
+	query := `SELECT status, COUNT(*) FROM batch_items
+              WHERE batch_id = @batch_id AND tenant_id = @tenant_id
+              GROUP BY status`
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query,
+		sql.Named("batch_id", batch.ID),
+		sql.Named("tenant_id", batch.TenantID),
+	)
+	if err != nil {
+		return errors.New("failed to count batch items: " + err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			status ItemStatus
+			count  int
+		)
+
+		if err := rows.Scan(&status, &count); err != nil {
+			return errors.New("failed to scan batch item count: " + err.Error())
+		}
+
+		batch.Progress.add(status, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.New("failed to count batch items: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *repository) ListItems(ctx context.Context, batchID string, status ItemStatus) (_ []*Item, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + itemColumns + `
+              FROM batch_items
+              WHERE batch_id = @batch_id AND tenant_id = @tenant_id AND (@status = '' OR status = @status)
+              ORDER BY line`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "batch.Repository/ListItems", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query,
+		sql.Named("batch_id", batchID),
+		sql.Named("tenant_id", tenantID),
+		sql.Named("status", string(status)),
+	)
+	if err != nil {
+		return nil, errors.New("failed to list batch items: " + err.Error())
+	}
+	defer rows.Close()
+
+	items := make([]*Item, 0)
+
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, errors.New("failed to scan batch item: " + err.Error())
+		}
+
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list batch items: " + err.Error())
+	}
+
+	return items, nil
+}
+
+func (r *repository) Claim(ctx context.Context, lease time.Duration) (_ *Batch, err error) {
+	now := time.Now().UTC()
+
+	// NOTE: READPAST skips the batches being claimed by other processors instead of waiting for them.
+	query := `WITH next AS (
+                  SELECT TOP 1 * FROM batches WITH (UPDLOCK, READPAST, ROWLOCK)
+                  WHERE status = 'pending' OR (status = 'processing' AND heartbeat_at < @expired_at)
+                  ORDER BY created_at
+              )
+              UPDATE next
+              SET status = 'processing', started_at = COALESCE(started_at, @now), heartbeat_at = @now,
+                  attempts = attempts + 1
+              OUTPUT ` + batchOutputColumns
+
+	ctx, span := tracing.StartQuerySpan(ctx, "batch.Repository/Claim", query)
+	defer func() { tracing.End(span, err) }()
+
+	batch, err := scanBatch(r.db.QueryRowContext(ctx, query,
+		sql.Named("expired_at", now.Add(-lease)),
+		sql.Named("now", now),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.New("failed to claim batch: " + err.Error())
+	}
+
+	return batch, nil
+}
+
+func (r *repository) Heartbeat(ctx context.Context, id string) (err error) {
+	query := `UPDATE batches SET heartbeat_at = @now WHERE id = @id AND status = 'processing'`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "batch.Repository/Heartbeat", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = r.db.ExecContext(ctx, query,
+		sql.Named("now", time.Now().UTC()),
+		sql.Named("id", id),
+	)
+	if err != nil {
+		return errors.New("failed to record batch heartbeat: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *repository) CompleteItem(ctx context.Context, batchID string, line int, transactionID string) (err error) {
+	return r.updateItem(ctx, "batch.Repository/CompleteItem", batchID, line, ItemSucceeded, transactionID, "")
+}
+
+func (r *repository) FailItem(ctx context.Context, batchID string, line int, message string) (err error) {
+	return r.updateItem(ctx, "batch.Repository/FailItem", batchID, line, ItemFailed, "", message)
+}
+
+func (r *repository) updateItem(
+	ctx context.Context,
+	spanName string,
+	batchID string,
+	line int,
+	status ItemStatus,
+	transactionID string,
+	message string,
+) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE batch_items
+              SET status = @status, transaction_id = @transaction_id, error = @error, processed_at = @processed_at
+              WHERE batch_id = @batch_id AND line = @line AND tenant_id = @tenant_id AND status = 'pending'`
+
+	ctx, span := tracing.StartQuerySpan(ctx, spanName, query)
+	defer func() { tracing.End(span, err) }()
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("status", string(status)),
+		sql.Named("transaction_id", nullString(transactionID)),
+		sql.Named("error", nullString(wallet.Truncate(message, maxErrorLength))),
+		sql.Named("processed_at", time.Now().UTC()),
+		sql.Named("batch_id", batchID),
+		sql.Named("line", line),
+		sql.Named("tenant_id", tenantID),
+	)
+	if err != nil {
+		return errors.New("failed to update batch item: " + err.Error())
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return errors.New("failed to update batch item: " + err.Error())
+	}
+
+	if updated == 0 {
+		return ErrItemProcessed
+	}
+
+	return nil
+}
+
+func (r *repository) CloseItems(ctx context.Context, batchID string, status ItemStatus) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE batch_items
+              SET status = @status, processed_at = @processed_at
+              WHERE batch_id = @batch_id AND tenant_id = @tenant_id AND status = 'pending'`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "batch.Repository/CloseItems", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("status", string(status)),
+		sql.Named("processed_at", time.Now().UTC()),
+		sql.Named("batch_id", batchID),
+		sql.Named("tenant_id", tenantID),
+	)
+	if err != nil {
+		return errors.New("failed to close batch items: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *repository) Finish(ctx context.Context, id string, status Status, message string) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE batches
+              SET status = @status, error = @error, finished_at = @finished_at
+              WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "batch.Repository/Finish", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("status", string(status)),
+		sql.Named("error", nullString(wallet.Truncate(message, maxErrorLength))),
+		sql.Named("finished_at", time.Now().UTC()),
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+	)
+	if err != nil {
+		return errors.New("failed to finish batch: " + err.Error())
+	}
+
+	return nil
+}
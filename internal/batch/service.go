@@ -0,0 +1,154 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+var (
+	ErrBatchNotFound     = errors.New("batch not found")
+	ErrBatchNotFinished  = errors.New("batch not finished")
+	ErrEmptyBatch        = errors.New("batch has no operations")
+	ErrTooManyOperations = errors.New("batch has too many operations")
+	ErrInvalidOperation  = errors.New("invalid batch operation")
+	ErrInvalidItemStatus = errors.New("invalid batch item status")
+)
+
+// OperationError tells which operation of a batch is invalid.
+type OperationError struct {
+	Line    int
+	Message string
+}
+
+func (e *OperationError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Message
+}
+
+func (e *OperationError) Unwrap() error {
+	return ErrInvalidOperation
+}
+
+// DefaultMaxOperations bounds the operations of a batch unless WithMaxOperations is used.
+const DefaultMaxOperations = 10000
+
+// maxErrorLength is the length of the error columns.
+const maxErrorLength = 500
+
+// Service queues batches for a Processor and reports their progress.
+type Service interface {
+	// Submit validates the operations and queues them as a pending batch. Atomic batches apply all their
+	// operations or none of them.
+	Submit(ctx context.Context, operations []Operation, atomic bool) (*Batch, error)
+	Get(ctx context.Context, id string) (*Batch, error)
+	// ListItems returns the items of a finished batch in batch order, only those with status unless it is empty.
+	ListItems(ctx context.Context, id string, status ItemStatus) ([]*Item, error)
+}
+
+type service struct {
+	repo          Repository
+	maxOperations int
+}
+
+type serviceOption func(*service)
+
+// WithMaxOperations bounds the operations of a batch, DefaultMaxOperations otherwise.
+func WithMaxOperations(maxOperations int) serviceOption {
+	return func(s *service) {
+		s.maxOperations = maxOperations
+	}
+}
+
+func NewService(repo Repository, options ...serviceOption) Service {
+	s := &service{
+		repo:          repo,
+		maxOperations: DefaultMaxOperations,
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *service) Submit(ctx context.Context, operations []Operation, atomic bool) (*Batch, error) {
+	if len(operations) == 0 {
+		return nil, ErrEmptyBatch
+	}
+
+	if len(operations) > s.maxOperations {
+		return nil, ErrTooManyOperations
+	}
+
+	for i, operation := range operations {
+		if message := validateOperation(operation); message != "" {
+			return nil, &OperationError{Line: i + 1, Message: message}
+		}
+	}
+
+	batch := &Batch{
+		Status:      StatusPending,
+		Atomic:      atomic,
+		Operations:  len(operations),
+		Progress:    Progress{Pending: len(operations)},
+		SubmittedBy: audit.ActorFromContext(ctx),
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.Create(ctx, batch, operations)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// validateOperation returns why the operation is invalid, or an empty string. Wallets are only checked when
+// the operation runs.
+func validateOperation(operation Operation) string {
+	switch {
+	case operation.Type != OperationDeposit && operation.Type != OperationWithdraw:
+		return "type must be deposit or withdraw"
+	case operation.WalletID == "":
+		return "wallet_id is required"
+	case operation.Amount <= 0 || !wallet.WholeCents(operation.Amount):
+		return "amount must be greater than zero, with at most two decimals"
+	case operation.Reference != "" && operation.Type != OperationDeposit:
+		return "reference is only allowed for deposits"
+	case utf8.RuneCountInString(operation.Reference) > wallet.MaxReferenceLength:
+		return "reference must be at most " + strconv.Itoa(wallet.MaxReferenceLength) + " characters"
+	}
+
+	return ""
+}
+
+func (s *service) Get(ctx context.Context, id string) (*Batch, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *service) ListItems(ctx context.Context, id string, status ItemStatus) ([]*Item, error) {
+	switch status {
+	case "", ItemPending, ItemSucceeded, ItemFailed, ItemRolledBack:
+	default:
+		return nil, ErrInvalidItemStatus
+	}
+
+	batch, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !batch.Status.Finished() {
+		return nil, ErrBatchNotFinished
+	}
+
+	return s.repo.ListItems(ctx, batch.ID, status)
+}
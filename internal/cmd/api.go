@@ -3,12 +3,26 @@ package cmd
 import (
 	"context"
 
+	"tribe-payments-wallet-golang-interview-assignment/internal/adjustment"
 	"tribe-payments-wallet-golang-interview-assignment/internal/api"
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/batch"
 	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/escrow"
+	"tribe-payments-wallet-golang-interview-assignment/internal/health"
 	"tribe-payments-wallet-golang-interview-assignment/internal/http"
+	"tribe-payments-wallet-golang-interview-assignment/internal/interest"
+	"tribe-payments-wallet-golang-interview-assignment/internal/ledger"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/metrics"
+	"tribe-payments-wallet-golang-interview-assignment/internal/reconciliation"
+	"tribe-payments-wallet-golang-interview-assignment/internal/schedule"
+	"tribe-payments-wallet-golang-interview-assignment/internal/settlement"
+	"tribe-payments-wallet-golang-interview-assignment/internal/snapshot"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
 	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
-
-	"database/sql"
+	"tribe-payments-wallet-golang-interview-assignment/migrations"
 
 	"github.com/go-chi/chi/v5"
 	_ "github.com/microsoft/go-mssqldb" // Example for MSSQL Server, adjust if using another driver.
@@ -17,7 +31,6 @@ import (
 	"github.com/sumup-oss/go-pkgs/logger"
 	"github.com/sumup-oss/go-pkgs/os"
 	"github.com/sumup-oss/go-pkgs/task"
-	"moul.io/chizap"
 )
 
 //nolint:gocognit
@@ -34,59 +47,238 @@ func NewApiCmd(osExecutor os.OsExecutor) *cobra.Command {
 				return errors.Wrap(err, "failed to create runtime config")
 			}
 
-			log, err := logger.NewZapLogger(
-				logger.Configuration{
-					Level:         cfg.Log.Level,
-					Encoding:      logger.EncodingJSON,
-					StdoutEnabled: cfg.Log.StdoutEnabled,
-				},
-			)
+			log, err := logging.NewLogger(cfg.Log)
 			if err != nil {
 				return errors.Wrap(err, "failed to create logger")
 			}
 
 			defer log.Sync() //nolint:errcheck
 
+			shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+			if err != nil {
+				return errors.Wrap(err, "failed to set up tracing")
+			}
+
+			defer func() {
+				err := shutdownTracing(context.Background())
+				if err != nil {
+					log.Error("failed to flush traces", logger.ErrorField(err))
+				}
+			}()
+
+			tenants, err := tenant.LoadRegistry(cfg.Tenancy.ConfigFile, cfg.Tenancy.DefaultTenant)
+			if err != nil {
+				return errors.Wrap(err, "failed to load tenants")
+			}
+
+			db, err := openDatabase(ctx, log, cfg.Database)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			schemaVersion, err := migrations.LatestVersion()
+			if err != nil {
+				return errors.Wrap(err, "failed to read migrations")
+			}
+
+			checks := []health.Check{
+				health.NewDatabaseCheck(db, cfg.Database.PingTimeout),
+				health.NewMigrationCheck(db, schemaVersion),
+			}
+
+			if cfg.ReadinessMaxBacklogAge > 0 {
+				checks = append(checks, health.NewBacklogCheck(db, cfg.ReadinessMaxBacklogAge))
+			}
+
+			readiness := health.NewReadiness(checks...)
+
 			shutdownTask := newShutdownTask(
 				log,
 				osExecutor,
 				cfg.GracefulShutdownTimeout,
+				withOnSignal(readiness.SetDraining),
+				withDrainDelay(cfg.ShutdownDrainDelay),
 			)
 
-			db, err := sql.Open("sqlserver", "server=localhost\\SQLEXPRESS;database=wallet_db;trusted_connection=yes;")
-			if err != nil {
-				return errors.Wrap(err, "failed to connect to database")
-			}
-			defer db.Close()
+			appMetrics := metrics.New()
+			appMetrics.RegisterDB(db, "wallet_db")
+
+			auditLog := audit.NewLog(db)
+			walletLedger := ledger.NewLedger(db)
 
 			// Initialise Wallet Repository
 			walletRepo := wallet.NewRepository(db)
 
 			// Initialise Wallet Service with the Repository
-			walletService := wallet.NewService(walletRepo)
+			walletService := wallet.NewTracingService(
+				wallet.NewService(
+					walletRepo,
+					walletLedger,
+					wallet.WithObserver(appMetrics),
+					wallet.WithAuditLog(auditLog),
+				),
+			)
+
+			adjustmentService := adjustment.NewService(
+				adjustment.NewRepository(db),
+				walletService,
+				adjustment.WithAuditLog(auditLog),
+			)
+
+			settlementService := settlement.NewService(
+				settlement.NewRepository(db),
+				walletService,
+				settlement.WithAuditLog(auditLog),
+				settlement.WithTolerance(settlement.Tolerance{
+					Amount: cfg.Settlement.AmountTolerance,
+					Date:   cfg.Settlement.DateTolerance,
+				}),
+			)
+
+			batchRepo := batch.NewRepository(db)
+			batchService := batch.NewService(batchRepo, batch.WithMaxOperations(cfg.Batch.MaxOperations))
+
+			scheduleRepo := schedule.NewRepository(db)
+			scheduleService := schedule.NewService(
+				scheduleRepo,
+				walletService,
+				schedule.WithAuditLog(auditLog),
+			)
+
+			escrowRepo := escrow.NewRepository(db)
+			escrowService := escrow.NewService(
+				escrowRepo,
+				walletService,
+				escrow.WithAuditLog(auditLog),
+			)
+
+			interestAccruer := interest.NewAccruer(
+				interest.NewRepository(db),
+				snapshot.NewSnapshotter(db),
+				walletService,
+				tenants,
+			)
+
+			rateLimitStore := http.NewMemoryRateLimitStore()
+			rateLimitRules := newRateLimitRules(cfg.RateLimits)
 
 			mux := chi.NewRouter()
 			mux.Use(
+				http.RequestID,
+				tracing.Middleware,
+				http.RequestLogger(log),
+				appMetrics.Middleware,
+				http.AccessLog,
 				http.Recovery(
 					log,
-					api.WritePanicResponse(log),
+					appMetrics.CountPanics(api.WritePanicResponse(log)),
 				),
-				chizap.New(
-					log.Logger,
-					&chizap.Opts{
-						WithReferer:   true,
-						WithUserAgent: true,
+				http.Cors(
+					log,
+					http.CorsOptions{
+						AllowedOrigins:   cfg.CorsAllowedOrigins,
+						AllowedMethods:   cfg.CorsAllowedMethods,
+						AllowedHeaders:   cfg.CorsHeaders(),
+						ExposedHeaders:   cfg.CorsExposedHeaders,
+						AllowCredentials: cfg.CorsAllowCredentials,
+						MaxAge:           cfg.CorsMaxAge,
+						Debug:            cfg.CorsDebug,
 					},
 				),
+				http.RateLimit(
+					log,
+					rateLimitStore,
+					rateLimitRules,
+					cfg.Tenancy.APIKeyHeader,
+					api.WriteRateLimitResponse,
+				),
 			)
 
 			// Pass walletService to RegisterRoutes
-			api.RegisterRoutes(mux, log, walletService)
+			api.RegisterRoutes(
+				mux,
+				log,
+				readiness,
+				tenants,
+				cfg.Tenancy,
+				walletService,
+				batchService,
+				cfg.Batch,
+				scheduleService,
+				interestAccruer,
+				escrowService,
+			)
+
+			httpServerOptions := []http.Option{
+				http.WithMaxHeaderBytes(cfg.MaxHeaderBytes),
+				http.WithReadHeaderTimeout(cfg.ReadHeaderTimeout),
+				http.WithReadTimeout(cfg.ReadTimeout),
+				http.WithServerShutdownTimeout(cfg.GracefulShutdownTimeout),
+				http.WithWriteTimeout(cfg.WriteTimeout),
+			}
+
+			if cfg.TLS.Enabled() {
+				tlsConfig, err := http.NewTLSConfig(
+					log,
+					http.TLSOptions{
+						CertFile:              cfg.TLS.CertFile,
+						KeyFile:               cfg.TLS.KeyFile,
+						MinVersion:            cfg.TLS.MinVersion,
+						ClientCAFile:          cfg.TLS.ClientCAFile,
+						AllowedClientSubjects: cfg.TLS.ClientAllowedSubjects,
+						ReloadInterval:        cfg.TLS.ReloadInterval,
+					},
+				)
+				if err != nil {
+					return errors.Wrap(err, "failed to create TLS config")
+				}
+
+				httpServerOptions = append(httpServerOptions, http.WithTLSConfig(tlsConfig))
+			}
 
 			httpServer := http.NewServer(
 				log,
 				cfg.ListenAddress,
 				mux,
+				httpServerOptions...,
+			)
+
+			adminMux := chi.NewRouter()
+			adminMux.Use(
+				http.RequestID,
+				http.RequestLogger(log),
+				http.Recovery(
+					log,
+					api.WritePanicResponse(log),
+				),
+			)
+
+			if cfg.OperatorSigningKey == "" {
+				log.Warn("Operators are not authenticated without OPERATOR_SIGNING_KEY, adjustments are refused")
+			}
+
+			api.RegisterAdminRoutes(
+				adminMux,
+				log,
+				tenants,
+				readiness,
+				appMetrics.Handler(),
+				log.Level(),
+				cfg,
+				walletService,
+				adjustmentService,
+				settlementService,
+				snapshot.NewSnapshotter(db),
+				auditLog,
+				walletLedger,
+			)
+
+			adminServer := http.NewServer(
+				log,
+				cfg.AdminListenAddress,
+				adminMux,
+				http.WithName("admin"),
 				http.WithMaxHeaderBytes(cfg.MaxHeaderBytes),
 				http.WithReadHeaderTimeout(cfg.ReadHeaderTimeout),
 				http.WithReadTimeout(cfg.ReadTimeout),
@@ -94,11 +286,95 @@ func NewApiCmd(osExecutor os.OsExecutor) *cobra.Command {
 				http.WithWriteTimeout(cfg.WriteTimeout),
 			)
 
-			taskGroup := task.NewGroup()
-			taskGroup.Go(
+			tasks := []task.TaskFunc{
 				shutdownTask.Run,
 				httpServer.Run,
-			)
+				adminServer.Run,
+			}
+
+			if cfg.Reconciliation.Enabled {
+				reconciliationTask, err := newReconciliationTask(
+					log,
+					reconciliation.NewReconciler(db, walletService, reconciliation.WithObserver(appMetrics)),
+					cfg.Reconciliation,
+				)
+				if err != nil {
+					return err
+				}
+
+				tasks = append(tasks, reconciliationTask.Run)
+			}
+
+			if cfg.Snapshots.Enabled {
+				snapshotTask, err := newSnapshotTask(log, snapshot.NewSnapshotter(db), cfg.Snapshots)
+				if err != nil {
+					return err
+				}
+
+				tasks = append(tasks, snapshotTask.Run)
+			}
+
+			if cfg.Batch.WorkerEnabled {
+				batchTask := newBatchTask(
+					log,
+					batch.NewProcessor(
+						batchRepo,
+						walletService,
+						tenants,
+						batch.WithLease(cfg.Batch.Lease),
+						batch.WithMaxAttempts(cfg.Batch.MaxAttempts),
+						batch.WithWithdrawLimiter(
+							http.NewWalletRateLimiter(rateLimitStore, rateLimitRules, "POST", "/v1/wallets/{id}/withdraw"),
+						),
+					),
+					cfg.Batch.PollInterval,
+				)
+
+				tasks = append(tasks, batchTask.Run)
+			}
+
+			if cfg.Schedules.WorkerEnabled {
+				scheduleTask := newScheduleTask(
+					log,
+					schedule.NewExecutor(
+						scheduleRepo,
+						walletService,
+						tenants,
+						schedule.WithRetryInterval(cfg.Schedules.RetryInterval),
+						schedule.WithMaxRetries(cfg.Schedules.MaxRetries),
+					),
+					cfg.Schedules.PollInterval,
+				)
+
+				tasks = append(tasks, scheduleTask.Run)
+			}
+
+			if cfg.Escrows.WorkerEnabled {
+				escrowTask := newEscrowTask(
+					log,
+					escrow.NewExpirer(
+						escrowRepo,
+						escrowService,
+						tenants,
+						escrow.WithRetryInterval(cfg.Escrows.RetryInterval),
+					),
+					cfg.Escrows.PollInterval,
+				)
+
+				tasks = append(tasks, escrowTask.Run)
+			}
+
+			if cfg.Interest.Enabled {
+				interestTask, err := newInterestTask(log, interestAccruer, cfg.Interest)
+				if err != nil {
+					return err
+				}
+
+				tasks = append(tasks, interestTask.Run)
+			}
+
+			taskGroup := task.NewGroup()
+			taskGroup.Go(tasks...)
 
 			err = taskGroup.Wait(ctx)
 			if err != nil {
@@ -111,3 +387,20 @@ func NewApiCmd(osExecutor os.OsExecutor) *cobra.Command {
 		},
 	}
 }
+
+func newRateLimitRules(cfgRules config.RateLimitRules) []http.RateLimitRule {
+	rules := make([]http.RateLimitRule, 0, len(cfgRules))
+
+	for _, rule := range cfgRules {
+		rules = append(rules, http.RateLimitRule{
+			Method:    rule.Method,
+			Pattern:   rule.Pattern,
+			Limit:     rule.Limit,
+			Window:    rule.Window,
+			Key:       http.RateLimitKey(rule.Key),
+			Algorithm: http.RateLimitAlgorithm(rule.Algorithm),
+		})
+	}
+
+	return rules
+}
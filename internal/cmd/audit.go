@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/os"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+)
+
+func NewAuditCmd(osExecutor os.OsExecutor) *cobra.Command {
+	cmdInstance := &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect the audit log",
+		Long:  "Inspect the audit log",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdInstance.AddCommand(
+		newAuditVerifyCmd(osExecutor),
+	)
+
+	return cmdInstance
+}
+
+func newAuditVerifyCmd(_ os.OsExecutor) *cobra.Command {
+	var tenantIDs []string
+
+	cmdInstance := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the hash chain of the audit log",
+		Long: "Verify the hash chain of the audit log of every tenant, or of the tenants given with --tenant. " +
+			"Prints one JSON verification per tenant and fails when a chain is broken.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			cfg, err := config.NewServerConfig()
+			if err != nil {
+				return errors.Wrap(err, "failed to create runtime config")
+			}
+
+			log, err := logging.NewLogger(cfg.Log)
+			if err != nil {
+				return errors.Wrap(err, "failed to create logger")
+			}
+
+			defer log.Sync() //nolint:errcheck
+
+			db, err := openDatabase(ctx, log, cfg.Database)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			auditLog := audit.NewLog(db)
+
+			if len(tenantIDs) == 0 {
+				tenantIDs, err = auditLog.Tenants(ctx)
+				if err != nil {
+					return err
+				}
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			broken := 0
+
+			for _, tenantID := range tenantIDs {
+				verification, err := auditLog.Verify(ctx, tenantID)
+				if err != nil {
+					return errors.Wrap(err, "failed to verify audit log of tenant %s", tenantID)
+				}
+
+				if !verification.Valid {
+					broken++
+				}
+
+				if err := encoder.Encode(verification); err != nil {
+					return errors.Wrap(err, "failed to write verification")
+				}
+			}
+
+			if broken > 0 {
+				return errors.New("audit log hash chain broken for %d tenant(s)", broken)
+			}
+
+			return nil
+		},
+	}
+
+	cmdInstance.Flags().StringSliceVar(&tenantIDs, "tenant", nil, "tenant to verify, all tenants when omitted")
+
+	return cmdInstance
+}
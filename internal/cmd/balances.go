@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	stdOs "os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/os"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/snapshot"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+)
+
+func NewBalancesCmd(_ os.OsExecutor) *cobra.Command {
+	var (
+		tenantID string
+		date     string
+		format   string
+		output   string
+	)
+
+	cmdInstance := &cobra.Command{
+		Use:   "balances",
+		Short: "Report the end-of-day wallet balances",
+		Long: "Report the balance at the end of a day of every wallet of a tenant, with the total per currency, " +
+			"from the balance snapshots of the day or, when missing, from the transactions.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if format != snapshot.FormatJSON && format != snapshot.FormatCSV {
+				return errors.New("unsupported report format %q", format)
+			}
+
+			day := snapshot.Day(time.Now()).AddDate(0, 0, -1)
+
+			if date != "" {
+				var err error
+
+				if day, err = time.Parse(time.DateOnly, date); err != nil {
+					return errors.New("--date must be a date (YYYY-MM-DD)")
+				}
+			}
+
+			cfg, err := config.NewServerConfig()
+			if err != nil {
+				return errors.Wrap(err, "failed to create runtime config")
+			}
+
+			log, err := logging.NewLogger(cfg.Log)
+			if err != nil {
+				return errors.Wrap(err, "failed to create logger")
+			}
+
+			defer log.Sync() //nolint:errcheck
+
+			walletTenant, err := loadTenant(cfg.Tenancy, tenantID)
+			if err != nil {
+				return err
+			}
+
+			db, err := openDatabase(ctx, log, cfg.Database)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			ctx = tenant.NewContext(logging.NewContext(ctx, log), walletTenant)
+
+			report, err := snapshot.NewSnapshotter(db).EndOfDay(ctx, day)
+			if err != nil {
+				return errors.Wrap(err, "failed to report end-of-day balances")
+			}
+
+			writer := cmd.OutOrStdout()
+
+			if output != "" {
+				file, err := stdOs.Create(output)
+				if err != nil {
+					return errors.Wrap(err, "failed to create report file")
+				}
+				defer file.Close()
+
+				writer = file
+			}
+
+			if err := snapshot.WriteReport(writer, report, format); err != nil {
+				return errors.Wrap(err, "failed to write report")
+			}
+
+			return nil
+		},
+	}
+
+	cmdInstance.Flags().StringVar(&tenantID, "tenant", "", "tenant of the wallets, DEFAULT_TENANT when omitted")
+	cmdInstance.Flags().StringVar(&date, "date", "", "day of the balances, as YYYY-MM-DD, yesterday when omitted")
+	cmdInstance.Flags().StringVar(&format, "format", snapshot.FormatJSON, "report format, json or csv")
+	cmdInstance.Flags().StringVar(&output, "output", "", "file to write the report to, stdout when omitted")
+
+	return cmdInstance
+}
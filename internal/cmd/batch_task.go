@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/batch"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+)
+
+// batchTask is a task that processes the submitted batches one after the other, polling for new ones when idle.
+type batchTask struct {
+	log          logger.StructuredLogger
+	processor    *batch.Processor
+	pollInterval time.Duration
+}
+
+// newBatchTask bootstraps a new instance of batchTask.
+func newBatchTask(log logger.StructuredLogger, processor *batch.Processor, pollInterval time.Duration) *batchTask {
+	return &batchTask{
+		log:          log,
+		processor:    processor,
+		pollInterval: pollInterval,
+	}
+}
+
+// Run processes batches until ctx is done. A batch interrupted by an error or by the shutdown is resumed once
+// its lease expires, by this or another instance.
+func (t *batchTask) Run(ctx context.Context) error {
+	for {
+		processed, err := t.processor.ProcessNext(logging.NewContext(ctx, t.log))
+		if err != nil && ctx.Err() == nil {
+			t.log.Error("failed to process batch", logger.ErrorField(err))
+		}
+
+		if processed && err == nil {
+			continue
+		}
+
+		timer := time.NewTimer(t.pollInterval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"time"
+)
+
+// parseTimeOfDay parses a UTC time of day formatted as `HH:MM` into the duration since midnight.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	at, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute, nil
+}
+
+// nextDailyRun returns the first instant after now at timeOfDay, UTC.
+func nextDailyRun(now time.Time, timeOfDay time.Duration) time.Time {
+	next := now.Truncate(24 * time.Hour).Add(timeOfDay)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+
+	return next
+}
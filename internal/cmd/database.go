@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/database"
+)
+
+// openDatabase opens the wallet database and pings it, so commands fail early when it is unreachable.
+func openDatabase(ctx context.Context, log logger.StructuredLogger, cfg config.Database) (*sql.DB, error) {
+	db, err := database.Open(
+		log,
+		"sqlserver",
+		"server=localhost\\SQLEXPRESS;database=wallet_db;trusted_connection=yes;",
+		database.QueryLogOptions{
+			Enabled:            cfg.LogQueries,
+			LogSQL:             cfg.LogSQL,
+			LogArgs:            cfg.LogSQLArgs,
+			RedactedArgs:       cfg.LogSQLRedactedArgs,
+			SlowQueryThreshold: cfg.SlowQueryThreshold,
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to database")
+	}
+
+	pingCtx, cancelPing := context.WithTimeout(ctx, cfg.PingTimeout)
+	err = db.PingContext(pingCtx)
+	cancelPing()
+
+	if err != nil {
+		_ = db.Close()
+
+		return nil, errors.Wrap(err, "failed to ping database")
+	}
+
+	return db, nil
+}
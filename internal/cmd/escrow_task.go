@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/escrow"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+)
+
+// escrowTask is a task that settles the expired escrows one after the other, polling for new ones when idle.
+type escrowTask struct {
+	log          logger.StructuredLogger
+	expirer      *escrow.Expirer
+	pollInterval time.Duration
+}
+
+// newEscrowTask bootstraps a new instance of escrowTask.
+func newEscrowTask(
+	log logger.StructuredLogger,
+	expirer *escrow.Expirer,
+	pollInterval time.Duration,
+) *escrowTask {
+	return &escrowTask{
+		log:          log,
+		expirer:      expirer,
+		pollInterval: pollInterval,
+	}
+}
+
+// Run settles expired escrows until ctx is done. An expiry interrupted by an error or by the shutdown is rolled
+// back and stays due, so it is settled again by this or another instance.
+func (t *escrowTask) Run(ctx context.Context) error {
+	for {
+		expired, err := t.expirer.ExpireNext(logging.NewContext(ctx, t.log))
+		if err != nil && ctx.Err() == nil {
+			t.log.Error("failed to expire escrow", logger.ErrorField(err))
+		}
+
+		if expired && err == nil {
+			continue
+		}
+
+		timer := time.NewTimer(t.pollInterval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/os"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/interest"
+	"tribe-payments-wallet-golang-interview-assignment/internal/ledger"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/snapshot"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+func NewInterestCmd(_ os.OsExecutor) *cobra.Command {
+	var (
+		date string
+		from string
+	)
+
+	cmdInstance := &cobra.Command{
+		Use:   "interest",
+		Short: "Accrue and capitalise the interest of the savings wallets",
+		Long: "Accrue the interest of a day of every wallet with a product, then pay the interest of the periods " +
+			"ending that day and charge the overdraft interest and fees of the wallets that ended it overdrawn. " +
+			"Wallets already accrued, capitalised or charged are skipped. With --from, every day up to --date is " +
+			"run, oldest first.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			last := snapshot.Day(time.Now()).AddDate(0, 0, -1)
+
+			if date != "" {
+				var err error
+
+				if last, err = time.Parse(time.DateOnly, date); err != nil {
+					return errors.New("--date must be a date (YYYY-MM-DD)")
+				}
+			}
+
+			first := last
+
+			if from != "" {
+				var err error
+
+				if first, err = time.Parse(time.DateOnly, from); err != nil {
+					return errors.New("--from must be a date (YYYY-MM-DD)")
+				}
+
+				if first.After(last) {
+					return errors.New("--from must not be after --date")
+				}
+			}
+
+			cfg, err := config.NewServerConfig()
+			if err != nil {
+				return errors.Wrap(err, "failed to create runtime config")
+			}
+
+			log, err := logging.NewLogger(cfg.Log)
+			if err != nil {
+				return errors.Wrap(err, "failed to create logger")
+			}
+
+			defer log.Sync() //nolint:errcheck
+
+			tenants, err := tenant.LoadRegistry(cfg.Tenancy.ConfigFile, cfg.Tenancy.DefaultTenant)
+			if err != nil {
+				return errors.Wrap(err, "failed to load tenants")
+			}
+
+			db, err := openDatabase(ctx, log, cfg.Database)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			ctx = logging.NewContext(ctx, log)
+
+			// NOTE: Interest payments are attributed to the system actor in the audit log.
+			walletService := wallet.NewService(
+				wallet.NewRepository(db),
+				ledger.NewLedger(db),
+				wallet.WithAuditLog(audit.NewLog(db)),
+			)
+
+			accruer := interest.NewAccruer(
+				interest.NewRepository(db),
+				snapshot.NewSnapshotter(db),
+				walletService,
+				tenants,
+			)
+
+			for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+				if err := accruer.Run(ctx, day); err != nil {
+					return errors.Wrap(err, "failed to accrue interest of %s", day.Format(time.DateOnly))
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmdInstance.Flags().StringVar(&date, "date", "", "day to accrue, as YYYY-MM-DD, yesterday when omitted")
+	cmdInstance.Flags().StringVar(&from, "from", "", "first day to accrue, as YYYY-MM-DD, to backfill up to --date")
+
+	return cmdInstance
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/interest"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/snapshot"
+)
+
+// interestTask is a task that accrues and capitalises the interest of the previous day, and charges the
+// overdrafts of that day, once a day at a fixed UTC time.
+type interestTask struct {
+	log       logger.StructuredLogger
+	accruer   *interest.Accruer
+	timeOfDay time.Duration
+}
+
+// newInterestTask bootstraps a new instance of interestTask.
+func newInterestTask(
+	log logger.StructuredLogger,
+	accruer *interest.Accruer,
+	cfg config.Interest,
+) (*interestTask, error) {
+	timeOfDay, err := parseTimeOfDay(cfg.Time)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid interest time %q", cfg.Time)
+	}
+
+	return &interestTask{
+		log:       log,
+		accruer:   accruer,
+		timeOfDay: timeOfDay,
+	}, nil
+}
+
+// Run accrues the interest daily until ctx is done. Failed runs are logged and retried the next day, when the
+// missed day can be accrued with the `interest` command.
+func (t *interestTask) Run(ctx context.Context) error {
+	for {
+		next := nextDailyRun(time.Now().UTC(), t.timeOfDay)
+		t.log.Info("next interest accrual scheduled", zap.Time("at", next))
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil
+		case <-timer.C:
+		}
+
+		day := snapshot.Day(time.Now()).AddDate(0, 0, -1)
+
+		if err := t.accruer.Run(logging.NewContext(ctx, t.log), day); err != nil {
+			t.log.Error("failed to accrue interest", logger.ErrorField(err))
+		}
+	}
+}
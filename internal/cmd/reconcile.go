@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	stdOs "os"
+
+	"github.com/spf13/cobra"
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/os"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/ledger"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/reconciliation"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+func NewReconcileCmd(_ os.OsExecutor) *cobra.Command {
+	var (
+		format string
+		output string
+		freeze bool
+	)
+
+	cmdInstance := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Reconcile the wallet balances with their movements",
+		Long: "Recompute the balance of every wallet from its transactions and ledger postings and report the " +
+			"wallets that do not match. Fails when a wallet does not reconcile.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if format != reconciliation.FormatJSON && format != reconciliation.FormatCSV {
+				return errors.New("unsupported report format %q", format)
+			}
+
+			cfg, err := config.NewServerConfig()
+			if err != nil {
+				return errors.Wrap(err, "failed to create runtime config")
+			}
+
+			log, err := logging.NewLogger(cfg.Log)
+			if err != nil {
+				return errors.Wrap(err, "failed to create logger")
+			}
+
+			defer log.Sync() //nolint:errcheck
+
+			db, err := openDatabase(ctx, log, cfg.Database)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			ctx = logging.NewContext(ctx, log)
+
+			// NOTE: Freezing is attributed to the system actor in the audit log.
+			walletService := wallet.NewService(
+				wallet.NewRepository(db),
+				ledger.NewLedger(db),
+				wallet.WithAuditLog(audit.NewLog(db)),
+			)
+
+			report, err := reconciliation.NewReconciler(db, walletService).Run(ctx, freeze)
+			if err != nil {
+				return errors.Wrap(err, "failed to reconcile wallets")
+			}
+
+			writer := cmd.OutOrStdout()
+
+			if output != "" {
+				file, err := stdOs.Create(output)
+				if err != nil {
+					return errors.Wrap(err, "failed to create report file")
+				}
+				defer file.Close()
+
+				writer = file
+			}
+
+			if err := reconciliation.WriteReport(writer, report, format); err != nil {
+				return errors.Wrap(err, "failed to write report")
+			}
+
+			if len(report.Mismatches) > 0 {
+				return errors.New("%d wallet(s) do not reconcile", len(report.Mismatches))
+			}
+
+			return nil
+		},
+	}
+
+	cmdInstance.Flags().StringVar(&format, "format", reconciliation.FormatJSON, "report format, json or csv")
+	cmdInstance.Flags().StringVar(&output, "output", "", "file to write the report to, stdout when omitted")
+	cmdInstance.Flags().BoolVar(&freeze, "freeze", false, "freeze the wallets that do not reconcile")
+
+	return cmdInstance
+}
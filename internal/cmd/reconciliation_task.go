@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	stdOs "os"
+	"path/filepath"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/reconciliation"
+)
+
+// reconciliationTask is a task that reconciles the wallet balances once a day at a fixed UTC time.
+type reconciliationTask struct {
+	log        logger.StructuredLogger
+	reconciler *reconciliation.Reconciler
+	timeOfDay  time.Duration
+	freeze     bool
+	reportDir  string
+}
+
+// newReconciliationTask bootstraps a new instance of reconciliationTask.
+func newReconciliationTask(
+	log logger.StructuredLogger,
+	reconciler *reconciliation.Reconciler,
+	cfg config.Reconciliation,
+) (*reconciliationTask, error) {
+	timeOfDay, err := parseTimeOfDay(cfg.Time)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid reconciliation time %q", cfg.Time)
+	}
+
+	return &reconciliationTask{
+		log:        log,
+		reconciler: reconciler,
+		timeOfDay:  timeOfDay,
+		freeze:     cfg.Freeze,
+		reportDir:  cfg.ReportDir,
+	}, nil
+}
+
+// Run runs the reconciliation daily until ctx is done. Failed runs are logged and retried the next day.
+func (t *reconciliationTask) Run(ctx context.Context) error {
+	for {
+		next := nextDailyRun(time.Now().UTC(), t.timeOfDay)
+		t.log.Info("next reconciliation scheduled", zap.Time("at", next))
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil
+		case <-timer.C:
+		}
+
+		t.run(ctx)
+	}
+}
+
+func (t *reconciliationTask) run(ctx context.Context) {
+	report, err := t.reconciler.Run(logging.NewContext(ctx, t.log), t.freeze)
+	if err != nil {
+		t.log.Error("reconciliation failed", logger.ErrorField(err))
+
+		return
+	}
+
+	for _, mismatch := range report.Mismatches {
+		t.log.Warn(
+			"wallet balance does not reconcile",
+			zap.String("tenant_id", mismatch.TenantID),
+			zap.String("wallet_id", mismatch.WalletID),
+			zap.Float64("balance", mismatch.Balance),
+			zap.Float64("movements_balance", mismatch.MovementsBalance),
+			zap.Float64("ledger_balance", mismatch.LedgerBalance),
+			zap.Bool("frozen", mismatch.Frozen),
+		)
+	}
+
+	if t.reportDir == "" {
+		return
+	}
+
+	if err := t.writeReport(report); err != nil {
+		t.log.Error("failed to write reconciliation report", logger.ErrorField(err))
+	}
+}
+
+func (t *reconciliationTask) writeReport(report *reconciliation.Report) error {
+	path := filepath.Join(t.reportDir, "reconciliation-"+report.StartedAt.Format("20060102T150405Z")+".json")
+
+	file, err := stdOs.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := reconciliation.WriteReport(file, report, reconciliation.FormatJSON); err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	return file.Close()
+}
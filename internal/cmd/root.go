@@ -21,6 +21,12 @@ func NewRootCmd(osExecutor os.OsExecutor) *cobra.Command {
 
 	cmdInstance.AddCommand(
 		NewApiCmd(osExecutor),
+		NewAuditCmd(osExecutor),
+		NewReconcileCmd(osExecutor),
+		NewStatementCmd(osExecutor),
+		NewSnapshotCmd(osExecutor),
+		NewBalancesCmd(osExecutor),
+		NewInterestCmd(osExecutor),
 	)
 
 	return cmdInstance
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/schedule"
+)
+
+// scheduleTask is a task that runs the due schedules one after the other, polling for new ones when idle.
+type scheduleTask struct {
+	log          logger.StructuredLogger
+	executor     *schedule.Executor
+	pollInterval time.Duration
+}
+
+// newScheduleTask bootstraps a new instance of scheduleTask.
+func newScheduleTask(
+	log logger.StructuredLogger,
+	executor *schedule.Executor,
+	pollInterval time.Duration,
+) *scheduleTask {
+	return &scheduleTask{
+		log:          log,
+		executor:     executor,
+		pollInterval: pollInterval,
+	}
+}
+
+// Run runs schedules until ctx is done. A schedule interrupted by an error or by the shutdown is rolled back and
+// stays due, so it is run again by this or another instance.
+func (t *scheduleTask) Run(ctx context.Context) error {
+	for {
+		ran, err := t.executor.RunNext(logging.NewContext(ctx, t.log))
+		if err != nil && ctx.Err() == nil {
+			t.log.Error("failed to run schedule", logger.ErrorField(err))
+		}
+
+		if ran && err == nil {
+			continue
+		}
+
+		timer := time.NewTimer(t.pollInterval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil
+		case <-timer.C:
+		}
+	}
+}
@@ -21,7 +21,9 @@ var (
 type shutdownTaskOptionsFunc = func(*shutdownTaskOptions)
 
 type shutdownTaskOptions struct {
-	signals []stdOs.Signal
+	signals    []stdOs.Signal
+	onSignal   []func()
+	drainDelay time.Duration
 }
 
 func newShutdownTaskOptions(opts ...shutdownTaskOptionsFunc) *shutdownTaskOptions {
@@ -43,6 +45,21 @@ func withSignals(signals []stdOs.Signal) shutdownTaskOptionsFunc {
 	}
 }
 
+// withOnSignal returns a function that adds a callback run as soon as a signal is received.
+func withOnSignal(fn func()) shutdownTaskOptionsFunc {
+	return func(o *shutdownTaskOptions) {
+		o.onSignal = append(o.onSignal, fn)
+	}
+}
+
+// withDrainDelay returns a function that sets how long to wait after a signal before
+// the task terminates, giving load balancers time to stop routing traffic.
+func withDrainDelay(drainDelay time.Duration) shutdownTaskOptionsFunc {
+	return func(o *shutdownTaskOptions) {
+		o.drainDelay = drainDelay
+	}
+}
+
 // shutdownTask is a task that listens for a list of stdOs.Signal and terminates after
 // shutdownDeadline.
 type shutdownTask struct {
@@ -50,6 +67,8 @@ type shutdownTask struct {
 	osExecutor       os.OsExecutor
 	shutdownDeadline time.Duration
 	signals          []stdOs.Signal
+	onSignal         []func()
+	drainDelay       time.Duration
 }
 
 // newShutdownTask bootstrap a new instance of ShutdownTask.
@@ -66,6 +85,8 @@ func newShutdownTask(
 		osExecutor:       osExecutor,
 		shutdownDeadline: shutdownDeadline,
 		signals:          taskOpts.signals,
+		onSignal:         taskOpts.onSignal,
+		drainDelay:       taskOpts.drainDelay,
 	}
 }
 
@@ -89,6 +110,19 @@ func (t *shutdownTask) Run(ctx context.Context) error {
 
 			t.osExecutor.Exit(1)
 		}()
+
+		for _, fn := range t.onSignal {
+			fn()
+		}
+
+		if t.drainDelay > 0 {
+			t.log.Info("draining before shutdown", zap.Stringer("delay", t.drainDelay))
+
+			select {
+			case <-time.After(t.drainDelay):
+			case <-ctx.Done():
+			}
+		}
 	case <-ctx.Done():
 	}
 
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/os"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/snapshot"
+)
+
+func NewSnapshotCmd(_ os.OsExecutor) *cobra.Command {
+	var (
+		date string
+		from string
+	)
+
+	cmdInstance := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Snapshot the end-of-day wallet balances",
+		Long: "Record the balance at the end of a day of every wallet of every tenant. Wallets already " +
+			"snapshotted for the day are skipped. With --from, every day up to --date is snapshotted, oldest first.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			last := snapshot.Day(time.Now()).AddDate(0, 0, -1)
+
+			if date != "" {
+				var err error
+
+				if last, err = time.Parse(time.DateOnly, date); err != nil {
+					return errors.New("--date must be a date (YYYY-MM-DD)")
+				}
+			}
+
+			first := last
+
+			if from != "" {
+				var err error
+
+				if first, err = time.Parse(time.DateOnly, from); err != nil {
+					return errors.New("--from must be a date (YYYY-MM-DD)")
+				}
+
+				if first.After(last) {
+					return errors.New("--from must not be after --date")
+				}
+			}
+
+			cfg, err := config.NewServerConfig()
+			if err != nil {
+				return errors.Wrap(err, "failed to create runtime config")
+			}
+
+			log, err := logging.NewLogger(cfg.Log)
+			if err != nil {
+				return errors.Wrap(err, "failed to create logger")
+			}
+
+			defer log.Sync() //nolint:errcheck
+
+			db, err := openDatabase(ctx, log, cfg.Database)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			ctx = logging.NewContext(ctx, log)
+
+			snapshotter := snapshot.NewSnapshotter(db)
+
+			for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+				if _, err := snapshotter.Take(ctx, day); err != nil {
+					return errors.Wrap(err, "failed to snapshot balances of %s", day.Format(time.DateOnly))
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmdInstance.Flags().StringVar(&date, "date", "", "day to snapshot, as YYYY-MM-DD, yesterday when omitted")
+	cmdInstance.Flags().StringVar(&from, "from", "", "first day to snapshot, as YYYY-MM-DD, to backfill up to --date")
+
+	return cmdInstance
+}
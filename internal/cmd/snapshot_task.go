@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/snapshot"
+)
+
+// snapshotTask is a task that snapshots the wallet balances of the previous day once a day at a fixed UTC time.
+type snapshotTask struct {
+	log         logger.StructuredLogger
+	snapshotter *snapshot.Snapshotter
+	timeOfDay   time.Duration
+}
+
+// newSnapshotTask bootstraps a new instance of snapshotTask.
+func newSnapshotTask(
+	log logger.StructuredLogger,
+	snapshotter *snapshot.Snapshotter,
+	cfg config.Snapshots,
+) (*snapshotTask, error) {
+	timeOfDay, err := parseTimeOfDay(cfg.Time)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid snapshots time %q", cfg.Time)
+	}
+
+	return &snapshotTask{
+		log:         log,
+		snapshotter: snapshotter,
+		timeOfDay:   timeOfDay,
+	}, nil
+}
+
+// Run snapshots the balances daily until ctx is done. Failed runs are logged and retried the next day, when the
+// snapshots of the missed day can be taken with the `snapshot` command.
+func (t *snapshotTask) Run(ctx context.Context) error {
+	for {
+		next := nextDailyRun(time.Now().UTC(), t.timeOfDay)
+		t.log.Info("next balance snapshots scheduled", zap.Time("at", next))
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil
+		case <-timer.C:
+		}
+
+		day := snapshot.Day(time.Now()).AddDate(0, 0, -1)
+
+		if _, err := t.snapshotter.Take(logging.NewContext(ctx, t.log), day); err != nil {
+			t.log.Error("failed to take balance snapshots", logger.ErrorField(err))
+		}
+	}
+}
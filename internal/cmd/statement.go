@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	stdOs "os"
+
+	"github.com/spf13/cobra"
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/os"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/ledger"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/statement"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+func NewStatementCmd(_ os.OsExecutor) *cobra.Command {
+	var (
+		tenantID string
+		walletID string
+		from     string
+		to       string
+		format   string
+		output   string
+	)
+
+	cmdInstance := &cobra.Command{
+		Use:   "statement",
+		Short: "Generate the statement of a wallet",
+		Long: "Generate the statement of a wallet for a period as JSON, CSV or PDF, with the opening balance, " +
+			"every movement with the running balance and the closing balance.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			statementFormat, err := statement.ParseFormat(format)
+			if err != nil {
+				return errors.New("unsupported statement format %q", format)
+			}
+
+			start, end, err := statement.ParsePeriod(from, to)
+			if err != nil {
+				return errors.New("--from and --to must be dates (YYYY-MM-DD) or RFC 3339 timestamps")
+			}
+
+			cfg, err := config.NewServerConfig()
+			if err != nil {
+				return errors.Wrap(err, "failed to create runtime config")
+			}
+
+			log, err := logging.NewLogger(cfg.Log)
+			if err != nil {
+				return errors.Wrap(err, "failed to create logger")
+			}
+
+			defer log.Sync() //nolint:errcheck
+
+			walletTenant, err := loadTenant(cfg.Tenancy, tenantID)
+			if err != nil {
+				return err
+			}
+
+			db, err := openDatabase(ctx, log, cfg.Database)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			ctx = tenant.NewContext(logging.NewContext(ctx, log), walletTenant)
+
+			walletService := wallet.NewService(wallet.NewRepository(db), ledger.NewLedger(db))
+
+			walletStatement, err := walletService.Statement(ctx, walletID, start, end)
+			if err != nil {
+				return errors.Wrap(err, "failed to generate statement")
+			}
+
+			writer := cmd.OutOrStdout()
+
+			if output != "" {
+				file, err := stdOs.Create(output)
+				if err != nil {
+					return errors.Wrap(err, "failed to create statement file")
+				}
+				defer file.Close()
+
+				writer = file
+			}
+
+			if err := statement.Write(writer, walletStatement, statementFormat); err != nil {
+				return errors.Wrap(err, "failed to write statement")
+			}
+
+			return nil
+		},
+	}
+
+	cmdInstance.Flags().StringVar(&tenantID, "tenant", "", "tenant of the wallet, DEFAULT_TENANT when omitted")
+	cmdInstance.Flags().StringVar(&walletID, "wallet", "", "wallet to generate the statement of")
+	cmdInstance.Flags().StringVar(&from, "from", "", "start of the period, a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+	cmdInstance.Flags().StringVar(&to, "to", "", "end of the period, an included date or an RFC 3339 timestamp")
+	cmdInstance.Flags().StringVar(&format, "format", string(statement.FormatPDF), "statement format, json, csv or pdf")
+	cmdInstance.Flags().StringVar(&output, "output", "", "file to write the statement to, stdout when omitted")
+
+	_ = cmdInstance.MarkFlagRequired("wallet")
+	_ = cmdInstance.MarkFlagRequired("from")
+	_ = cmdInstance.MarkFlagRequired("to")
+
+	return cmdInstance
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/sumup-oss/go-pkgs/errors"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+)
+
+// loadTenant returns the tenant with id from the tenants configuration, the default tenant when id is empty.
+func loadTenant(cfg config.Tenancy, id string) (*tenant.Tenant, error) {
+	tenants, err := tenant.LoadRegistry(cfg.ConfigFile, cfg.DefaultTenant)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load tenants")
+	}
+
+	if id == "" {
+		id = cfg.DefaultTenant
+	}
+
+	t, err := tenants.ByID(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "unknown tenant %s", id)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+type Batch struct {
+	// WorkerEnabled processes the submitted batches within the `api` process.
+	WorkerEnabled bool `default:"true" envconfig:"BATCH_WORKER_ENABLED"`
+
+	// PollInterval is how often the worker looks for pending batches when idle.
+	PollInterval time.Duration `default:"1s" envconfig:"BATCH_POLL_INTERVAL"`
+
+	// Lease is how long a batch may go without progress before another worker takes it over.
+	Lease time.Duration `default:"1m" envconfig:"BATCH_LEASE"`
+
+	// MaxAttempts is how many times a batch interrupted by an error is run before it fails.
+	MaxAttempts int `default:"5" envconfig:"BATCH_MAX_ATTEMPTS"`
+
+	// MaxOperations is the largest number of operations of a batch.
+	MaxOperations int `default:"10000" envconfig:"BATCH_MAX_OPERATIONS"`
+
+	// MaxFileSize is the largest batch request body accepted, JSON or CSV, in bytes.
+	MaxFileSize int64 `default:"10485760" envconfig:"BATCH_MAX_FILE_SIZE"`
+}
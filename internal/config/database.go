@@ -16,7 +16,7 @@ type Database struct {
 	Username string `default:"sumup" envconfig:"DB_USERNAME"`
 
 	// Password is the user password.
-	Password string `default:"sumup" envconfig:"DB_PASSWORD"`
+	Password string `default:"sumup" envconfig:"DB_PASSWORD" secret:"true"`
 
 	// SSLMode is the SSL mode to use when connecting to Database.
 	SSLMode string `default:"disable" envconfig:"DB_SSL_MODE"`
@@ -51,4 +51,11 @@ type Database struct {
 
 	// LogArgs specify whether the query arguments must be logged.
 	LogSQLArgs bool `default:"false" envconfig:"DB_LOG_SQL_ARGS"`
+
+	// LogSQLRedactedArgs is a comma-separated list of named query arguments whose values are never logged.
+	LogSQLRedactedArgs []string `default:"password,secret,token,api_key" envconfig:"DB_LOG_SQL_REDACTED_ARGS"`
+
+	// SlowQueryThreshold is the duration from which queries are logged at WARN, even when LogQueries is false.
+	// Zero disables the slow query log.
+	SlowQueryThreshold time.Duration `default:"500ms" envconfig:"DB_SLOW_QUERY_THRESHOLD"`
 }
@@ -0,0 +1,14 @@
+package config
+
+import "time"
+
+type Escrows struct {
+	// WorkerEnabled settles the expired escrows within the `api` process.
+	WorkerEnabled bool `default:"true" envconfig:"ESCROWS_WORKER_ENABLED"`
+
+	// PollInterval is how often the worker looks for expired escrows when idle.
+	PollInterval time.Duration `default:"10s" envconfig:"ESCROWS_POLL_INTERVAL"`
+
+	// RetryInterval is how long an expiry failing on a frozen or missing wallet waits before it is tried again.
+	RetryInterval time.Duration `default:"1h" envconfig:"ESCROWS_RETRY_INTERVAL"`
+}
@@ -0,0 +1,12 @@
+package config
+
+type Interest struct {
+	// Enabled accrues the interest of the wallets with a product for the previous day, pays the interest of the
+	// periods ending then and charges the wallets that ended it overdrawn, once a day within the `api` process.
+	// The `interest` command runs it on demand.
+	Enabled bool `default:"true" envconfig:"INTEREST_ENABLED"`
+
+	// Time is the UTC time of day, as `HH:MM`, the interest of the previous day is accrued at. It should come
+	// after SNAPSHOTS_TIME, so the accruals read the balances from the snapshots.
+	Time string `default:"00:30" envconfig:"INTEREST_TIME"`
+}
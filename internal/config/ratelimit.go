@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+)
+
+const (
+	defaultRateLimitKey       = "client"
+	defaultRateLimitAlgorithm = "token_bucket"
+)
+
+var (
+	rateLimitKeys       = []string{"client", "api_key", "ip", "wallet"}
+	rateLimitAlgorithms = []string{"token_bucket", "sliding_window"}
+)
+
+type RateLimitRule struct {
+	Method    string
+	Pattern   string
+	Limit     int
+	Window    time.Duration
+	Key       string
+	Algorithm string
+}
+
+// RateLimitRules is a `;`-separated list of rules in the form
+// `<METHOD> <route pattern> <limit>/<window> [client|api_key|ip|wallet] [token_bucket|sliding_window]`,
+// e.g. `POST /v1/wallets/{id}/withdraw 10/1m wallet sliding_window`.
+type RateLimitRules []RateLimitRule
+
+func (r RateLimitRule) String() string {
+	return fmt.Sprintf("%s %s %d/%s %s %s", r.Method, r.Pattern, r.Limit, r.Window, r.Key, r.Algorithm)
+}
+
+func (r RateLimitRules) String() string {
+	rules := make([]string, 0, len(r))
+	for _, rule := range r {
+		rules = append(rules, rule.String())
+	}
+
+	return strings.Join(rules, ";")
+}
+
+// Decode implements envconfig.Decoder.
+func (r *RateLimitRules) Decode(value string) error {
+	var rules RateLimitRules
+
+	for _, rawRule := range strings.Split(value, ";") {
+		fields := strings.Fields(rawRule)
+		if len(fields) == 0 {
+			continue
+		}
+
+		rule, err := parseRateLimitRule(fields)
+		if err != nil {
+			return errors.Wrap(err, "invalid rate limit rule %q", strings.TrimSpace(rawRule))
+		}
+
+		rules = append(rules, rule)
+	}
+
+	*r = rules
+
+	return nil
+}
+
+func parseRateLimitRule(fields []string) (RateLimitRule, error) {
+	if len(fields) < 3 || len(fields) > 5 {
+		return RateLimitRule{}, errors.New("expected `<METHOD> <pattern> <limit>/<window> [key] [algorithm]`")
+	}
+
+	rawLimit, rawWindow, ok := strings.Cut(fields[2], "/")
+	if !ok {
+		return RateLimitRule{}, errors.New("quota must be in the form <limit>/<window>")
+	}
+
+	limit, err := strconv.Atoi(rawLimit)
+	if err != nil || limit <= 0 {
+		return RateLimitRule{}, errors.New("limit must be a positive integer")
+	}
+
+	window, err := time.ParseDuration(rawWindow)
+	if err != nil || window <= 0 {
+		return RateLimitRule{}, errors.New("window must be a positive duration")
+	}
+
+	rule := RateLimitRule{
+		Method:    strings.ToUpper(fields[0]),
+		Pattern:   fields[1],
+		Limit:     limit,
+		Window:    window,
+		Key:       defaultRateLimitKey,
+		Algorithm: defaultRateLimitAlgorithm,
+	}
+
+	if len(fields) > 3 {
+		rule.Key = fields[3]
+		if !contains(rateLimitKeys, rule.Key) {
+			return RateLimitRule{}, errors.New("key must be one of %s", strings.Join(rateLimitKeys, ", "))
+		}
+	}
+
+	if len(fields) > 4 {
+		rule.Algorithm = fields[4]
+		if !contains(rateLimitAlgorithms, rule.Algorithm) {
+			return RateLimitRule{}, errors.New("algorithm must be one of %s", strings.Join(rateLimitAlgorithms, ", "))
+		}
+	}
+
+	return rule, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
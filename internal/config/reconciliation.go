@@ -0,0 +1,16 @@
+package config
+
+type Reconciliation struct {
+	// Enabled runs the reconciliation daily within the `api` process. The `reconcile` command runs it on demand.
+	Enabled bool `default:"false" envconfig:"RECONCILIATION_ENABLED"`
+
+	// Time is the UTC time of day, as `HH:MM`, the scheduled reconciliation runs at.
+	Time string `default:"02:00" envconfig:"RECONCILIATION_TIME"`
+
+	// Freeze freezes the wallets whose balance does not reconcile during scheduled runs.
+	Freeze bool `default:"false" envconfig:"RECONCILIATION_FREEZE"`
+
+	// ReportDir is the directory the reports of scheduled runs are written to as JSON.
+	// Leave empty to only log the mismatches.
+	ReportDir string `default:"" envconfig:"RECONCILIATION_REPORT_DIR"`
+}
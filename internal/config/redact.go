@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const redactedValue = "[REDACTED]"
+
+// Redacted returns the configuration as a map keyed by environment variable name, with the values of fields
+// tagged `secret:"true"` replaced, so it can be shown to operators.
+func Redacted(cfg interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	redact(reflect.Indirect(reflect.ValueOf(cfg)), result)
+
+	return result
+}
+
+func redact(value reflect.Value, result map[string]interface{}) {
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		fieldValue := value.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Tag.Get("envconfig")
+		if name == "" {
+			if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+				redact(fieldValue, result)
+			}
+
+			continue
+		}
+
+		if field.Tag.Get("secret") == "true" {
+			result[name] = redactedValue
+			continue
+		}
+
+		if stringer, ok := fieldValue.Interface().(fmt.Stringer); ok {
+			result[name] = stringer.String()
+			continue
+		}
+
+		result[name] = fieldValue.Interface()
+	}
+}
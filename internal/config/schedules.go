@@ -0,0 +1,19 @@
+package config
+
+import "time"
+
+type Schedules struct {
+	// WorkerEnabled runs the due scheduled transfers within the `api` process.
+	WorkerEnabled bool `default:"true" envconfig:"SCHEDULES_WORKER_ENABLED"`
+
+	// PollInterval is how often the worker looks for due schedules when idle.
+	PollInterval time.Duration `default:"10s" envconfig:"SCHEDULES_POLL_INTERVAL"`
+
+	// RetryInterval is how long an occurrence failing for insufficient funds, or interrupted by an error
+	// unrelated to its transfer, waits before it is retried.
+	RetryInterval time.Duration `default:"1h" envconfig:"SCHEDULES_RETRY_INTERVAL"`
+
+	// MaxRetries is how many times an occurrence failing for insufficient funds is retried before it is skipped,
+	// and an interrupted occurrence before its schedule is cancelled.
+	MaxRetries int `default:"3" envconfig:"SCHEDULES_MAX_RETRIES"`
+}
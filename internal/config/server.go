@@ -12,9 +12,34 @@ type ServerConfig struct {
 	// The graceful shutdown is initiated with SIGINT or SIGTERM.
 	GracefulShutdownTimeout time.Duration `default:"60s" envconfig:"GRACEFUL_SHUTDOWN_TIMEOUT"`
 
-	// ListenAddress is the IP address and port where the metrics HTTP server listens at.
+	// ShutdownDrainDelay is how long the readiness probe fails after SIGINT or SIGTERM before the servers shut down,
+	// so load balancers stop routing traffic first.
+	ShutdownDrainDelay time.Duration `default:"5s" envconfig:"SHUTDOWN_DRAIN_DELAY"`
+
+	// ReadinessMaxBacklogAge is how long batches, schedules and escrow expiries may wait for their processors before
+	// the readiness probe fails. Zero disables the check.
+	ReadinessMaxBacklogAge time.Duration `default:"1h" envconfig:"READINESS_MAX_BACKLOG_AGE"`
+
+	// ListenAddress is the IP address and port where the API HTTP server listens at.
 	ListenAddress string `default:"0.0.0.0:8080" envconfig:"LISTEN_ADDRESS"`
 
+	// AdminListenAddress is the IP address and port where the admin HTTP server serving operational endpoints
+	// such as metrics listens at. It must not be exposed publicly.
+	AdminListenAddress string `default:"127.0.0.1:8081" envconfig:"ADMIN_LISTEN_ADDRESS"`
+
+	// OperatorHeader is the header identifying the operator calling the admin endpoints, e.g. when proposing or
+	// approving balance adjustments. It must be set by an authenticating proxy in front of the admin listener.
+	OperatorHeader string `default:"X-Operator-ID" envconfig:"OPERATOR_HEADER"`
+
+	// OperatorSignatureHeader carries the signature with which the authenticating proxy vouches for the operator
+	// header, see OperatorSigningKey.
+	OperatorSignatureHeader string `default:"X-Operator-Signature" envconfig:"OPERATOR_SIGNATURE_HEADER"`
+
+	// OperatorSigningKey is the key shared with the authenticating proxy to sign the operator header. Without it
+	// operators are not authenticated, and the adjustment endpoints, whose approver must differ from the proposer,
+	// are refused.
+	OperatorSigningKey string `default:"" envconfig:"OPERATOR_SIGNING_KEY" secret:"true"`
+
 	// ReadTimeout is the maximum duration for reading the entire request, including the body.
 	ReadTimeout time.Duration `default:"15s" envconfig:"READ_TIMEOUT"`
 
@@ -27,17 +52,19 @@ type ServerConfig struct {
 	// MaxHeaderBytes is the maximum number of bytes the server will read parsing the request header's keys and values.
 	MaxHeaderBytes int `default:"1000000" envconfig:"MAX_HEADER_BYTES"`
 
-	// CorsAllowedOrigins is a comma-separated list of origins allowed via CORS.
-	CorsAllowedMethods []string `default:"GET,POST,DELETE,OPTIONS" envconfig:"CORS_ALLOWED_METHODS"`
+	// CorsAllowedMethods is a comma-separated list of methods allowed via CORS.
+	CorsAllowedMethods []string `default:"GET,POST,PUT,PATCH,DELETE,OPTIONS" envconfig:"CORS_ALLOWED_METHODS"`
 
 	// CorsAllowedOrigins is a comma-separated list of origins allowed via CORS.
+	// Origins may contain `*` wildcards, e.g. `https://*.example.com`.
 	CorsAllowedOrigins []string `default:"http://*,https://*" envconfig:"CORS_ALLOWED_ORIGINS"`
 
-	// CorsAllowedHeaders is a comma-separated list of headers allowed via CORS.
-	CorsAllowedHeaders []string `default:"X-PINGOTHER,Accept,Authorization,Content-Type,X-CSRF-Token" envconfig:"CORS_ALLOWED_HEADERS"` //nolint:lll
+	// CorsAllowedHeaders is a comma-separated list of headers allowed via CORS, on top of the tenancy headers,
+	// see CorsHeaders.
+	CorsAllowedHeaders []string `default:"X-PINGOTHER,Accept,Authorization,Content-Type,X-CSRF-Token,If-Match,If-None-Match,X-Request-ID" envconfig:"CORS_ALLOWED_HEADERS"` //nolint:lll
 
 	// CorsExposedHeaders is a comma-separated list of headers exposed via CORS.
-	CorsExposedHeaders []string `default:"Link" envconfig:"CORS_EXPOSED_HEADERS"`
+	CorsExposedHeaders []string `default:"ETag,Location,Link,X-Request-ID" envconfig:"CORS_EXPOSED_HEADERS"`
 
 	// CorsAllowCredentials is a boolean value indicating whether the resource allows credentials.
 	CorsAllowCredentials bool `default:"true" envconfig:"CORS_ALLOW_CREDENTIALS"`
@@ -48,8 +75,30 @@ type ServerConfig struct {
 	// CorsDebug enables debug mode for CORS.
 	CorsDebug bool `default:"false" envconfig:"CORS_DEBUG"`
 
-	Log      Log
-	Database Database
+	// RateLimits are the per-route rate limits, see RateLimitRules for the format. Empty disables rate limiting.
+	RateLimits RateLimitRules `default:"POST /v1/wallets/{id}/withdraw 30/1m client;POST /v1/wallets/{id}/withdraw 10/1m wallet sliding_window" envconfig:"RATE_LIMITS"` //nolint:lll
+
+	Log            Log
+	Database       Database
+	Tenancy        Tenancy
+	Tracing        Tracing
+	TLS            TLS
+	Reconciliation Reconciliation
+	Settlement     Settlement
+	Snapshots      Snapshots
+	Batch          Batch
+	Schedules      Schedules
+	Interest       Interest
+	Escrows        Escrows
+}
+
+// CorsHeaders returns the headers allowed via CORS: CorsAllowedHeaders and the tenant, API key and user headers
+// clients identify themselves with, whatever their configured names.
+func (c *ServerConfig) CorsHeaders() []string {
+	headers := make([]string, 0, len(c.CorsAllowedHeaders)+3)
+	headers = append(headers, c.CorsAllowedHeaders...)
+
+	return append(headers, c.Tenancy.Header, c.Tenancy.APIKeyHeader, c.Tenancy.UserHeader)
 }
 
 func NewServerConfig() (*ServerConfig, error) {
@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+type Settlement struct {
+	// DateTolerance is how far from the booking date of a settlement line a deposit without a reference may have
+	// been made to match it.
+	DateTolerance time.Duration `default:"72h" envconfig:"SETTLEMENT_DATE_TOLERANCE"`
+
+	// AmountTolerance is the largest difference between the amounts of a settlement line and a matching deposit.
+	AmountTolerance float64 `default:"0" envconfig:"SETTLEMENT_AMOUNT_TOLERANCE"`
+
+	// MaxFileSize is the largest settlement file accepted, in bytes.
+	MaxFileSize int64 `default:"10485760" envconfig:"SETTLEMENT_MAX_FILE_SIZE"`
+}
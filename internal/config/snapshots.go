@@ -0,0 +1,10 @@
+package config
+
+type Snapshots struct {
+	// Enabled snapshots the balance of every wallet at the end of each day within the `api` process.
+	// The `snapshot` command takes them on demand.
+	Enabled bool `default:"true" envconfig:"SNAPSHOTS_ENABLED"`
+
+	// Time is the UTC time of day, as `HH:MM`, the snapshots of the previous day are taken at.
+	Time string `default:"00:15" envconfig:"SNAPSHOTS_TIME"`
+}
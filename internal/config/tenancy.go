@@ -0,0 +1,21 @@
+package config
+
+type Tenancy struct {
+	// ConfigFile is the path to a JSON file describing the tenants, their API keys, allowed currencies and limits.
+	// When empty, a single unrestricted tenant named DefaultTenant is used.
+	ConfigFile string `default:"" envconfig:"TENANTS_CONFIG_FILE"`
+
+	// DefaultTenant is the tenant used for requests carrying neither an API key nor a tenant header.
+	// Leave empty to reject such requests.
+	DefaultTenant string `default:"default" envconfig:"DEFAULT_TENANT"`
+
+	// Header is the request header carrying the tenant identifier.
+	Header string `default:"X-Tenant-ID" envconfig:"TENANT_HEADER"`
+
+	// APIKeyHeader is the request header carrying the tenant API key.
+	APIKeyHeader string `default:"X-API-Key" envconfig:"API_KEY_HEADER"`
+
+	// UserHeader is the request header naming the end user on whose behalf the client acts, as recorded in the
+	// audit log. It must be set by the gateway in front of the API.
+	UserHeader string `default:"X-User-ID" envconfig:"USER_HEADER"`
+}
@@ -0,0 +1,29 @@
+package config
+
+import "time"
+
+type TLS struct {
+	// CertFile is the PEM certificate (chain) of the API server. TLS is enabled when both CertFile and KeyFile are set.
+	CertFile string `default:"" envconfig:"TLS_CERT_FILE"`
+
+	// KeyFile is the PEM private key of CertFile.
+	KeyFile string `default:"" envconfig:"TLS_KEY_FILE"`
+
+	// MinVersion is the minimum accepted TLS version with possible values: `1.0|1.1|1.2|1.3`.
+	MinVersion string `default:"1.2" envconfig:"TLS_MIN_VERSION"`
+
+	// ClientCAFile is a PEM bundle of CAs. When set, clients must authenticate with a certificate signed by one of them.
+	ClientCAFile string `default:"" envconfig:"TLS_CLIENT_CA_FILE"`
+
+	// ClientAllowedSubjects is a comma-separated list of client certificate common names or distinguished names
+	// accepted for mutual TLS. Empty accepts any certificate signed by the client CAs.
+	ClientAllowedSubjects []string `default:"" envconfig:"TLS_CLIENT_ALLOWED_SUBJECTS"`
+
+	// ReloadInterval is how often the certificate files are checked for changes.
+	ReloadInterval time.Duration `default:"10s" envconfig:"TLS_RELOAD_INTERVAL"`
+}
+
+// Enabled reports whether the API server terminates TLS.
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
@@ -0,0 +1,22 @@
+package config
+
+type Tracing struct {
+	// Exporter is where finished spans are sent, one of `none|otlp|stdout|file`.
+	Exporter string `default:"none" envconfig:"TRACING_EXPORTER"`
+
+	// OTLPEndpoint is the `host:port` of the OTLP/HTTP collector.
+	OTLPEndpoint string `default:"localhost:4318" envconfig:"TRACING_OTLP_ENDPOINT"`
+
+	// OTLPInsecure disables TLS towards the OTLP collector.
+	OTLPInsecure bool `default:"true" envconfig:"TRACING_OTLP_INSECURE"`
+
+	// FilePath is the file the `file` exporter appends the spans to as JSON.
+	FilePath string `default:"traces.json" envconfig:"TRACING_FILE_PATH"`
+
+	// ServiceName is the `service.name` resource attribute of the spans.
+	ServiceName string `default:"wallet" envconfig:"TRACING_SERVICE_NAME"`
+
+	// SampleRatio is the ratio of new traces to sample, between 0 and 1. Traces started upstream follow
+	// the sampling decision of the caller.
+	SampleRatio float64 `default:"1" envconfig:"TRACING_SAMPLE_RATIO"`
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/logger"
+)
+
+// QueryLogOptions configures the logging of the queries executed through the database handle.
+type QueryLogOptions struct {
+	// Enabled logs every query at INFO.
+	Enabled bool
+	// LogSQL adds the SQL text to the query logs.
+	LogSQL bool
+	// LogArgs adds the query arguments to the query logs, with RedactedArgs masked.
+	LogArgs bool
+	// RedactedArgs are the case-insensitive names of the named arguments whose values are never logged.
+	RedactedArgs []string
+	// SlowQueryThreshold logs queries taking at least this long at WARN, even when Enabled is false.
+	// Zero disables the slow query log.
+	SlowQueryThreshold time.Duration
+}
+
+// Open opens a database handle whose connections log their queries according to options.
+func Open(
+	log logger.StructuredLogger,
+	driverName string,
+	dsn string,
+	options QueryLogOptions,
+) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.Enabled && options.SlowQueryThreshold <= 0 {
+		return db, nil
+	}
+
+	var connector driver.Connector
+
+	driverCtx, ok := db.Driver().(driver.DriverContext)
+	if ok {
+		connector, err = driverCtx.OpenConnector(dsn)
+		if err != nil {
+			_ = db.Close()
+
+			return nil, errors.Wrap(err, "failed to open %s connector", driverName)
+		}
+	} else {
+		connector = &dsnConnector{dsn: dsn, driver: db.Driver()}
+	}
+
+	_ = db.Close()
+
+	return sql.OpenDB(&loggingConnector{
+		connector: connector,
+		logger:    newQueryLogger(log, options),
+	}), nil
+}
+
+// dsnConnector adapts drivers not implementing driver.DriverContext.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c *dsnConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
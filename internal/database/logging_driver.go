@@ -0,0 +1,316 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	_ driver.Connector          = (*loggingConnector)(nil)
+	_ driver.Conn               = (*loggingConn)(nil)
+	_ driver.ConnBeginTx        = (*loggingConn)(nil)
+	_ driver.ConnPrepareContext = (*loggingConn)(nil)
+	_ driver.ExecerContext      = (*loggingConn)(nil)
+	_ driver.QueryerContext     = (*loggingConn)(nil)
+	_ driver.Pinger             = (*loggingConn)(nil)
+	_ driver.SessionResetter    = (*loggingConn)(nil)
+	_ driver.Validator          = (*loggingConn)(nil)
+	_ driver.NamedValueChecker  = (*loggingConn)(nil)
+	_ driver.StmtExecContext    = (*loggingStmt)(nil)
+	_ driver.StmtQueryContext   = (*loggingStmt)(nil)
+	_ driver.RowsNextResultSet  = (*loggingRows)(nil)
+)
+
+type loggingConnector struct {
+	connector driver.Connector
+	logger    *queryLogger
+}
+
+func (c *loggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &loggingConn{conn: conn, logger: c.logger}, nil
+}
+
+func (c *loggingConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+type loggingConn struct {
+	conn   driver.Conn
+	logger *queryLogger
+}
+
+func (c *loggingConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *loggingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &loggingStmt{stmt: stmt, query: query, logger: c.logger}, nil
+}
+
+func (c *loggingConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *loggingConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *loggingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+
+	return c.conn.Begin() //nolint:staticcheck
+}
+
+// ExecContext executes directly on drivers supporting it, otherwise database/sql falls back to a prepared statement.
+func (c *loggingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	c.logger.logQuery(ctx, query, args, time.Since(start), rowsAffected(result, err), err)
+
+	return result, err
+}
+
+// QueryContext queries directly on drivers supporting it, otherwise database/sql falls back to a prepared statement.
+func (c *loggingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		c.logger.logQuery(ctx, query, args, time.Since(start), -1, err)
+
+		return nil, err
+	}
+
+	return newLoggingRows(ctx, rows, query, args, start, c.logger), nil
+}
+
+func (c *loggingConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *loggingConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *loggingConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+
+	return true
+}
+
+func (c *loggingConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	return driver.ErrSkip
+}
+
+// NOTE: loggingStmt does not implement driver.NamedValueChecker, so database/sql
+// checks the arguments with the one of the connection.
+type loggingStmt struct {
+	stmt   driver.Stmt
+	query  string
+	logger *queryLogger
+}
+
+func (s *loggingStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *loggingStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *loggingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *loggingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *loggingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var (
+		result driver.Result
+		err    error
+	)
+
+	if execer, ok := s.stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = s.stmt.Exec(values(args)) //nolint:staticcheck
+	}
+
+	s.logger.logQuery(ctx, s.query, args, time.Since(start), rowsAffected(result, err), err)
+
+	return result, err
+}
+
+func (s *loggingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var (
+		rows driver.Rows
+		err  error
+	)
+
+	if queryer, ok := s.stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.stmt.Query(values(args)) //nolint:staticcheck
+	}
+
+	if err != nil {
+		s.logger.logQuery(ctx, s.query, args, time.Since(start), -1, err)
+
+		return nil, err
+	}
+
+	return newLoggingRows(ctx, rows, s.query, args, start, s.logger), nil
+}
+
+// loggingRows logs the query once the rows are closed, with the number of rows read.
+type loggingRows struct {
+	driver.Rows
+
+	ctx    context.Context //nolint:containedctx
+	query  string
+	args   []driver.NamedValue
+	start  time.Time
+	count  int64
+	err    error
+	logger *queryLogger
+}
+
+func newLoggingRows(
+	ctx context.Context,
+	rows driver.Rows,
+	query string,
+	args []driver.NamedValue,
+	start time.Time,
+	logger *queryLogger,
+) *loggingRows {
+	return &loggingRows{
+		Rows:   rows,
+		ctx:    ctx,
+		query:  query,
+		args:   args,
+		start:  start,
+		logger: logger,
+	}
+}
+
+func (r *loggingRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+
+	switch {
+	case err == nil:
+		r.count++
+	case !errors.Is(err, io.EOF):
+		r.err = err
+	}
+
+	return err
+}
+
+func (r *loggingRows) HasNextResultSet() bool {
+	if rows, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rows.HasNextResultSet()
+	}
+
+	return false
+}
+
+func (r *loggingRows) NextResultSet() error {
+	if rows, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rows.NextResultSet()
+	}
+
+	return io.EOF
+}
+
+func (r *loggingRows) Close() error {
+	err := r.Rows.Close()
+	r.logger.logQuery(r.ctx, r.query, r.args, time.Since(r.start), r.count, r.err)
+
+	return err
+}
+
+func rowsAffected(result driver.Result, err error) int64 {
+	if err != nil || result == nil {
+		return -1
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return -1
+	}
+
+	return rows
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	result := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		result[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+
+	return result
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	result := make([]driver.Value, len(args))
+	for i, arg := range args {
+		result[i] = arg.Value
+	}
+
+	return result
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+)
+
+const (
+	redactedValue     = "[REDACTED]"
+	maxLoggedArgBytes = 64
+)
+
+type queryLogger struct {
+	log          logger.StructuredLogger
+	options      QueryLogOptions
+	redactedArgs map[string]struct{}
+}
+
+func newQueryLogger(log logger.StructuredLogger, options QueryLogOptions) *queryLogger {
+	redactedArgs := make(map[string]struct{}, len(options.RedactedArgs))
+	for _, name := range options.RedactedArgs {
+		redactedArgs[strings.ToLower(strings.TrimSpace(name))] = struct{}{}
+	}
+
+	return &queryLogger{
+		log:          log,
+		options:      options,
+		redactedArgs: redactedArgs,
+	}
+}
+
+// logQuery logs one executed query. rows is the number of affected or returned rows, -1 when unknown.
+func (l *queryLogger) logQuery(
+	ctx context.Context,
+	query string,
+	args []driver.NamedValue,
+	duration time.Duration,
+	rows int64,
+	err error,
+) {
+	slow := l.options.SlowQueryThreshold > 0 && duration >= l.options.SlowQueryThreshold
+	if !l.options.Enabled && !slow {
+		return
+	}
+
+	fields := []zap.Field{zap.Duration("duration", duration)}
+
+	if rows >= 0 {
+		fields = append(fields, zap.Int64("rows", rows))
+	}
+
+	if l.options.LogSQL {
+		fields = append(fields, zap.String("sql", strings.Join(strings.Fields(query), " ")))
+	}
+
+	if l.options.LogArgs && len(args) > 0 {
+		fields = append(fields, zap.Strings("args", l.formatArgs(args)))
+	}
+
+	log := logging.FromContext(ctx, l.log)
+
+	switch {
+	case err != nil && err != driver.ErrSkip:
+		log.Error("SQL query failed", append(fields, logger.ErrorField(err))...)
+	case slow:
+		log.Warn("Slow SQL query", append(fields, zap.Duration("threshold", l.options.SlowQueryThreshold))...)
+	default:
+		log.Info("SQL query", fields...)
+	}
+}
+
+func (l *queryLogger) formatArgs(args []driver.NamedValue) []string {
+	result := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		name := arg.Name
+		if name == "" {
+			name = fmt.Sprintf("$%d", arg.Ordinal)
+		}
+
+		result = append(result, name+"="+l.formatArg(arg))
+	}
+
+	return result
+}
+
+func (l *queryLogger) formatArg(arg driver.NamedValue) string {
+	if _, ok := l.redactedArgs[strings.ToLower(arg.Name)]; ok {
+		return redactedValue
+	}
+
+	switch value := arg.Value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return fmt.Sprintf("<%d bytes>", len(value))
+	case string:
+		return truncate(value)
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	default:
+		return truncate(fmt.Sprint(value))
+	}
+}
+
+func truncate(value string) string {
+	if len(value) <= maxLoggedArgBytes {
+		return value
+	}
+
+	cut := maxLoggedArgBytes
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+
+	return value[:cut] + "..."
+}
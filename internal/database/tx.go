@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+)
+
+// Querier is implemented by both *sql.DB and *sql.Tx, so repositories can run the same queries
+// inside and outside of a transaction.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txContextKey struct{}
+
+// transaction is the transaction carried by the context of RunInTx with the functions to run once it commits.
+type transaction struct {
+	*sql.Tx
+	afterCommit []func()
+}
+
+// RunInTx runs fn in a transaction carried by the context passed to fn. The transaction is committed when fn
+// returns nil and rolled back otherwise. Calls nested in fn join the outer transaction.
+func RunInTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*transaction); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	t := &transaction{Tx: tx}
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, t)); err != nil {
+		_ = tx.Rollback()
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	for _, afterCommit := range t.afterCommit {
+		afterCommit()
+	}
+
+	return nil
+}
+
+// AfterCommit runs fn once the transaction started by RunInTx for ctx is committed, right away outside of a
+// transaction. fn never runs when the transaction is rolled back, so metrics and logs of changes made by calls
+// nested in a transaction only report the changes that were kept.
+func AfterCommit(ctx context.Context, fn func()) {
+	if t, ok := ctx.Value(txContextKey{}).(*transaction); ok {
+		t.afterCommit = append(t.afterCommit, fn)
+		return
+	}
+
+	fn()
+}
+
+// Conn returns the transaction started by RunInTx for ctx, or db outside of a transaction.
+func Conn(ctx context.Context, db *sql.DB) Querier {
+	if t, ok := ctx.Value(txContextKey{}).(*transaction); ok {
+		return t.Tx
+	}
+
+	return db
+}
@@ -0,0 +1,102 @@
+// Package escrow locks payments between two wallets of a tenant until they are settled, e.g. the payment of a
+// marketplace buyer until the delivery is confirmed. Creating an escrow moves the money from the payer wallet into
+// the escrow account of the tenant, settling it releases the money to the payee, refunds it to the payer or splits
+// it between them. Escrows past their expiry are settled by an Expirer.
+package escrow
+
+import (
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+)
+
+// Status is the state of an escrow.
+type Status string
+
+const (
+	// StatusHeld escrows hold the money of the payer until they are settled.
+	StatusHeld Status = "held"
+	// StatusReleased escrows paid the whole amount to the payee.
+	StatusReleased Status = "released"
+	// StatusRefunded escrows paid the whole amount back to the payer.
+	StatusRefunded Status = "refunded"
+	// StatusSplit escrows paid part of the amount to the payee and the rest back to the payer.
+	StatusSplit Status = "split"
+	// StatusExpired escrows were settled with their expiry action once they expired.
+	StatusExpired Status = "expired"
+)
+
+// ExpiryAction tells how an escrow still held at its expiry is settled.
+type ExpiryAction string
+
+const (
+	// ExpiryRefund pays the amount back to the payer, e.g. when the delivery was never confirmed.
+	ExpiryRefund ExpiryAction = "refund"
+	// ExpiryRelease pays the amount to the payee, e.g. when the buyer did not dispute the delivery in time.
+	ExpiryRelease ExpiryAction = "release"
+)
+
+type Escrow struct {
+	ID            string  `json:"id" db:"id"`
+	TenantID      string  `json:"tenant_id" db:"tenant_id"`
+	PayerWalletID string  `json:"payer_wallet_id" db:"payer_wallet_id"`
+	PayeeWalletID string  `json:"payee_wallet_id" db:"payee_wallet_id"`
+	Amount        float64 `json:"amount" db:"amount"`
+	Currency      string  `json:"currency" db:"currency"`
+	Reference     string  `json:"reference,omitempty" db:"reference"`
+	Status        Status  `json:"status" db:"status"`
+	// Released and Refunded are the parts of Amount paid to the payee and back to the payer, both 0 while held.
+	Released float64 `json:"released" db:"released"`
+	Refunded float64 `json:"refunded" db:"refunded"`
+	// ExpiresAt is when an escrow still held is settled with OnExpiry, nil for escrows that never expire.
+	ExpiresAt *time.Time   `json:"expires_at,omitempty" db:"expires_at"`
+	OnExpiry  ExpiryAction `json:"on_expiry" db:"on_expiry"`
+	// RetryAt is when a failed expiry is tried again, LastError tells why it failed.
+	RetryAt           *time.Time  `json:"retry_at,omitempty" db:"retry_at"`
+	LastError         string      `json:"last_error,omitempty" db:"last_error"`
+	HoldTransactionID string      `json:"hold_transaction_id" db:"hold_transaction_id"`
+	CreatedBy         audit.Actor `json:"created_by"`
+	CreatedAt         time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// Event is a change of the state of an escrow, from its creation to its settlement.
+type Event struct {
+	ID       string `json:"id" db:"id"`
+	EscrowID string `json:"escrow_id" db:"escrow_id"`
+	// FromStatus is empty for the creation of the escrow.
+	FromStatus Status  `json:"from_status,omitempty" db:"from_status"`
+	ToStatus   Status  `json:"to_status" db:"to_status"`
+	Released   float64 `json:"released" db:"released"`
+	Refunded   float64 `json:"refunded" db:"refunded"`
+	// PayerTransactionID is the hold or the refund of the payer wallet, PayeeTransactionID the release to the
+	// payee wallet.
+	PayerTransactionID string      `json:"payer_transaction_id,omitempty" db:"payer_transaction_id"`
+	PayeeTransactionID string      `json:"payee_transaction_id,omitempty" db:"payee_transaction_id"`
+	Reason             string      `json:"reason,omitempty" db:"reason"`
+	Actor              audit.Actor `json:"actor"`
+	CreatedAt          time.Time   `json:"created_at" db:"created_at"`
+}
+
+// Instruction is a payment to hold in escrow.
+type Instruction struct {
+	PayerWalletID string
+	PayeeWalletID string
+	Amount        float64
+	Reference     string
+	ExpiresAt     *time.Time
+	// OnExpiry defaults to ExpiryRefund.
+	OnExpiry ExpiryAction
+}
+
+// Filter narrows down the escrows returned by List. Empty fields match every escrow.
+type Filter struct {
+	// WalletID matches the escrows paid by or to the wallet.
+	WalletID string
+	Status   Status
+}
+
+// expired tells whether the escrow is past its expiry at now.
+func (e *Escrow) expired(now time.Time) bool {
+	return e.ExpiresAt != nil && !e.ExpiresAt.After(now)
+}
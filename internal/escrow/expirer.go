@@ -0,0 +1,149 @@
+package escrow
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+// DefaultRetryInterval is how long a failed expiry waits before it is tried again.
+const DefaultRetryInterval = time.Hour
+
+// payoutErrors are the errors failing an expiry until it is tried again. Other errors leave the escrow due, so
+// it is expired again on the next poll.
+var payoutErrors = []error{
+	wallet.ErrWalletNotFound,
+	wallet.ErrWalletFrozen,
+	tenant.ErrTenantNotFound,
+}
+
+// Expirer settles the expired escrows of every tenant with their expiry action, see Service.Expire.
+type Expirer struct {
+	repo          Repository
+	escrows       Service
+	tenants       *tenant.Registry
+	retryInterval time.Duration
+}
+
+type ExpirerOption func(*Expirer)
+
+// WithRetryInterval sets how long a failed expiry waits before it is tried again, DefaultRetryInterval otherwise.
+func WithRetryInterval(interval time.Duration) ExpirerOption {
+	return func(e *Expirer) {
+		e.retryInterval = interval
+	}
+}
+
+func NewExpirer(repo Repository, escrows Service, tenants *tenant.Registry, options ...ExpirerOption) *Expirer {
+	e := &Expirer{
+		repo:          repo,
+		escrows:       escrows,
+		tenants:       tenants,
+		retryInterval: DefaultRetryInterval,
+	}
+
+	for _, opt := range options {
+		opt(e)
+	}
+
+	return e
+}
+
+// ExpireNext settles the escrow expired the longest. It returns false when no escrow is due.
+func (e *Expirer) ExpireNext(ctx context.Context) (bool, error) {
+	var (
+		due     *Escrow
+		failure error
+	)
+
+	err := e.repo.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		due, err = e.repo.LockDue(ctx, time.Now().UTC())
+		if err != nil || due == nil {
+			return err
+		}
+
+		ctx, err = e.escrowContext(ctx, due)
+		if err == nil {
+			_, err = e.escrows.Expire(ctx, due.ID)
+		}
+
+		if isPayoutError(err) {
+			failure = err
+		}
+
+		return err
+	})
+
+	if failure == nil {
+		return due != nil, err
+	}
+
+	// NOTE: The failed payout was rolled back with the transaction, its failure is recorded in another one.
+	return true, e.repo.WithinTx(ctx, func(ctx context.Context) error {
+		ctx, _ = e.escrowContext(ctx, due)
+
+		escrow, err := e.repo.GetForUpdate(ctx, due.ID)
+		if err != nil {
+			return err
+		}
+
+		// Another expirer settled the escrow, or recorded the failure, in between.
+		if escrow.Status != StatusHeld || escrow.UpdatedAt.After(due.UpdatedAt) {
+			return nil
+		}
+
+		retryAt := time.Now().UTC().Add(e.retryInterval)
+
+		escrow.RetryAt = &retryAt
+		escrow.LastError = failure.Error()
+
+		if err := e.repo.Update(ctx, escrow); err != nil {
+			return err
+		}
+
+		logging.FromContext(ctx, nil).Warn(
+			"Escrow expiry failed",
+			logger.ErrorField(failure),
+			zap.Timep("retry_at", escrow.RetryAt),
+		)
+
+		return nil
+	})
+}
+
+// escrowContext returns ctx with the logger and tenant of the escrow. Expiries are audited on behalf of the
+// system. When the tenant was removed from the configuration, the tenant of ctx only scopes the escrow queries.
+func (e *Expirer) escrowContext(ctx context.Context, escrow *Escrow) (context.Context, error) {
+	log := logging.FromContext(ctx, nil).With(
+		zap.String("escrow_id", escrow.ID),
+		zap.String("tenant_id", escrow.TenantID),
+	)
+
+	ctx = logging.NewContext(ctx, log)
+
+	escrowTenant, err := e.tenants.ByID(escrow.TenantID)
+	if err != nil {
+		return tenant.NewContext(ctx, &tenant.Tenant{ID: escrow.TenantID}), err
+	}
+
+	return tenant.NewContext(ctx, escrowTenant), nil
+}
+
+func isPayoutError(err error) bool {
+	for _, payoutErr := range payoutErrors {
+		if errors.Is(err, payoutErr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,415 @@
+package escrow
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/database"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+const escrowColumns = `id, tenant_id, payer_wallet_id, payee_wallet_id, amount, currency, reference, status, released,
+                  refunded, expires_at, on_expiry, retry_at, last_error, hold_transaction_id, actor_type, actor_id,
+                  actor_ip, created_at, updated_at`
+
+const eventColumns = `id, escrow_id, from_status, to_status, released, refunded, payer_transaction_id,
+                  payee_transaction_id, reason, actor_type, actor_id, actor_ip, created_at`
+
+type Repository interface {
+	// WithinTx runs fn in a database transaction, see database.RunInTx.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Create(ctx context.Context, escrow *Escrow) error
+	Get(ctx context.Context, id string) (*Escrow, error)
+	// GetForUpdate returns the escrow locked until the end of the transaction of ctx.
+	GetForUpdate(ctx context.Context, id string) (*Escrow, error)
+	// List returns the escrows matching the filter, newest first.
+	List(ctx context.Context, filter Filter) ([]*Escrow, error)
+	// Update saves the status, settled amounts and expiry attempts of the escrow.
+	Update(ctx context.Context, escrow *Escrow) error
+	// LockDue returns the held escrow of any tenant expired the longest at now, locked until the end of the
+	// transaction of ctx, nil when there is none. Escrows locked by other transactions are skipped.
+	LockDue(ctx context.Context, now time.Time) (*Escrow, error)
+	CreateEvent(ctx context.Context, event *Event) error
+	// ListEvents returns the events of the escrow, oldest first.
+	ListEvents(ctx context.Context, escrowID string) ([]*Event, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.RunInTx(ctx, r.db, fn)
+}
+
+// currentTenantID returns the tenant every query must be scoped to.
+func currentTenantID(ctx context.Context) (string, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return t.ID, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEscrow(row rowScanner) (*Escrow, error) {
+	var (
+		escrow    Escrow
+		reference sql.NullString
+		expiresAt sql.NullTime
+		retryAt   sql.NullTime
+		lastError sql.NullString
+		actorID   sql.NullString
+		actorIP   sql.NullString
+	)
+
+	err := row.Scan(&escrow.ID, &escrow.TenantID, &escrow.PayerWalletID, &escrow.PayeeWalletID, &escrow.Amount,
+		&escrow.Currency, &reference, &escrow.Status, &escrow.Released, &escrow.Refunded, &expiresAt,
+		&escrow.OnExpiry, &retryAt, &lastError, &escrow.HoldTransactionID, &escrow.CreatedBy.Type, &actorID,
+		&actorIP, &escrow.CreatedAt, &escrow.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	escrow.Reference = reference.String
+	escrow.ExpiresAt = timePtr(expiresAt)
+	escrow.RetryAt = timePtr(retryAt)
+	escrow.LastError = lastError.String
+	escrow.CreatedBy.ID = actorID.String
+	escrow.CreatedBy.IP = actorIP.String
+
+	return &escrow, nil
+}
+
+func scanEvent(row rowScanner) (*Event, error) {
+	var (
+		event              Event
+		fromStatus         sql.NullString
+		payerTransactionID sql.NullString
+		payeeTransactionID sql.NullString
+		reason             sql.NullString
+		actorID            sql.NullString
+		actorIP            sql.NullString
+	)
+
+	err := row.Scan(&event.ID, &event.EscrowID, &fromStatus, &event.ToStatus, &event.Released, &event.Refunded,
+		&payerTransactionID, &payeeTransactionID, &reason, &event.Actor.Type, &actorID, &actorIP, &event.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	event.FromStatus = Status(fromStatus.String)
+	event.PayerTransactionID = payerTransactionID.String
+	event.PayeeTransactionID = payeeTransactionID.String
+	event.Reason = reason.String
+	event.Actor.ID = actorID.String
+	event.Actor.IP = actorIP.String
+
+	return &event, nil
+}
+
+func timePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Time
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullTime(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: *value, Valid: true}
+}
+
+func (r *repository) Create(ctx context.Context, escrow *Escrow) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	escrow.ID = uuid.New().String()
+	escrow.TenantID = tenantID
+
+	query := `INSERT INTO escrows (id, tenant_id, payer_wallet_id, payee_wallet_id, amount, currency, reference,
+                  status, released, refunded, expires_at, on_expiry, hold_transaction_id, actor_type, actor_id,
+                  actor_ip, created_at, updated_at)
+              VALUES (@id, @tenant_id, @payer_wallet_id, @payee_wallet_id, @amount, @currency, @reference, @status,
+                  0, 0, @expires_at, @on_expiry, @hold_transaction_id, @actor_type, @actor_id, @actor_ip,
+                  @created_at, @updated_at)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "escrow.Repository/Create", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("id", escrow.ID),
+		sql.Named("tenant_id", escrow.TenantID),
+		sql.Named("payer_wallet_id", escrow.PayerWalletID),
+		sql.Named("payee_wallet_id", escrow.PayeeWalletID),
+		sql.Named("amount", escrow.Amount),
+		sql.Named("currency", escrow.Currency),
+		sql.Named("reference", nullString(escrow.Reference)),
+		sql.Named("status", string(escrow.Status)),
+		sql.Named("expires_at", nullTime(escrow.ExpiresAt)),
+		sql.Named("on_expiry", string(escrow.OnExpiry)),
+		sql.Named("hold_transaction_id", escrow.HoldTransactionID),
+		sql.Named("actor_type", string(escrow.CreatedBy.Type)),
+		sql.Named("actor_id", nullString(escrow.CreatedBy.ID)),
+		sql.Named("actor_ip", nullString(escrow.CreatedBy.IP)),
+		sql.Named("created_at", escrow.CreatedAt),
+		sql.Named("updated_at", escrow.UpdatedAt),
+	)
+	if err != nil {
+		return errors.New("failed to insert escrow into database: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *repository) Get(ctx context.Context, id string) (_ *Escrow, err error) {
+	query := `SELECT ` + escrowColumns + `
+              FROM escrows WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "escrow.Repository/Get", query)
+	defer func() { tracing.End(span, err) }()
+
+	return r.get(ctx, query, id)
+}
+
+func (r *repository) GetForUpdate(ctx context.Context, id string) (_ *Escrow, err error) {
+	query := `SELECT ` + escrowColumns + `
+              FROM escrows WITH (UPDLOCK, ROWLOCK) WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "escrow.Repository/GetForUpdate", query)
+	defer func() { tracing.End(span, err) }()
+
+	return r.get(ctx, query, id)
+}
+
+func (r *repository) get(ctx context.Context, query string, id string) (*Escrow, error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	escrow, err := scanEscrow(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEscrowNotFound
+		}
+		return nil, errors.New("failed to retrieve escrow: " + err.Error())
+	}
+
+	return escrow, nil
+}
+
+func (r *repository) List(ctx context.Context, filter Filter) (_ []*Escrow, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + escrowColumns + `
+              FROM escrows
+              WHERE tenant_id = @tenant_id
+                AND (@wallet_id = '' OR payer_wallet_id = @wallet_id OR payee_wallet_id = @wallet_id)
+                AND (@status = '' OR status = @status)
+              ORDER BY created_at DESC`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "escrow.Repository/List", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query,
+		sql.Named("tenant_id", tenantID),
+		sql.Named("wallet_id", filter.WalletID),
+		sql.Named("status", string(filter.Status)),
+	)
+	if err != nil {
+		return nil, errors.New("failed to list escrows: " + err.Error())
+	}
+	defer rows.Close()
+
+	escrows := make([]*Escrow, 0)
+
+	for rows.Next() {
+		escrow, err := scanEscrow(rows)
+		if err != nil {
+			return nil, errors.New("failed to scan escrow: " + err.Error())
+		}
+
+		escrows = append(escrows, escrow)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list escrows: " + err.Error())
+	}
+
+	return escrows, nil
+}
+
+func (r *repository) Update(ctx context.Context, escrow *Escrow) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE escrows
+              SET status = @status, released = @released, refunded = @refunded, retry_at = @retry_at,
+                  last_error = @last_error, updated_at = @updated_at
+              WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "escrow.Repository/Update", query)
+	defer func() { tracing.End(span, err) }()
+
+	escrow.UpdatedAt = time.Now().UTC()
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("status", string(escrow.Status)),
+		sql.Named("released", escrow.Released),
+		sql.Named("refunded", escrow.Refunded),
+		sql.Named("retry_at", nullTime(escrow.RetryAt)),
+		sql.Named("last_error", nullString(wallet.Truncate(escrow.LastError, maxTextLength))),
+		sql.Named("updated_at", escrow.UpdatedAt),
+		sql.Named("id", escrow.ID),
+		sql.Named("tenant_id", tenantID),
+	)
+	if err != nil {
+		return errors.New("failed to update escrow: " + err.Error())
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return errors.New("failed to update escrow: " + err.Error())
+	}
+
+	if updated == 0 {
+		return ErrEscrowNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) LockDue(ctx context.Context, now time.Time) (_ *Escrow, err error) {
+	// NOTE: READPAST skips the escrows being expired by other expirers instead of waiting for them.
+	query := `SELECT TOP 1 ` + escrowColumns + `
+              FROM escrows WITH (UPDLOCK, READPAST, ROWLOCK)
+              WHERE status = 'held' AND expires_at <= @now AND (retry_at IS NULL OR retry_at <= @now)
+              ORDER BY COALESCE(retry_at, expires_at)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "escrow.Repository/LockDue", query)
+	defer func() { tracing.End(span, err) }()
+
+	escrow, err := scanEscrow(database.Conn(ctx, r.db).QueryRowContext(ctx, query, sql.Named("now", now)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.New("failed to lock due escrow: " + err.Error())
+	}
+
+	return escrow, nil
+}
+
+func (r *repository) CreateEvent(ctx context.Context, event *Event) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	event.ID = uuid.New().String()
+
+	query := `INSERT INTO escrow_events (id, tenant_id, escrow_id, from_status, to_status, released, refunded,
+                  payer_transaction_id, payee_transaction_id, reason, actor_type, actor_id, actor_ip, created_at)
+              VALUES (@id, @tenant_id, @escrow_id, @from_status, @to_status, @released, @refunded,
+                  @payer_transaction_id, @payee_transaction_id, @reason, @actor_type, @actor_id, @actor_ip,
+                  @created_at)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "escrow.Repository/CreateEvent", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("id", event.ID),
+		sql.Named("tenant_id", tenantID),
+		sql.Named("escrow_id", event.EscrowID),
+		sql.Named("from_status", nullString(string(event.FromStatus))),
+		sql.Named("to_status", string(event.ToStatus)),
+		sql.Named("released", event.Released),
+		sql.Named("refunded", event.Refunded),
+		sql.Named("payer_transaction_id", nullString(event.PayerTransactionID)),
+		sql.Named("payee_transaction_id", nullString(event.PayeeTransactionID)),
+		sql.Named("reason", nullString(event.Reason)),
+		sql.Named("actor_type", string(event.Actor.Type)),
+		sql.Named("actor_id", nullString(event.Actor.ID)),
+		sql.Named("actor_ip", nullString(event.Actor.IP)),
+		sql.Named("created_at", event.CreatedAt),
+	)
+	if err != nil {
+		return errors.New("failed to insert escrow event into database: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *repository) ListEvents(ctx context.Context, escrowID string) (_ []*Event, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + eventColumns + `
+              FROM escrow_events
+              WHERE escrow_id = @escrow_id AND tenant_id = @tenant_id
+              ORDER BY created_at, CASE WHEN from_status IS NULL THEN 0 ELSE 1 END`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "escrow.Repository/ListEvents", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query,
+		sql.Named("escrow_id", escrowID),
+		sql.Named("tenant_id", tenantID),
+	)
+	if err != nil {
+		return nil, errors.New("failed to list escrow events: " + err.Error())
+	}
+	defer rows.Close()
+
+	events := make([]*Event, 0)
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, errors.New("failed to scan escrow event: " + err.Error())
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list escrow events: " + err.Error())
+	}
+
+	return events, nil
+}
@@ -0,0 +1,351 @@
+package escrow
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+var (
+	ErrEscrowNotFound      = errors.New("escrow not found")
+	ErrEscrowNotHeld       = errors.New("escrow is not held")
+	ErrEscrowExpired       = errors.New("escrow has expired")
+	ErrEscrowNotExpired    = errors.New("escrow has not expired")
+	ErrInvalidExpiry       = errors.New("invalid escrow expiry")
+	ErrInvalidExpiryAction = errors.New("invalid escrow expiry action")
+	ErrInvalidSplit        = errors.New("invalid escrow split")
+	ErrReferenceTooLong    = errors.New("reference too long")
+	ErrReasonTooLong       = errors.New("reason too long")
+)
+
+// maxTextLength is the length of the reason and error columns.
+const maxTextLength = 500
+
+// expiryReason is the reason of the settlements of expired escrows.
+const expiryReason = "Escrow expired"
+
+// Service manages the escrows of a tenant. Expired escrows are settled by an Expirer.
+type Service interface {
+	// Create holds the amount of the payer wallet in escrow for the payee wallet of the same currency.
+	Create(ctx context.Context, instruction Instruction) (*Escrow, error)
+	Get(ctx context.Context, id string) (*Escrow, error)
+	List(ctx context.Context, filter Filter) ([]*Escrow, error)
+	// Release pays the whole amount of a held escrow to the payee.
+	Release(ctx context.Context, id string, reason string) (*Escrow, error)
+	// Refund pays the whole amount of a held escrow back to the payer.
+	Refund(ctx context.Context, id string, reason string) (*Escrow, error)
+	// Split pays payeeAmount of a held escrow to the payee and the rest back to the payer.
+	Split(ctx context.Context, id string, payeeAmount float64, reason string) (*Escrow, error)
+	// Expire settles a held escrow past its expiry with its expiry action.
+	Expire(ctx context.Context, id string) (*Escrow, error)
+	// ListEvents returns the state history of the escrow, oldest first.
+	ListEvents(ctx context.Context, id string) ([]*Event, error)
+}
+
+// ResourceType is the audit log resource type of escrows.
+const ResourceType = "escrow"
+
+// Audit log actions of escrows. Settlements are audited as `escrow.<status>`, e.g. `escrow.released`, their
+// movements are audited by the wallets.
+const (
+	AuditActionCreated = "escrow.created"
+)
+
+type service struct {
+	repo     Repository
+	wallets  wallet.Service
+	auditLog audit.Recorder
+}
+
+type serviceOption func(*service)
+
+// WithAuditLog sets the audit log recording every change of an escrow in the same database transaction.
+func WithAuditLog(auditLog audit.Recorder) serviceOption {
+	return func(s *service) {
+		s.auditLog = auditLog
+	}
+}
+
+func NewService(repo Repository, wallets wallet.Service, options ...serviceOption) Service {
+	s := &service{
+		repo:     repo,
+		wallets:  wallets,
+		auditLog: audit.NopRecorder{},
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *service) Create(ctx context.Context, instruction Instruction) (*Escrow, error) {
+	now := time.Now().UTC()
+
+	escrow := &Escrow{
+		PayerWalletID: instruction.PayerWalletID,
+		PayeeWalletID: instruction.PayeeWalletID,
+		Amount:        instruction.Amount,
+		Reference:     instruction.Reference,
+		Status:        StatusHeld,
+		ExpiresAt:     instruction.ExpiresAt,
+		OnExpiry:      instruction.OnExpiry,
+		CreatedBy:     audit.ActorFromContext(ctx),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if escrow.OnExpiry == "" {
+		escrow.OnExpiry = ExpiryRefund
+	}
+
+	if escrow.ExpiresAt != nil {
+		// NOTE: DATETIME columns do not store nanoseconds.
+		expiresAt := escrow.ExpiresAt.UTC().Truncate(time.Second)
+		escrow.ExpiresAt = &expiresAt
+	}
+
+	if err := validate(escrow, now); err != nil {
+		return nil, err
+	}
+
+	payer, err := s.wallets.GetWallet(ctx, escrow.PayerWalletID)
+	if err != nil {
+		return nil, wallet.CheckFound(ctx, err)
+	}
+
+	payee, err := s.wallets.GetWallet(ctx, escrow.PayeeWalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payer.Currency != payee.Currency {
+		return nil, wallet.ErrCurrencyMismatch
+	}
+
+	escrow.Currency = payer.Currency
+
+	err = s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		hold, err := s.wallets.HoldEscrow(ctx, escrow.PayerWalletID, escrow.Amount, escrow.Reference)
+		if err != nil {
+			return err
+		}
+
+		escrow.HoldTransactionID = hold.ID
+
+		if err := s.repo.Create(ctx, escrow); err != nil {
+			return err
+		}
+
+		err = s.repo.CreateEvent(ctx, &Event{
+			EscrowID:           escrow.ID,
+			ToStatus:           StatusHeld,
+			PayerTransactionID: hold.ID,
+			Actor:              escrow.CreatedBy,
+			CreatedAt:          now,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.auditLog.Record(ctx, AuditActionCreated, ResourceType, escrow.ID, nil, escrow)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Escrow created",
+		zap.String("escrow_id", escrow.ID),
+		zap.String("wallet_id", escrow.PayerWalletID),
+		zap.String("payee_wallet_id", escrow.PayeeWalletID),
+		zap.Float64("amount", escrow.Amount),
+		zap.Timep("expires_at", escrow.ExpiresAt),
+	)
+
+	return escrow, nil
+}
+
+func (s *service) Get(ctx context.Context, id string) (*Escrow, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *service) List(ctx context.Context, filter Filter) ([]*Escrow, error) {
+	return s.repo.List(ctx, filter)
+}
+
+func (s *service) Release(ctx context.Context, id string, reason string) (*Escrow, error) {
+	return s.settle(ctx, id, StatusReleased, func(escrow *Escrow) (float64, error) {
+		return escrow.Amount, nil
+	}, reason)
+}
+
+func (s *service) Refund(ctx context.Context, id string, reason string) (*Escrow, error) {
+	return s.settle(ctx, id, StatusRefunded, func(*Escrow) (float64, error) {
+		return 0, nil
+	}, reason)
+}
+
+func (s *service) Split(ctx context.Context, id string, payeeAmount float64, reason string) (*Escrow, error) {
+	return s.settle(ctx, id, StatusSplit, func(escrow *Escrow) (float64, error) {
+		if payeeAmount <= 0 || payeeAmount >= escrow.Amount || !wallet.WholeCents(payeeAmount) {
+			return 0, ErrInvalidSplit
+		}
+
+		return payeeAmount, nil
+	}, reason)
+}
+
+func (s *service) Expire(ctx context.Context, id string) (*Escrow, error) {
+	return s.settle(ctx, id, StatusExpired, func(escrow *Escrow) (float64, error) {
+		if escrow.OnExpiry == ExpiryRelease {
+			return escrow.Amount, nil
+		}
+
+		return 0, nil
+	}, expiryReason)
+}
+
+func (s *service) ListEvents(ctx context.Context, id string) ([]*Event, error) {
+	escrow, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListEvents(ctx, escrow.ID)
+}
+
+// settle pays the part of a held escrow returned by payeeAmount to the payee and the rest back to the payer,
+// records the settlement and moves the escrow to status in one database transaction holding the lock of the
+// escrow, so the money is paid out exactly once. Escrows past their expiry are only settled by Expire.
+func (s *service) settle(
+	ctx context.Context,
+	id string,
+	status Status,
+	payeeAmount func(escrow *Escrow) (float64, error),
+	reason string,
+) (*Escrow, error) {
+	if utf8.RuneCountInString(reason) > maxTextLength {
+		return nil, ErrReasonTooLong
+	}
+
+	var escrow *Escrow
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		escrow, err = s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if escrow.Status != StatusHeld {
+			return ErrEscrowNotHeld
+		}
+
+		now := time.Now().UTC()
+
+		switch {
+		case status == StatusExpired && !escrow.expired(now):
+			return ErrEscrowNotExpired
+		case status != StatusExpired && escrow.expired(now):
+			return ErrEscrowExpired
+		}
+
+		released, err := payeeAmount(escrow)
+		if err != nil {
+			return err
+		}
+
+		before := *escrow
+
+		event := &Event{
+			EscrowID:   escrow.ID,
+			FromStatus: escrow.Status,
+			ToStatus:   status,
+			Released:   released,
+			Refunded:   wallet.RoundCents(escrow.Amount - released),
+			Reason:     reason,
+			Actor:      audit.ActorFromContext(ctx),
+			CreatedAt:  now,
+		}
+
+		description := reason
+		if description == "" {
+			description = escrow.Reference
+		}
+
+		if event.Released > 0 {
+			transaction, err := s.wallets.ReleaseEscrow(ctx, escrow.PayeeWalletID, event.Released, description)
+			if err != nil {
+				return err
+			}
+
+			event.PayeeTransactionID = transaction.ID
+		}
+
+		if event.Refunded > 0 {
+			transaction, err := s.wallets.RefundEscrow(ctx, escrow.PayerWalletID, event.Refunded, description)
+			if err != nil {
+				return err
+			}
+
+			event.PayerTransactionID = transaction.ID
+		}
+
+		escrow.Status = status
+		escrow.Released = event.Released
+		escrow.Refunded = event.Refunded
+		escrow.RetryAt = nil
+		escrow.LastError = ""
+
+		if err := s.repo.Update(ctx, escrow); err != nil {
+			return err
+		}
+
+		if err := s.repo.CreateEvent(ctx, event); err != nil {
+			return err
+		}
+
+		return s.auditLog.Record(ctx, "escrow."+string(status), ResourceType, escrow.ID, &before, escrow)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Escrow settled",
+		zap.String("escrow_id", escrow.ID),
+		zap.String("status", string(escrow.Status)),
+		zap.Float64("released", escrow.Released),
+		zap.Float64("refunded", escrow.Refunded),
+	)
+
+	return escrow, nil
+}
+
+// validate checks the fields of the escrow that do not depend on its wallets.
+func validate(escrow *Escrow, now time.Time) error {
+	switch {
+	case escrow.PayerWalletID == escrow.PayeeWalletID:
+		return wallet.ErrSameWallet
+	case escrow.Amount <= 0 || !wallet.WholeCents(escrow.Amount):
+		return wallet.ErrInvalidAmount
+	case utf8.RuneCountInString(escrow.Reference) > wallet.MaxReferenceLength:
+		return ErrReferenceTooLong
+	case escrow.OnExpiry != ExpiryRefund && escrow.OnExpiry != ExpiryRelease:
+		return ErrInvalidExpiryAction
+	case escrow.ExpiresAt != nil && !escrow.ExpiresAt.After(now):
+		return ErrInvalidExpiry
+	}
+
+	return nil
+}
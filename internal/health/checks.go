@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type checkFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// NewCheck creates a Check from a function.
+func NewCheck(name string, fn func(ctx context.Context) error) Check {
+	return &checkFunc{name: name, fn: fn}
+}
+
+func (c *checkFunc) Name() string {
+	return c.name
+}
+
+func (c *checkFunc) Check(ctx context.Context) error {
+	return c.fn(ctx)
+}
+
+// NewDatabaseCheck pings the database within timeout.
+func NewDatabaseCheck(db *sql.DB, timeout time.Duration) Check {
+	return NewCheck("database", func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return db.PingContext(ctx)
+	})
+}
+
+// NewMigrationCheck verifies the schema is migrated to expectedVersion by golang-migrate and not left dirty.
+func NewMigrationCheck(db *sql.DB, expectedVersion uint) Check {
+	return NewCheck("migrations", func(ctx context.Context) error {
+		var (
+			version int64
+			dirty   bool
+		)
+
+		err := db.QueryRowContext(ctx, `SELECT TOP 1 version, dirty FROM schema_migrations`).Scan(&version, &dirty)
+		if err != nil {
+			return fmt.Errorf("failed to read schema version: %w", err)
+		}
+
+		if dirty {
+			return fmt.Errorf("schema version %d is dirty", version)
+		}
+
+		if version != int64(expectedVersion) {
+			return fmt.Errorf("schema version is %d, expected %d", version, expectedVersion)
+		}
+
+		return nil
+	})
+}
+
+// NewBacklogCheck fails when work queued for the background processors of the service waits for longer than
+// maxAge: pending batches, processing batches whose processor stopped renewing its lease, and due schedules and
+// escrow expiries. The service has no outbox, these tables are the queues of its asynchronous work.
+func NewBacklogCheck(db *sql.DB, maxAge time.Duration) Check {
+	query := `SELECT 'batches', MIN(created_at) FROM batches WHERE status = 'pending'
+              UNION ALL
+              SELECT 'batches', MIN(heartbeat_at) FROM batches WHERE status = 'processing'
+              UNION ALL
+              SELECT 'schedules', MIN(COALESCE(retry_at, next_run_at)) FROM schedules
+              WHERE status = 'active' AND COALESCE(retry_at, next_run_at) <= @now
+              UNION ALL
+              SELECT 'escrows', MIN(COALESCE(retry_at, expires_at)) FROM escrows
+              WHERE status = 'held' AND COALESCE(retry_at, expires_at) <= @now`
+
+	return NewCheck("backlog", func(ctx context.Context) error {
+		now := time.Now().UTC()
+
+		rows, err := db.QueryContext(ctx, query, sql.Named("now", now))
+		if err != nil {
+			return fmt.Errorf("failed to read backlog: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				queue  string
+				oldest sql.NullTime
+			)
+
+			if err := rows.Scan(&queue, &oldest); err != nil {
+				return fmt.Errorf("failed to scan backlog: %w", err)
+			}
+
+			if oldest.Valid && now.Sub(oldest.Time) > maxAge {
+				return fmt.Errorf("%s wait since %s, longer than %s", queue, oldest.Time.Format(time.RFC3339), maxAge)
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read backlog: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,137 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusFailing  = "failing"
+	StatusDraining = "draining"
+
+	defaultCheckTimeout = 2 * time.Second
+)
+
+// Check verifies one dependency the service needs to serve traffic.
+type Check interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type CheckResult struct {
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Error    string  `json:"error,omitempty"`
+	Duration float64 `json:"duration_seconds"`
+}
+
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+// Readiness reports whether the service can take traffic, running every Check on each probe.
+type Readiness struct {
+	checks       []Check
+	checkTimeout time.Duration
+	draining     atomic.Bool
+}
+
+func NewReadiness(checks ...Check) *Readiness {
+	return &Readiness{
+		checks:       checks,
+		checkTimeout: defaultCheckTimeout,
+	}
+}
+
+// SetDraining makes every subsequent probe fail, so load balancers stop routing traffic
+// before the servers shut down.
+func (r *Readiness) SetDraining() {
+	r.draining.Store(true)
+}
+
+// Report runs the checks concurrently.
+func (r *Readiness) Report(ctx context.Context) Report {
+	results := make([]CheckResult, len(r.checks))
+
+	var wg sync.WaitGroup
+
+	for i, check := range r.checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i] = r.run(ctx, check)
+		}()
+	}
+
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+
+	if r.draining.Load() {
+		report.Status = StatusDraining
+	}
+
+	return report
+}
+
+func (r *Readiness) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+
+	result := CheckResult{
+		Name:     check.Name(),
+		Status:   StatusOK,
+		Duration: time.Since(start).Seconds(),
+	}
+
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Handler serves the report as JSON, with status 503 unless the service is ready.
+func (r *Readiness) Handler(w http.ResponseWriter, req *http.Request) {
+	report := r.Report(req.Context())
+
+	writeReport(w, report.Status, report)
+}
+
+// StatusHandler serves only the overall status of the report, for listeners reachable by the public: the errors
+// of the checks may disclose e.g. the hosts and ports of dependencies.
+func (r *Readiness) StatusHandler(w http.ResponseWriter, req *http.Request) {
+	report := r.Report(req.Context())
+
+	writeReport(w, report.Status, Report{Status: report.Status})
+}
+
+func writeReport(w http.ResponseWriter, status string, body Report) {
+	code := http.StatusOK
+	if status != StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
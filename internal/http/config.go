@@ -28,40 +28,47 @@ type config struct {
 	shutdownTimeout   time.Duration
 }
 
-type option func(*config)
+type Option func(*config)
 
-func WithName(name string) option {
+func WithName(name string) Option {
 	return func(cfg *config) {
 		cfg.name = name
 	}
 }
 
-func WithReadTimeout(readTimeout time.Duration) option {
+func WithReadTimeout(readTimeout time.Duration) Option {
 	return func(cfg *config) {
 		cfg.readTimeout = readTimeout
 	}
 }
 
-func WithReadHeaderTimeout(readHeaderTimeout time.Duration) option {
+func WithReadHeaderTimeout(readHeaderTimeout time.Duration) Option {
 	return func(cfg *config) {
 		cfg.readHeaderTimeout = readHeaderTimeout
 	}
 }
 
-func WithWriteTimeout(writeTimeout time.Duration) option {
+func WithWriteTimeout(writeTimeout time.Duration) Option {
 	return func(cfg *config) {
 		cfg.writeTimeout = writeTimeout
 	}
 }
 
-func WithMaxHeaderBytes(maxHeaderBytes int) option {
+func WithMaxHeaderBytes(maxHeaderBytes int) Option {
 	return func(cfg *config) {
 		cfg.maxHeaderBytes = maxHeaderBytes
 	}
 }
 
-func WithServerShutdownTimeout(shutdownTimeout time.Duration) option {
+func WithServerShutdownTimeout(shutdownTimeout time.Duration) Option {
 	return func(cfg *config) {
 		cfg.shutdownTimeout = shutdownTimeout
 	}
 }
+
+// WithTLSConfig makes the server terminate TLS with tlsConfig, see NewTLSConfig.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(cfg *config) {
+		cfg.tlsConfig = tlsConfig
+	}
+}
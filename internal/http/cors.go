@@ -0,0 +1,288 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	headerOrigin                        = "Origin"
+	headerVary                          = "Vary"
+	headerAccessControlRequestMethod    = "Access-Control-Request-Method"
+	headerAccessControlRequestHeaders   = "Access-Control-Request-Headers"
+	headerAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	headerAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	headerAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	headerAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	headerAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	headerAccessControlMaxAge           = "Access-Control-Max-Age"
+)
+
+// CorsOptions configures the Cors middleware.
+type CorsOptions struct {
+	// AllowedOrigins may contain `*` wildcards, e.g. `https://*.example.com`. A single `*` allows any origin.
+	AllowedOrigins []string
+	AllowedMethods []string
+	// AllowedHeaders may contain a single `*` to allow any request header.
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is the number of seconds a preflight response may be cached. Zero omits the header.
+	MaxAge int
+	// Debug logs every CORS decision.
+	Debug bool
+}
+
+type cors struct {
+	log              logger.StructuredLogger
+	allowedOrigins   []string
+	allowAnyOrigin   bool
+	allowedMethods   []string
+	allowedHeaders   []string
+	allowAnyHeader   bool
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           int
+	debug            bool
+}
+
+// Cors handles CORS preflight requests and decorates the responses of cross-origin requests.
+func Cors(log logger.StructuredLogger, options CorsOptions) func(next http.Handler) http.Handler {
+	c := &cors{
+		log:              log,
+		allowedMethods:   normalize(options.AllowedMethods, strings.ToUpper),
+		exposedHeaders:   strings.Join(options.ExposedHeaders, ", "),
+		allowCredentials: options.AllowCredentials,
+		maxAge:           options.MaxAge,
+		debug:            options.Debug,
+	}
+
+	for _, origin := range normalize(options.AllowedOrigins, strings.ToLower) {
+		if origin == "*" {
+			c.allowAnyOrigin = true
+		}
+
+		c.allowedOrigins = append(c.allowedOrigins, origin)
+	}
+
+	for _, header := range normalize(options.AllowedHeaders, http.CanonicalHeaderKey) {
+		if header == "*" {
+			c.allowAnyHeader = true
+		}
+
+		c.allowedHeaders = append(c.allowedHeaders, header)
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions && r.Header.Get(headerAccessControlRequestMethod) != "" {
+				c.handlePreflight(w, r)
+				w.WriteHeader(http.StatusNoContent)
+
+				return
+			}
+
+			c.handleActual(w, r)
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func (c *cors) handlePreflight(w http.ResponseWriter, r *http.Request) {
+	headers := w.Header()
+	origin := r.Header.Get(headerOrigin)
+
+	headers.Add(headerVary, headerOrigin)
+	headers.Add(headerVary, headerAccessControlRequestMethod)
+	headers.Add(headerVary, headerAccessControlRequestHeaders)
+
+	if origin == "" {
+		c.logf("Preflight aborted: empty origin")
+		return
+	}
+
+	if !c.isOriginAllowed(origin) {
+		c.logf("Preflight aborted: origin not allowed", zap.String("origin", origin))
+		return
+	}
+
+	method := strings.ToUpper(r.Header.Get(headerAccessControlRequestMethod))
+	if !c.isMethodAllowed(method) {
+		c.logf("Preflight aborted: method not allowed", zap.String("method", method))
+		return
+	}
+
+	requestedHeaders := parseHeaderList(r.Header.Get(headerAccessControlRequestHeaders))
+	if !c.areHeadersAllowed(requestedHeaders) {
+		c.logf("Preflight aborted: headers not allowed", zap.Strings("headers", requestedHeaders))
+		return
+	}
+
+	headers.Set(headerAccessControlAllowOrigin, c.allowOriginValue(origin))
+	headers.Set(headerAccessControlAllowMethods, method)
+
+	if len(requestedHeaders) > 0 {
+		headers.Set(headerAccessControlAllowHeaders, strings.Join(requestedHeaders, ", "))
+	}
+
+	if c.allowCredentials {
+		headers.Set(headerAccessControlAllowCredentials, "true")
+	}
+
+	if c.maxAge > 0 {
+		headers.Set(headerAccessControlMaxAge, strconv.Itoa(c.maxAge))
+	}
+
+	c.logf("Preflight response headers", zap.Any("headers", headers))
+}
+
+func (c *cors) handleActual(w http.ResponseWriter, r *http.Request) {
+	headers := w.Header()
+	origin := r.Header.Get(headerOrigin)
+
+	headers.Add(headerVary, headerOrigin)
+
+	if origin == "" {
+		return
+	}
+
+	if !c.isOriginAllowed(origin) {
+		c.logf("Actual request no headers added: origin not allowed", zap.String("origin", origin))
+		return
+	}
+
+	if !c.isMethodAllowed(r.Method) {
+		c.logf("Actual request no headers added: method not allowed", zap.String("method", r.Method))
+		return
+	}
+
+	headers.Set(headerAccessControlAllowOrigin, c.allowOriginValue(origin))
+
+	if c.exposedHeaders != "" {
+		headers.Set(headerAccessControlExposeHeaders, c.exposedHeaders)
+	}
+
+	if c.allowCredentials {
+		headers.Set(headerAccessControlAllowCredentials, "true")
+	}
+}
+
+// allowOriginValue echoes the request origin unless any origin is allowed without credentials,
+// since browsers reject the `*` wildcard on credentialed requests.
+func (c *cors) allowOriginValue(origin string) string {
+	if c.allowAnyOrigin && !c.allowCredentials {
+		return "*"
+	}
+
+	return origin
+}
+
+func (c *cors) isOriginAllowed(origin string) bool {
+	origin = strings.ToLower(origin)
+
+	for _, pattern := range c.allowedOrigins {
+		if matchWildcard(pattern, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *cors) isMethodAllowed(method string) bool {
+	// NOTE: Preflight requests are always allowed, they are answered by the middleware itself.
+	if method == http.MethodOptions {
+		return true
+	}
+
+	for _, allowed := range c.allowedMethods {
+		if allowed == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *cors) areHeadersAllowed(requested []string) bool {
+	if c.allowAnyHeader {
+		return true
+	}
+
+	for _, header := range requested {
+		found := false
+
+		for _, allowed := range c.allowedHeaders {
+			if allowed == header {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *cors) logf(msg string, fields ...zap.Field) {
+	if c.debug {
+		c.log.Info("[cors] "+msg, fields...)
+	}
+}
+
+// matchWildcard reports whether value matches pattern, where every `*` in pattern matches any sequence of characters.
+func matchWildcard(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+
+	value = value[len(parts[0]):]
+
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(value, part)
+		if idx < 0 {
+			return false
+		}
+
+		value = value[idx+len(part):]
+	}
+
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
+
+func parseHeaderList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return normalize(strings.Split(value, ","), http.CanonicalHeaderKey)
+}
+
+func normalize(values []string, fn func(string) string) []string {
+	result := make([]string, 0, len(values))
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		result = append(result, fn(value))
+	}
+
+	return result
+}
@@ -0,0 +1,224 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	internalConfig "tribe-payments-wallet-golang-interview-assignment/internal/config"
+)
+
+var testCorsOptions = CorsOptions{
+	AllowedOrigins:   []string{"https://*.example.com"},
+	AllowedMethods:   []string{"GET", "POST"},
+	AllowedHeaders:   []string{"Content-Type", "If-Match"},
+	ExposedHeaders:   []string{"ETag", "Location"},
+	AllowCredentials: true,
+	MaxAge:           300,
+}
+
+// serveCors sends the request through the Cors middleware in front of a handler answering 200 OK.
+func serveCors(t *testing.T, options CorsOptions, request *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	Cors(nil, options)(next).ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func newPreflightRequest(origin string, method string, headers string) *http.Request {
+	request := httptest.NewRequest(http.MethodOptions, "/v1/wallets", nil)
+	request.Header.Set(headerOrigin, origin)
+	request.Header.Set(headerAccessControlRequestMethod, method)
+
+	if headers != "" {
+		request.Header.Set(headerAccessControlRequestHeaders, headers)
+	}
+
+	return request
+}
+
+func TestCors_PreflightAllowedOrigin(t *testing.T) {
+	recorder := serveCors(t, testCorsOptions,
+		newPreflightRequest("https://app.example.com", "POST", "content-type, if-match"))
+
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNoContent)
+	}
+
+	want := map[string]string{
+		headerAccessControlAllowOrigin:      "https://app.example.com",
+		headerAccessControlAllowMethods:     "POST",
+		headerAccessControlAllowHeaders:     "Content-Type, If-Match",
+		headerAccessControlAllowCredentials: "true",
+		headerAccessControlMaxAge:           "300",
+	}
+
+	for header, value := range want {
+		if got := recorder.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+}
+
+// defaultCorsOptions returns the options of the API server with the default configuration.
+func defaultCorsOptions(t *testing.T) CorsOptions {
+	t.Helper()
+
+	cfg, err := internalConfig.NewServerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return CorsOptions{
+		AllowedOrigins:   cfg.CorsAllowedOrigins,
+		AllowedMethods:   cfg.CorsAllowedMethods,
+		AllowedHeaders:   cfg.CorsHeaders(),
+		ExposedHeaders:   cfg.CorsExposedHeaders,
+		AllowCredentials: cfg.CorsAllowCredentials,
+		MaxAge:           cfg.CorsMaxAge,
+	}
+}
+
+func TestCors_DefaultConfigAllowsAPIMethods(t *testing.T) {
+	for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+		t.Run(method, func(t *testing.T) {
+			recorder := serveCors(t, defaultCorsOptions(t), newPreflightRequest("https://app.example.com", method, ""))
+
+			if got := recorder.Header().Get(headerAccessControlAllowMethods); got != method {
+				t.Errorf("%s = %q, want %q", headerAccessControlAllowMethods, got, method)
+			}
+		})
+	}
+}
+
+func TestCors_DefaultConfigAllowsClientHeaders(t *testing.T) {
+	recorder := serveCors(t, defaultCorsOptions(t), newPreflightRequest("https://app.example.com", "POST",
+		"content-type, if-match, x-api-key, x-tenant-id, x-user-id, x-request-id"))
+
+	want := "Content-Type, If-Match, X-Api-Key, X-Tenant-Id, X-User-Id, X-Request-Id"
+	if got := recorder.Header().Get(headerAccessControlAllowHeaders); got != want {
+		t.Errorf("%s = %q, want %q", headerAccessControlAllowHeaders, got, want)
+	}
+}
+
+func TestCors_ConfiguredTenancyHeadersAllowed(t *testing.T) {
+	t.Setenv("TENANT_HEADER", "X-Brand")
+	t.Setenv("API_KEY_HEADER", "X-Brand-Key")
+
+	recorder := serveCors(t, defaultCorsOptions(t), newPreflightRequest("https://app.example.com", "GET",
+		"x-brand, x-brand-key"))
+
+	if got := recorder.Header().Get(headerAccessControlAllowHeaders); got != "X-Brand, X-Brand-Key" {
+		t.Errorf("%s = %q, want %q", headerAccessControlAllowHeaders, got, "X-Brand, X-Brand-Key")
+	}
+}
+
+func TestCors_PreflightRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *http.Request
+	}{
+		{"disallowed origin", newPreflightRequest("https://evil.test", "POST", "")},
+		{"disallowed method", newPreflightRequest("https://app.example.com", "DELETE", "")},
+		{"disallowed header", newPreflightRequest("https://app.example.com", "POST", "X-Custom")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := serveCors(t, testCorsOptions, tc.request)
+
+			if recorder.Code != http.StatusNoContent {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNoContent)
+			}
+
+			for _, header := range []string{
+				headerAccessControlAllowOrigin,
+				headerAccessControlAllowMethods,
+				headerAccessControlAllowCredentials,
+			} {
+				if got := recorder.Header().Get(header); got != "" {
+					t.Errorf("%s = %q, want none", header, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCors_ActualRequestExposesHeaders(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/v1/wallets", nil)
+	request.Header.Set(headerOrigin, "https://app.example.com")
+
+	recorder := serveCors(t, testCorsOptions, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	want := map[string]string{
+		headerAccessControlAllowOrigin:      "https://app.example.com",
+		headerAccessControlExposeHeaders:    "ETag, Location",
+		headerAccessControlAllowCredentials: "true",
+	}
+
+	for header, value := range want {
+		if got := recorder.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+}
+
+func TestCors_ActualRequestDisallowedOrigin(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/v1/wallets", nil)
+	request.Header.Set(headerOrigin, "https://evil.test")
+
+	recorder := serveCors(t, testCorsOptions, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if got := recorder.Header().Get(headerAccessControlAllowOrigin); got != "" {
+		t.Errorf("%s = %q, want none", headerAccessControlAllowOrigin, got)
+	}
+
+	if got := recorder.Header().Get(headerAccessControlExposeHeaders); got != "" {
+		t.Errorf("%s = %q, want none", headerAccessControlExposeHeaders, got)
+	}
+}
+
+func TestCors_Credentials(t *testing.T) {
+	tests := []struct {
+		name             string
+		allowCredentials bool
+		wantOrigin       string
+		wantCredentials  string
+	}{
+		// Browsers reject the `*` wildcard on credentialed requests, so the origin is echoed.
+		{"with credentials", true, "https://app.example.com", "true"},
+		{"without credentials", false, "*", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			options := testCorsOptions
+			options.AllowedOrigins = []string{"*"}
+			options.AllowCredentials = tc.allowCredentials
+
+			recorder := serveCors(t, options, newPreflightRequest("https://app.example.com", "GET", ""))
+
+			if got := recorder.Header().Get(headerAccessControlAllowOrigin); got != tc.wantOrigin {
+				t.Errorf("%s = %q, want %q", headerAccessControlAllowOrigin, got, tc.wantOrigin)
+			}
+
+			if got := recorder.Header().Get(headerAccessControlAllowCredentials); got != tc.wantCredentials {
+				t.Errorf("%s = %q, want %q", headerAccessControlAllowCredentials, got, tc.wantCredentials)
+			}
+		})
+	}
+}
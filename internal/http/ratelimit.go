@@ -0,0 +1,269 @@
+package http
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+	headerRateLimitPolicy    = "RateLimit-Policy"
+	headerRetryAfter         = "Retry-After"
+
+	walletURLParam = "id"
+	anyMethod      = "*"
+)
+
+// RateLimitKey selects what a RateLimitRule counts requests by.
+type RateLimitKey string
+
+const (
+	// RateLimitByClient counts by API key, or by IP address for requests without one.
+	RateLimitByClient RateLimitKey = "client"
+	// RateLimitByAPIKey counts by API key. Requests without one share a quota per IP address.
+	RateLimitByAPIKey RateLimitKey = "api_key"
+	// RateLimitByIP counts by the IP address of the peer.
+	RateLimitByIP RateLimitKey = "ip"
+	// RateLimitByWallet counts by the `{id}` URL parameter of wallet routes.
+	RateLimitByWallet RateLimitKey = "wallet"
+)
+
+// RateLimitRule limits the requests to one route.
+type RateLimitRule struct {
+	// Method is the HTTP method of the route, `*` matches any method.
+	Method string
+	// Pattern is the chi route pattern, e.g. `/v1/wallets/{id}/withdraw`.
+	Pattern   string
+	Limit     int
+	Window    time.Duration
+	Key       RateLimitKey
+	Algorithm RateLimitAlgorithm
+}
+
+type rateLimiter struct {
+	log            logger.StructuredLogger
+	store          RateLimitStore
+	rules          map[string][]RateLimitRule
+	responseWriter http.HandlerFunc
+	apiKeyHeader   string
+	now            func() time.Time
+}
+
+// RateLimit rejects requests exceeding the rule of their route with the responseWriter
+// and reports the quota in `RateLimit-*` headers. Routes without a rule are not limited.
+// When the store fails the request is let through.
+func RateLimit(
+	log logger.StructuredLogger,
+	store RateLimitStore,
+	rules []RateLimitRule,
+	apiKeyHeader string,
+	responseWriter http.HandlerFunc,
+) func(next http.Handler) http.Handler {
+	limiter := &rateLimiter{
+		log:            log,
+		store:          store,
+		rules:          make(map[string][]RateLimitRule),
+		responseWriter: responseWriter,
+		apiKeyHeader:   apiKeyHeader,
+		now:            time.Now,
+	}
+
+	for _, rule := range rules {
+		routeKey := rule.Method + " " + rule.Pattern
+		limiter.rules[routeKey] = append(limiter.rules[routeKey], rule)
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if len(limiter.rules) == 0 || limiter.allow(w, r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			limiter.responseWriter(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func (l *rateLimiter) allow(w http.ResponseWriter, r *http.Request) bool {
+	routeCtx := l.matchRoute(r)
+	if routeCtx == nil {
+		return true
+	}
+
+	pattern := routeCtx.RoutePattern()
+
+	rules := l.rules[r.Method+" "+pattern]
+	rules = append(rules, l.rules[anyMethod+" "+pattern]...)
+
+	var (
+		strictest *RateLimitDecision
+		policy    *RateLimitRule
+	)
+
+	now := l.now()
+
+	for i := range rules {
+		rule := rules[i]
+		key := storeKey(rule, l.clientKey(rule.Key, r, routeCtx))
+
+		decision, err := l.store.Take(r.Context(), key, rule, now)
+		if err != nil {
+			l.log.Error(
+				"Failed to apply rate limit",
+				zap.String("pattern", rule.Pattern),
+				logger.ErrorField(err),
+			)
+
+			continue
+		}
+
+		if strictest == nil || isStricter(decision, *strictest) {
+			strictest = &decision
+			policy = &rule
+		}
+	}
+
+	if strictest == nil {
+		return true
+	}
+
+	headers := w.Header()
+	headers.Set(headerRateLimitLimit, strconv.Itoa(strictest.Limit))
+	headers.Set(headerRateLimitRemaining, strconv.Itoa(strictest.Remaining))
+	headers.Set(headerRateLimitReset, strconv.Itoa(ceilSeconds(strictest.Reset)))
+	headers.Set(
+		headerRateLimitPolicy,
+		strconv.Itoa(policy.Limit)+";w="+strconv.Itoa(ceilSeconds(policy.Window)),
+	)
+
+	if !strictest.Allowed {
+		headers.Set(headerRetryAfter, strconv.Itoa(ceilSeconds(strictest.RetryAfter)))
+	}
+
+	return strictest.Allowed
+}
+
+// matchRoute resolves the route pattern and URL parameters of the request ahead of the router.
+func (l *rateLimiter) matchRoute(r *http.Request) *chi.Context {
+	routeCtx := chi.RouteContext(r.Context())
+	if routeCtx == nil || routeCtx.Routes == nil {
+		return nil
+	}
+
+	matchCtx := chi.NewRouteContext()
+	if !routeCtx.Routes.Match(matchCtx, r.Method, r.URL.Path) {
+		return nil
+	}
+
+	return matchCtx
+}
+
+func (l *rateLimiter) clientKey(key RateLimitKey, r *http.Request, routeCtx *chi.Context) string {
+	switch key {
+	case RateLimitByWallet:
+		return walletKey(routeCtx.URLParam(walletURLParam))
+	case RateLimitByIP:
+		return "ip:" + remoteIP(r)
+	case RateLimitByAPIKey, RateLimitByClient:
+		apiKey := r.Header.Get(l.apiKeyHeader)
+		if apiKey != "" {
+			return "api_key:" + apiKey
+		}
+	}
+
+	return "ip:" + remoteIP(r)
+}
+
+// WalletRateLimiter applies the wallet rules of a route to operations made without a request to the route,
+// e.g. the withdrawals of a batch. They share the quota of the wallet with the requests.
+type WalletRateLimiter struct {
+	store RateLimitStore
+	rules []RateLimitRule
+	now   func() time.Time
+}
+
+// NewWalletRateLimiter keeps the rules counting the requests to method and pattern by wallet.
+func NewWalletRateLimiter(
+	store RateLimitStore,
+	rules []RateLimitRule,
+	method string,
+	pattern string,
+) *WalletRateLimiter {
+	limiter := &WalletRateLimiter{
+		store: store,
+		now:   time.Now,
+	}
+
+	for _, rule := range rules {
+		if (rule.Method == method || rule.Method == anyMethod) && rule.Pattern == pattern &&
+			rule.Key == RateLimitByWallet {
+			limiter.rules = append(limiter.rules, rule)
+		}
+	}
+
+	return limiter
+}
+
+// Allow counts an operation on the wallet and tells whether it is within every rule.
+func (l *WalletRateLimiter) Allow(ctx context.Context, walletID string) (bool, error) {
+	now := l.now()
+	allowed := true
+
+	for _, rule := range l.rules {
+		decision, err := l.store.Take(ctx, storeKey(rule, walletKey(walletID)), rule, now)
+		if err != nil {
+			return false, err
+		}
+
+		allowed = allowed && decision.Allowed
+	}
+
+	return allowed, nil
+}
+
+// storeKey is the key of the quota of the client of the rule in the RateLimitStore.
+func storeKey(rule RateLimitRule, clientKey string) string {
+	return rule.Method + " " + rule.Pattern + "|" + clientKey
+}
+
+func walletKey(walletID string) string {
+	return "wallet:" + walletID
+}
+
+func isStricter(decision, than RateLimitDecision) bool {
+	if decision.Allowed != than.Allowed {
+		return !decision.Allowed
+	}
+
+	if !decision.Allowed {
+		return decision.RetryAfter > than.RetryAfter
+	}
+
+	return decision.Remaining < than.Remaining
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
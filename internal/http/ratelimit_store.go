@@ -0,0 +1,194 @@
+package http
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const memoryStoreSweepInterval = time.Minute
+
+// RateLimitAlgorithm selects how requests are counted against a RateLimitRule.
+type RateLimitAlgorithm string
+
+const (
+	// TokenBucket allows bursts of up to Limit requests and refills Limit tokens evenly over Window.
+	TokenBucket RateLimitAlgorithm = "token_bucket"
+	// SlidingWindow allows Limit requests in any Window, approximated by weighting the previous fixed window.
+	SlidingWindow RateLimitAlgorithm = "sliding_window"
+)
+
+// RateLimitDecision is the outcome of counting one request.
+type RateLimitDecision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the quota is fully restored.
+	Reset time.Duration
+	// RetryAfter is the time until the next request would be allowed. It is zero for allowed requests.
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps the rate limiting state per key.
+// Implementations backed by shared storage (e.g. Redis) must apply the algorithm atomically per key,
+// so that all instances of the service share the same quota.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, rule RateLimitRule, now time.Time) (RateLimitDecision, error)
+}
+
+type memoryEntry struct {
+	// token bucket state
+	tokens     float64
+	lastRefill time.Time
+
+	// sliding window state
+	windowStart   time.Time
+	currentCount  int
+	previousCount int
+
+	expiresAt time.Time
+}
+
+// MemoryRateLimitStore is a RateLimitStore local to the process.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		entries: make(map[string]*memoryEntry),
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(
+	_ context.Context,
+	key string,
+	rule RateLimitRule,
+	now time.Time,
+) (RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+
+	// NOTE: Sliding window state may still matter one window after the last request.
+	entry.expiresAt = now.Add(2 * rule.Window)
+
+	if rule.Algorithm == SlidingWindow {
+		return takeSlidingWindow(entry, rule, now), nil
+	}
+
+	return takeTokenBucket(entry, rule, now), nil
+}
+
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memoryStoreSweepInterval {
+		return
+	}
+
+	s.lastSweep = now
+
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func takeTokenBucket(entry *memoryEntry, rule RateLimitRule, now time.Time) RateLimitDecision {
+	capacity := float64(rule.Limit)
+	ratePerSecond := capacity / rule.Window.Seconds()
+
+	if entry.lastRefill.IsZero() {
+		entry.tokens = capacity
+	} else {
+		elapsed := now.Sub(entry.lastRefill).Seconds()
+		entry.tokens = math.Min(capacity, entry.tokens+elapsed*ratePerSecond)
+	}
+
+	entry.lastRefill = now
+
+	decision := RateLimitDecision{Limit: rule.Limit}
+
+	if entry.tokens >= 1 {
+		entry.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = secondsToDuration((1 - entry.tokens) / ratePerSecond)
+	}
+
+	decision.Remaining = int(math.Floor(entry.tokens))
+	decision.Reset = secondsToDuration((capacity - entry.tokens) / ratePerSecond)
+
+	return decision
+}
+
+func takeSlidingWindow(entry *memoryEntry, rule RateLimitRule, now time.Time) RateLimitDecision {
+	windowStart := now.Truncate(rule.Window)
+
+	switch {
+	case entry.windowStart.Equal(windowStart):
+	case entry.windowStart.Add(rule.Window).Equal(windowStart):
+		entry.previousCount = entry.currentCount
+		entry.currentCount = 0
+		entry.windowStart = windowStart
+	default:
+		entry.previousCount = 0
+		entry.currentCount = 0
+		entry.windowStart = windowStart
+	}
+
+	elapsed := now.Sub(windowStart)
+	untilNextWindow := rule.Window - elapsed
+	previousWeight := 1 - elapsed.Seconds()/rule.Window.Seconds()
+	estimated := float64(entry.previousCount)*previousWeight + float64(entry.currentCount)
+
+	decision := RateLimitDecision{Limit: rule.Limit}
+
+	if estimated+1 <= float64(rule.Limit) {
+		entry.currentCount++
+		estimated++
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = slidingWindowRetryAfter(entry, rule, elapsed)
+	}
+
+	decision.Remaining = max(0, rule.Limit-int(math.Ceil(estimated)))
+
+	// NOTE: Requests of the current window keep counting until the end of the next one.
+	decision.Reset = untilNextWindow
+	if entry.currentCount > 0 {
+		decision.Reset += rule.Window
+	}
+
+	return decision
+}
+
+// slidingWindowRetryAfter returns how long until the weighted count of the previous window decays enough
+// for one more request, or until the next window when the current one alone exhausts the limit.
+func slidingWindowRetryAfter(entry *memoryEntry, rule RateLimitRule, elapsed time.Duration) time.Duration {
+	untilNextWindow := rule.Window - elapsed
+	headroom := float64(rule.Limit - 1 - entry.currentCount)
+
+	if headroom < 0 || entry.previousCount == 0 {
+		return untilNextWindow
+	}
+
+	// previousCount * (1 - (elapsed+wait)/window) + currentCount <= limit - 1
+	wait := rule.Window.Seconds()*(1-headroom/float64(entry.previousCount)) - elapsed.Seconds()
+
+	return min(untilNextWindow, secondsToDuration(math.Max(0, wait)))
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/sumup-oss/go-pkgs/logger"
 	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
 )
 
 func Recovery(
@@ -17,11 +19,13 @@ func Recovery(
 			defer func() {
 				err := recover()
 				if err != nil && err != http.ErrAbortHandler {
+					reqLog := logging.FromContext(r.Context(), log)
+
 					logErr, ok := err.(error)
 					if ok {
-						log.Error("panic", logger.ErrorField(logErr), zap.String("trace", string(debug.Stack())))
+						reqLog.Error("panic", logger.ErrorField(logErr), zap.String("trace", string(debug.Stack())))
 					} else {
-						log.Error("Internal server error")
+						reqLog.Error("Internal server error")
 					}
 
 					responseWriter(w, r)
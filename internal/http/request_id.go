@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+
+	maxRequestIDLength = 128
+)
+
+type requestIDContextKey struct{}
+
+// RequestID accepts the `X-Request-ID` of the caller or generates one, stores it in the request context
+// and echoes it in the response.
+func RequestID(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(HeaderRequestID)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		w.Header().Set(HeaderRequestID, requestID)
+
+		ctx := context.WithValue(r.Context(), requestIDContextKey{}, requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// RequestIDFromContext returns the request ID stored by RequestID, or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+
+	return requestID
+}
+
+// isValidRequestID accepts IDs of printable ASCII characters only, so callers cannot inject into logs.
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
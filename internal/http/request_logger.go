@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
+)
+
+// RequestLogger stores a logger carrying the request ID and trace ID in the request context,
+// for handlers and the layers below to log with logging.FromContext.
+func RequestLogger(log logger.StructuredLogger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			fields := append(
+				[]zap.Field{zap.String("request_id", RequestIDFromContext(r.Context()))},
+				tracing.LogFields(r.Context())...,
+			)
+
+			ctx := logging.NewContext(r.Context(), log.With(fields...))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// AccessLog logs every served request with the request-scoped logger.
+func AccessLog(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		defer func() {
+			fields := []zap.Field{
+				zap.String("proto", r.Proto),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Duration("lat", time.Since(start)),
+				zap.Int("status", ww.Status()),
+				zap.Int("size", ww.BytesWritten()),
+			}
+
+			if routeCtx := chi.RouteContext(r.Context()); routeCtx != nil && routeCtx.RoutePattern() != "" {
+				fields = append(fields, zap.String("route", routeCtx.RoutePattern()))
+			}
+
+			if referer := r.Referer(); referer != "" {
+				fields = append(fields, zap.String("ref", referer))
+			}
+
+			if userAgent := r.UserAgent(); userAgent != "" {
+				fields = append(fields, zap.String("ua", userAgent))
+			}
+
+			logging.FromContext(r.Context(), nil).Info("Served", fields...)
+		}()
+
+		next.ServeHTTP(ww, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -22,7 +22,7 @@ func NewServer(
 	log logger.StructuredLogger,
 	listenAddr string,
 	mux http.Handler,
-	options ...option,
+	options ...Option,
 ) *Server {
 	cfg := &config{
 		name:              defaultName,
@@ -91,11 +91,21 @@ func (s *Server) Run(ctx context.Context) error {
 	defer wg.Wait()
 	defer close(doneCh)
 
-	s.log.Info("Starting HTTP server", zap.String("address", s.server.Addr))
+	s.log.Info(
+		"Starting HTTP server",
+		zap.String("address", s.server.Addr),
+		zap.Bool("tls", s.server.TLSConfig != nil),
+	)
 
-	err := s.server.ListenAndServe()
+	var err error
+	if s.server.TLSConfig != nil {
+		// NOTE: The certificates are provided by the TLS config.
+		err = s.server.ListenAndServeTLS("", "")
+	} else {
+		err = s.server.ListenAndServe()
+	}
 
-	// NOTE: ListenAndServe always returns an error.
+	// NOTE: ListenAndServe and ListenAndServeTLS always return an error.
 	s.log.Info(
 		"HTTP server shutdown",
 		zap.String("address", s.server.Addr),
@@ -0,0 +1,227 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+)
+
+const defaultCertificateReloadInterval = 10 * time.Second
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSOptions configures in-process TLS termination.
+type TLSOptions struct {
+	CertFile string
+	KeyFile  string
+	// MinVersion is one of `1.0|1.1|1.2|1.3`.
+	MinVersion string
+	// ClientCAFile enables mutual TLS: clients must present a certificate signed by one of its CAs.
+	ClientCAFile string
+	// AllowedClientSubjects restricts mutual TLS to client certificates whose subject common name
+	// or distinguished name is listed. Empty allows any certificate signed by the client CAs.
+	AllowedClientSubjects []string
+	// ReloadInterval is how often the files are checked for changes. Zero uses the default of 10s.
+	ReloadInterval time.Duration
+}
+
+// NewTLSConfig creates a server TLS configuration whose certificate and client CAs are reloaded
+// when their files change on disk, without restarting the server.
+func NewTLSConfig(log logger.StructuredLogger, options TLSOptions) (*tls.Config, error) {
+	minVersion, ok := tlsVersions[options.MinVersion]
+	if !ok {
+		return nil, errors.New("unsupported minimum TLS version %q", options.MinVersion)
+	}
+
+	if options.ReloadInterval <= 0 {
+		options.ReloadInterval = defaultCertificateReloadInterval
+	}
+
+	reloader := &certificateReloader{
+		log:     log,
+		options: options,
+	}
+
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+
+	baseConfig := &tls.Config{
+		MinVersion: minVersion,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+
+	if options.ClientCAFile != "" {
+		baseConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		baseConfig.VerifyConnection = verifyClientSubject(options.AllowedClientSubjects)
+	}
+
+	return &tls.Config{
+		MinVersion: minVersion,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := reloader.current()
+
+			return cert, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, clientCAs := reloader.current()
+
+			config := baseConfig.Clone()
+			config.Certificates = []tls.Certificate{*cert}
+			config.ClientCAs = clientCAs
+
+			return config, nil
+		},
+	}, nil
+}
+
+func verifyClientSubject(allowedSubjects []string) func(tls.ConnectionState) error {
+	allowed := make(map[string]struct{}, len(allowedSubjects))
+	for _, subject := range allowedSubjects {
+		allowed[subject] = struct{}{}
+	}
+
+	return func(state tls.ConnectionState) error {
+		if len(allowed) == 0 {
+			return nil
+		}
+
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("client certificate required")
+		}
+
+		subject := state.PeerCertificates[0].Subject
+
+		if _, ok := allowed[subject.CommonName]; ok {
+			return nil
+		}
+
+		if _, ok := allowed[subject.String()]; ok {
+			return nil
+		}
+
+		return errors.New("client certificate subject %q is not allowed", subject.String())
+	}
+}
+
+type certificateReloader struct {
+	log     logger.StructuredLogger
+	options TLSOptions
+
+	mu          sync.Mutex
+	cert        *tls.Certificate
+	clientCAs   *x509.CertPool
+	modTimes    map[string]time.Time
+	lastChecked time.Time
+}
+
+// current returns the loaded certificate and client CAs, reloading them first
+// when the files changed since the last check.
+func (r *certificateReloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastChecked) >= r.options.ReloadInterval {
+		r.lastChecked = time.Now()
+
+		if r.changed() {
+			err := r.loadLocked()
+			if err != nil {
+				// NOTE: Keep serving the previous certificate, e.g. while the files are only partially written.
+				r.log.Error("Failed to reload TLS certificate, keeping the previous one", logger.ErrorField(err))
+			} else {
+				r.log.Info("Reloaded TLS certificate", zap.String("cert_file", r.options.CertFile))
+			}
+		}
+	}
+
+	return r.cert, r.clientCAs
+}
+
+func (r *certificateReloader) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastChecked = time.Now()
+
+	return r.loadLocked()
+}
+
+func (r *certificateReloader) loadLocked() error {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.options.CertFile, r.options.KeyFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to load TLS certificate")
+	}
+
+	var clientCAs *x509.CertPool
+
+	if r.options.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.options.ClientCAFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to read client CA file")
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.New("no certificates found in client CA file %s", r.options.ClientCAFile)
+		}
+	}
+
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+
+	return nil
+}
+
+func (r *certificateReloader) changed() bool {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		r.log.Error("Failed to check TLS files for changes", logger.ErrorField(err))
+		return false
+	}
+
+	for file, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *certificateReloader) statFiles() (map[string]time.Time, error) {
+	files := []string{r.options.CertFile, r.options.KeyFile}
+	if r.options.ClientCAFile != "" {
+		files = append(files, r.options.ClientCAFile)
+	}
+
+	modTimes := make(map[string]time.Time, len(files))
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to stat %s", file)
+		}
+
+		modTimes[file] = info.ModTime()
+	}
+
+	return modTimes, nil
+}
@@ -0,0 +1,324 @@
+package interest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/snapshot"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+// BalanceReader reports the end-of-day balances of the wallets of the tenant in ctx, see snapshot.Snapshotter.
+type BalanceReader interface {
+	EndOfDay(ctx context.Context, day time.Time) (*snapshot.Report, error)
+}
+
+// Accruer accrues the interest of the wallets with a product and pays it at the end of the capitalisation
+// periods of their product. Accruals and capitalisations are recorded once per wallet and day, respectively
+// period, so running it again for the same day is safe.
+type Accruer struct {
+	repo     Repository
+	balances BalanceReader
+	wallets  wallet.Service
+	tenants  *tenant.Registry
+}
+
+func NewAccruer(repo Repository, balances BalanceReader, wallets wallet.Service, tenants *tenant.Registry) *Accruer {
+	return &Accruer{
+		repo:     repo,
+		balances: balances,
+		wallets:  wallets,
+		tenants:  tenants,
+	}
+}
+
+// Run accrues the interest of day for the wallets of every tenant, pays the interest of the periods ending on day
+// and charges the wallets that ended day overdrawn. A failing tenant does not stop the others, the error is
+// returned once every tenant was run.
+func (a *Accruer) Run(ctx context.Context, day time.Time) error {
+	var failed error
+
+	for _, t := range a.tenants.All() {
+		if len(t.Products) == 0 && !t.Overdraft.Charges() {
+			continue
+		}
+
+		log := logging.FromContext(ctx, nil).With(zap.String("tenant_id", t.ID))
+		tenantCtx := tenant.NewContext(logging.NewContext(ctx, log), t)
+
+		if _, err := a.Accrue(tenantCtx, day); err != nil {
+			log.Error("Failed to accrue interest", logger.ErrorField(err))
+			failed = err
+
+			continue
+		}
+
+		if _, err := a.Capitalise(tenantCtx, day); err != nil {
+			log.Error("Failed to capitalise interest", logger.ErrorField(err))
+			failed = err
+
+			continue
+		}
+
+		if _, err := a.ChargeOverdrafts(tenantCtx, day); err != nil {
+			log.Error("Failed to charge overdrafts", logger.ErrorField(err))
+			failed = err
+		}
+	}
+
+	return failed
+}
+
+// Accrue records the interest earned in day by the wallets of the tenant in ctx with a product, from their
+// end-of-day balance. Wallets already accrued for the day are skipped. It returns the number of recorded accruals
+// and snapshot.ErrDayNotOver when the day has not ended yet.
+func (a *Accruer) Accrue(ctx context.Context, day time.Time) (int, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	day = snapshot.Day(day)
+
+	products, err := a.repo.ProductWallets(ctx)
+	if err != nil || len(products) == 0 {
+		return 0, err
+	}
+
+	report, err := a.balances.EndOfDay(ctx, day)
+	if err != nil {
+		return 0, err
+	}
+
+	accrued := 0
+
+	for _, balance := range report.Balances {
+		productID, ok := products[balance.WalletID]
+		if !ok {
+			continue
+		}
+
+		product, err := t.Product(productID)
+		if err != nil {
+			logging.FromContext(ctx, nil).Warn(
+				"Wallet product is not offered anymore, no interest accrued",
+				zap.String("wallet_id", balance.WalletID),
+				zap.String("product", productID),
+			)
+
+			continue
+		}
+
+		amount, err := dailyInterest(product, fromCents(balance.Balance), day)
+		if err != nil {
+			return accrued, err
+		}
+
+		if amount.Sign() == 0 {
+			continue
+		}
+
+		created, err := a.repo.CreateAccrual(ctx, &Accrual{
+			WalletID: balance.WalletID,
+			Date:     day,
+			Product:  productID,
+			Balance:  balance.Balance,
+			Amount:   amount,
+		})
+		if err != nil {
+			return accrued, err
+		}
+
+		if created {
+			accrued++
+		}
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Interest accrued",
+		zap.String("date", day.Format(time.DateOnly)),
+		zap.Int("accruals", accrued),
+	)
+
+	return accrued, nil
+}
+
+// Capitalise pays the interest accrued up to day into the wallets of the tenant in ctx whose product ends a
+// capitalisation period on day. Frozen wallets are paid at the end of their next period. It returns the number
+// of recorded capitalisations.
+func (a *Accruer) Capitalise(ctx context.Context, day time.Time) (int, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	day = snapshot.Day(day)
+
+	walletIDs, err := a.repo.UnpaidWallets(ctx, day)
+	if err != nil || len(walletIDs) == 0 {
+		return 0, err
+	}
+
+	products, err := a.repo.ProductWallets(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	capitalised := 0
+
+	for _, walletID := range walletIDs {
+		product, err := t.Product(products[walletID])
+		if err != nil || !periodEnds(product.Capitalisation, day) {
+			continue
+		}
+
+		created, err := a.capitalise(ctx, walletID, day)
+		if err != nil {
+			if errors.Is(err, wallet.ErrWalletFrozen) {
+				logging.FromContext(ctx, nil).Warn(
+					"Wallet is frozen, interest capitalisation postponed to the next period",
+					zap.String("wallet_id", walletID),
+				)
+
+				continue
+			}
+
+			return capitalised, err
+		}
+
+		if created {
+			capitalised++
+		}
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Interest capitalised",
+		zap.String("period_end", day.Format(time.DateOnly)),
+		zap.Int("capitalisations", capitalised),
+	)
+
+	return capitalised, nil
+}
+
+// capitalise pays the interest of the wallet accrued up to periodEnd, with the remainder carried from its last
+// capitalisation, and records the capitalisation in the same database transaction. It returns false when the
+// accruals were capitalised in between.
+func (a *Accruer) capitalise(ctx context.Context, walletID string, periodEnd time.Time) (bool, error) {
+	created := false
+
+	err := a.repo.WithinTx(ctx, func(ctx context.Context) error {
+		unpaid, err := a.repo.Unpaid(ctx, walletID, periodEnd)
+		if err != nil || unpaid.Days == 0 {
+			return err
+		}
+
+		last, err := a.repo.LastCapitalisation(ctx, walletID)
+		if err != nil {
+			return err
+		}
+
+		accrued := new(big.Rat).Set(unpaid.Amount)
+		if last != nil {
+			accrued.Add(accrued, last.Carried)
+		}
+
+		cents, carried := wholeCents(accrued)
+
+		capitalisation := &Capitalisation{
+			WalletID:  walletID,
+			PeriodEnd: periodEnd,
+			Accrued:   accrued,
+			Paid:      float64(cents) / 100,
+			Carried:   carried,
+		}
+
+		if cents > 0 {
+			reason := "Interest " + unpaid.From.Format(time.DateOnly) + " to " + periodEnd.Format(time.DateOnly)
+
+			transaction, err := a.wallets.PayInterest(ctx, walletID, capitalisation.Paid, reason)
+			if err != nil {
+				return err
+			}
+
+			capitalisation.TransactionID = transaction.ID
+		}
+
+		if err := a.repo.CreateCapitalisation(ctx, capitalisation); err != nil {
+			return err
+		}
+
+		created = true
+
+		return nil
+	})
+
+	return created, err
+}
+
+// Accrued returns the interest accrued by the wallet and not paid yet.
+func (a *Accruer) Accrued(ctx context.Context, walletID string) (*Summary, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := a.wallets.GetWallet(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{
+		WalletID: found.ID,
+		Product:  found.Product,
+		Currency: found.Currency,
+		Accrued:  json.Number(formatDecimal(new(big.Rat))),
+	}
+
+	if found.Product == "" {
+		return summary, nil
+	}
+
+	today := snapshot.Day(time.Now())
+
+	unpaid, err := a.repo.Unpaid(ctx, found.ID, today)
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := a.repo.LastCapitalisation(ctx, found.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	accrued := new(big.Rat).Set(unpaid.Amount)
+
+	if last != nil {
+		accrued.Add(accrued, last.Carried)
+		summary.LastPeriodEnd = last.PeriodEnd.Format(time.DateOnly)
+	}
+
+	cents, _ := wholeCents(accrued)
+
+	summary.Accrued = json.Number(formatDecimal(accrued))
+	summary.Payable = float64(cents) / 100
+	summary.Days = unpaid.Days
+
+	if unpaid.Days > 0 {
+		summary.AccruedFrom = unpaid.From.Format(time.DateOnly)
+		summary.AccruedTo = unpaid.To.Format(time.DateOnly)
+	}
+
+	if product, err := t.Product(found.Product); err == nil {
+		summary.NextPeriodEnd = nextPeriodEnd(product.Capitalisation, today).Format(time.DateOnly)
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,65 @@
+// Package interest accrues the interest of the wallets opened with a product of their tenant, e.g. savings
+// wallets, and pays it into them. Every day the end-of-day balance of a wallet earns the annual rates of the tiers
+// of its product divided by the day-count convention of the product. Accruals are computed with exact rational
+// arithmetic and stored with 12 decimals. At the end of every capitalisation period the accrued interest is paid
+// in whole cents and the remaining fraction of a cent is carried to the next period.
+package interest
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+)
+
+// Accrual is the interest earned by a wallet in a day.
+type Accrual struct {
+	WalletID string
+	Date     time.Time
+	Product  string
+	// Balance is the end-of-day balance of the wallet.
+	Balance float64
+	Amount  *big.Rat
+}
+
+// Capitalisation is the payment into a wallet of the interest accrued up to the end of a period.
+type Capitalisation struct {
+	ID        string
+	WalletID  string
+	PeriodEnd time.Time
+	// Accrued is the interest of the period plus the remainder carried from the previous capitalisation.
+	Accrued *big.Rat
+	Paid    float64
+	// Carried is the fraction of a cent left unpaid, carried to the next capitalisation.
+	Carried *big.Rat
+	// TransactionID is the deposit of Paid, empty when less than a cent accrued.
+	TransactionID string
+	CreatedAt     time.Time
+}
+
+// Unpaid is the interest accrued by a wallet and not capitalised yet.
+type Unpaid struct {
+	Amount *big.Rat
+	Days   int
+	// From and To are the first and last days accrued, zero when Days is 0.
+	From time.Time
+	To   time.Time
+}
+
+// Summary is the interest a wallet accrued and was not paid yet.
+type Summary struct {
+	WalletID string `json:"wallet_id"`
+	Product  string `json:"product,omitempty"`
+	Currency string `json:"currency"`
+	// Accrued is the unpaid interest, including the fraction of a cent carried from the last capitalisation.
+	Accrued json.Number `json:"accrued"`
+	// Payable is the part of Accrued paid at the next capitalisation, in whole cents.
+	Payable float64 `json:"payable"`
+	// Days counts the days accrued since the last capitalisation, from AccruedFrom to AccruedTo.
+	Days        int    `json:"days"`
+	AccruedFrom string `json:"accrued_from,omitempty"`
+	AccruedTo   string `json:"accrued_to,omitempty"`
+	// LastPeriodEnd is the end of the period last capitalised.
+	LastPeriodEnd string `json:"last_period_end,omitempty"`
+	// NextPeriodEnd is the end of the period capitalised next, the interest is paid the day after.
+	NextPeriodEnd string `json:"next_period_end,omitempty"`
+}
@@ -0,0 +1,170 @@
+package interest
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/snapshot"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+// OverdraftCharge is the interest and fee of a day a wallet ended overdrawn, charged in whole cents.
+type OverdraftCharge struct {
+	WalletID string
+	Date     time.Time
+	// Balance is the end-of-day balance of the wallet.
+	Balance  float64
+	Interest *big.Rat
+	Fee      float64
+	Charged  float64
+	// Carried is the fraction of a cent left to charge the next day, or the whole charge when the wallet is frozen.
+	Carried *big.Rat
+	// TransactionID is the fee transaction of Charged, empty when nothing was charged.
+	TransactionID string
+}
+
+// ChargeOverdrafts charges the wallets of the tenant in ctx that ended day overdrawn with the overdraft interest
+// on their overdrawn balance and the daily fee of the tenant. Wallets already charged for the day are skipped.
+// It returns the number of recorded charges.
+func (a *Accruer) ChargeOverdrafts(ctx context.Context, day time.Time) (int, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if !t.Overdraft.Charges() {
+		return 0, nil
+	}
+
+	day = snapshot.Day(day)
+
+	report, err := a.balances.EndOfDay(ctx, day)
+	if err != nil {
+		return 0, err
+	}
+
+	charged := 0
+
+	for _, balance := range report.Balances {
+		if balance.Balance >= 0 {
+			continue
+		}
+
+		created, err := a.chargeOverdraft(ctx, &t.Overdraft, balance, day)
+		if err != nil {
+			return charged, err
+		}
+
+		if created {
+			charged++
+		}
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Overdrafts charged",
+		zap.String("date", day.Format(time.DateOnly)),
+		zap.Int("charges", charged),
+	)
+
+	return charged, nil
+}
+
+// chargeOverdraft charges the wallet for day, with the remainder carried from its previous charge, and records
+// the charge in the same database transaction. It returns false when the wallet was already charged for day.
+func (a *Accruer) chargeOverdraft(
+	ctx context.Context,
+	terms *tenant.Overdraft,
+	balance snapshot.Balance,
+	day time.Time,
+) (bool, error) {
+	created := false
+
+	err := a.repo.WithinTx(ctx, func(ctx context.Context) error {
+		last, err := a.repo.LastOverdraftCharge(ctx, balance.WalletID)
+		if err != nil {
+			return err
+		}
+
+		if last != nil && !last.Date.Before(day) {
+			return nil
+		}
+
+		charge, err := overdraftCharge(terms, balance, day)
+		if err != nil {
+			return err
+		}
+
+		due := new(big.Rat).Add(charge.Interest, fromCents(charge.Fee))
+		if last != nil {
+			due.Add(due, last.Carried)
+		}
+
+		cents, carried := wholeCents(due)
+		charge.Carried = carried
+
+		if cents > 0 {
+			reason := "Overdraft " + day.Format(time.DateOnly)
+
+			transaction, err := a.wallets.ChargeFee(ctx, balance.WalletID, float64(cents)/100, reason)
+
+			switch {
+			case errors.Is(err, wallet.ErrWalletFrozen):
+				// NOTE: Frozen wallets are rejected before any change, the charge is carried to the next day.
+				charge.Carried = due
+			case err != nil:
+				return err
+			default:
+				charge.Charged = transaction.Amount
+				charge.TransactionID = transaction.ID
+			}
+		}
+
+		if err := a.repo.CreateOverdraftCharge(ctx, charge); err != nil {
+			return err
+		}
+
+		created = true
+
+		return nil
+	})
+
+	return created, err
+}
+
+// overdraftCharge returns the interest and fee of the overdrawn balance in day.
+func overdraftCharge(terms *tenant.Overdraft, balance snapshot.Balance, day time.Time) (*OverdraftCharge, error) {
+	charge := &OverdraftCharge{
+		WalletID: balance.WalletID,
+		Date:     day,
+		Balance:  balance.Balance,
+		Interest: new(big.Rat),
+	}
+
+	if terms.Rate != "" {
+		rate, err := parseDecimal(terms.Rate.String())
+		if err != nil {
+			return nil, err
+		}
+
+		overdrawn := new(big.Rat).Neg(fromCents(balance.Balance))
+		charge.Interest.Mul(overdrawn, rate)
+		charge.Interest.Quo(charge.Interest, new(big.Rat).SetInt64(100*daysInYear(terms.DayCount, day)))
+	}
+
+	if terms.DailyFee != "" {
+		fee, err := parseDecimal(terms.DailyFee.String())
+		if err != nil {
+			return nil, err
+		}
+
+		charge.Fee, _ = fee.Float64()
+	}
+
+	return charge, nil
+}
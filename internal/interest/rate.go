@@ -0,0 +1,138 @@
+package interest
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+)
+
+// scale is the number of decimals accruals are stored with.
+const scale = 12
+
+// dailyInterest returns the interest earned in day by a wallet of the product whose end-of-day balance is balance.
+// Every band of the balance earns the rate of its tier, negative balances earn nothing.
+func dailyInterest(product *tenant.Product, balance *big.Rat, day time.Time) (*big.Rat, error) {
+	annual := new(big.Rat)
+
+	if balance.Sign() <= 0 {
+		return annual, nil
+	}
+
+	for i, tier := range product.Tiers {
+		from, err := tierFrom(tier)
+		if err != nil {
+			return nil, err
+		}
+
+		if balance.Cmp(from) <= 0 {
+			break
+		}
+
+		upTo := balance
+
+		if i+1 < len(product.Tiers) {
+			next, err := tierFrom(product.Tiers[i+1])
+			if err != nil {
+				return nil, err
+			}
+
+			if next.Cmp(balance) < 0 {
+				upTo = next
+			}
+		}
+
+		rate, err := parseDecimal(tier.Rate.String())
+		if err != nil {
+			return nil, err
+		}
+
+		band := new(big.Rat).Sub(upTo, from)
+		annual.Add(annual, band.Mul(band, rate))
+	}
+
+	// NOTE: Rates are percentages.
+	return annual.Quo(annual, new(big.Rat).SetInt64(100*daysInYear(product.DayCount, day))), nil
+}
+
+func tierFrom(tier tenant.InterestTier) (*big.Rat, error) {
+	if tier.From == "" {
+		return new(big.Rat), nil
+	}
+
+	return parseDecimal(tier.From.String())
+}
+
+// daysInYear returns the denominator of the day-count convention for an accrual in day.
+func daysInYear(dayCount string, day time.Time) int64 {
+	switch dayCount {
+	case tenant.DayCountActual360:
+		return 360
+	case tenant.DayCountActualActual:
+		if time.Date(day.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay() == 366 {
+			return 366
+		}
+
+		return 365
+	default:
+		return 365
+	}
+}
+
+// periodEnds tells whether day is the last day of a capitalisation period.
+func periodEnds(capitalisation string, day time.Time) bool {
+	next := day.AddDate(0, 0, 1)
+
+	switch capitalisation {
+	case tenant.CapitaliseQuarterly:
+		return next.Day() == 1 && next.Month()%3 == 1
+	case tenant.CapitaliseYearly:
+		return next.Day() == 1 && next.Month() == time.January
+	default:
+		return next.Day() == 1
+	}
+}
+
+// nextPeriodEnd returns the last day of the capitalisation period day is in.
+func nextPeriodEnd(capitalisation string, day time.Time) time.Time {
+	for !periodEnds(capitalisation, day) {
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return day
+}
+
+func parseDecimal(value string) (*big.Rat, error) {
+	decimal, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, errors.New("invalid decimal " + value)
+	}
+
+	return decimal, nil
+}
+
+// formatDecimal returns value with the decimals of accruals, the last one rounded half away from zero.
+func formatDecimal(value *big.Rat) string {
+	return value.FloatString(scale)
+}
+
+// fromCents returns the exact value of an amount with two decimals.
+func fromCents(amount float64) *big.Rat {
+	return big.NewRat(int64(math.Round(amount*100)), 100)
+}
+
+// wholeCents returns the cents of value, rounded down, and the remainder.
+func wholeCents(value *big.Rat) (int64, *big.Rat) {
+	hundredths := new(big.Rat).Mul(value, big.NewRat(100, 1))
+	cents := new(big.Int).Quo(hundredths.Num(), hundredths.Denom())
+
+	if hundredths.Sign() < 0 && !hundredths.IsInt() {
+		cents.Sub(cents, big.NewInt(1))
+	}
+
+	paid := new(big.Rat).SetFrac(cents, big.NewInt(100))
+
+	return cents.Int64(), paid.Sub(value, paid)
+}
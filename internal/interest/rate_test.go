@@ -0,0 +1,160 @@
+package interest
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDailyInterest(t *testing.T) {
+	tiers := []tenant.InterestTier{{Rate: "1.5"}, {From: "10000", Rate: "2.25"}}
+
+	tests := []struct {
+		name     string
+		dayCount string
+		balance  string
+		day      time.Time
+		want     *big.Rat
+	}{
+		{"first tier", tenant.DayCountActual365, "1000", date(2025, time.March, 1), big.NewRat(1000*15, 10*100*365)},
+		{"up to the next tier", tenant.DayCountActual365, "10000", date(2025, time.March, 1),
+			big.NewRat(10000*15, 10*100*365)},
+		// 10000 at 1.5% and 5000 at 2.25%, a year's interest of 262.50.
+		{"both tiers act/365", tenant.DayCountActual365, "15000", date(2025, time.March, 1), big.NewRat(26250, 36500)},
+		{"both tiers act/360", tenant.DayCountActual360, "15000", date(2025, time.March, 1), big.NewRat(26250, 36000)},
+		{"act/act leap year", tenant.DayCountActualActual, "15000", date(2024, time.March, 1),
+			big.NewRat(26250, 36600)},
+		{"act/act common year", tenant.DayCountActualActual, "15000", date(2025, time.March, 1),
+			big.NewRat(26250, 36500)},
+		{"cents", tenant.DayCountActual365, "0.01", date(2025, time.March, 1), big.NewRat(15, 10*100*36500)},
+		{"zero balance", tenant.DayCountActual365, "0", date(2025, time.March, 1), new(big.Rat)},
+		{"negative balance", tenant.DayCountActual365, "-500", date(2025, time.March, 1), new(big.Rat)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			product := &tenant.Product{DayCount: tc.dayCount, Tiers: tiers}
+
+			balance, err := parseDecimal(tc.balance)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := dailyInterest(product, balance, tc.day)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Cmp(tc.want) != 0 {
+				t.Errorf("dailyInterest = %s, want %s", got.RatString(), tc.want.RatString())
+			}
+		})
+	}
+}
+
+func TestDailyInterest_WithoutTiers(t *testing.T) {
+	got, err := dailyInterest(&tenant.Product{}, big.NewRat(1000, 1), date(2025, time.March, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Sign() != 0 {
+		t.Errorf("dailyInterest = %s, want 0", got.RatString())
+	}
+}
+
+func TestWholeCents(t *testing.T) {
+	tests := []struct {
+		value     string
+		cents     int64
+		remainder *big.Rat
+	}{
+		{"1.239", 123, big.NewRat(9, 1000)},
+		{"1.23", 123, new(big.Rat)},
+		{"0.004", 0, big.NewRat(4, 1000)},
+		// Negative values round toward minus infinity, so the remainder stays positive.
+		{"-1.231", -124, big.NewRat(9, 1000)},
+		{"-1.23", -123, new(big.Rat)},
+		{"-0.004", -1, big.NewRat(6, 1000)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			value, err := parseDecimal(tc.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cents, remainder := wholeCents(value)
+
+			if cents != tc.cents {
+				t.Errorf("cents = %d, want %d", cents, tc.cents)
+			}
+
+			if remainder.Cmp(tc.remainder) != 0 {
+				t.Errorf("remainder = %s, want %s", remainder.RatString(), tc.remainder.RatString())
+			}
+		})
+	}
+}
+
+func TestFromCents(t *testing.T) {
+	if got := fromCents(10.1); got.Cmp(big.NewRat(1010, 100)) != 0 {
+		t.Errorf("fromCents(10.1) = %s, want 101/10", got.RatString())
+	}
+}
+
+func TestPeriodEnds(t *testing.T) {
+	tests := []struct {
+		capitalisation string
+		day            time.Time
+		want           bool
+	}{
+		{tenant.CapitaliseMonthly, date(2025, time.January, 31), true},
+		{tenant.CapitaliseMonthly, date(2025, time.January, 30), false},
+		{tenant.CapitaliseMonthly, date(2024, time.February, 29), true},
+		{tenant.CapitaliseMonthly, date(2025, time.February, 28), true},
+		{tenant.CapitaliseQuarterly, date(2025, time.March, 31), true},
+		{tenant.CapitaliseQuarterly, date(2025, time.April, 30), false},
+		{tenant.CapitaliseQuarterly, date(2025, time.June, 30), true},
+		{tenant.CapitaliseQuarterly, date(2025, time.December, 31), true},
+		{tenant.CapitaliseYearly, date(2025, time.December, 31), true},
+		{tenant.CapitaliseYearly, date(2025, time.November, 30), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.capitalisation+" "+tc.day.Format(time.DateOnly), func(t *testing.T) {
+			if got := periodEnds(tc.capitalisation, tc.day); got != tc.want {
+				t.Errorf("periodEnds = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNextPeriodEnd(t *testing.T) {
+	tests := []struct {
+		capitalisation string
+		day            time.Time
+		want           time.Time
+	}{
+		{tenant.CapitaliseMonthly, date(2025, time.February, 10), date(2025, time.February, 28)},
+		{tenant.CapitaliseMonthly, date(2025, time.January, 31), date(2025, time.January, 31)},
+		{tenant.CapitaliseQuarterly, date(2025, time.February, 10), date(2025, time.March, 31)},
+		{tenant.CapitaliseQuarterly, date(2025, time.October, 1), date(2025, time.December, 31)},
+		{tenant.CapitaliseYearly, date(2025, time.February, 10), date(2025, time.December, 31)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.capitalisation+" "+tc.day.Format(time.DateOnly), func(t *testing.T) {
+			if got := nextPeriodEnd(tc.capitalisation, tc.day); !got.Equal(tc.want) {
+				t.Errorf("nextPeriodEnd = %s, want %s", got.Format(time.DateOnly), tc.want.Format(time.DateOnly))
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package interest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/database"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
+)
+
+const capitalisationColumns = `id, wallet_id, period_end, accrued, paid, carried, transaction_id, created_at`
+
+type Repository interface {
+	// WithinTx runs fn in a database transaction, see database.RunInTx.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// ProductWallets returns the product of every wallet opened with one, by wallet ID.
+	ProductWallets(ctx context.Context) (map[string]string, error)
+	// CreateAccrual records the accrual unless the wallet already accrued for its day, and tells whether it did.
+	CreateAccrual(ctx context.Context, accrual *Accrual) (bool, error)
+	// Unpaid sums the accruals of the wallet up to until that were not capitalised yet, locked until the end of
+	// the transaction of ctx.
+	Unpaid(ctx context.Context, walletID string, until time.Time) (*Unpaid, error)
+	// UnpaidWallets returns the wallets with accruals up to until that were not capitalised yet.
+	UnpaidWallets(ctx context.Context, until time.Time) ([]string, error)
+	// LastCapitalisation returns the latest capitalisation of the wallet, nil when there is none.
+	LastCapitalisation(ctx context.Context, walletID string) (*Capitalisation, error)
+	// CreateCapitalisation records the capitalisation and marks the unpaid accruals of its period as paid by it.
+	CreateCapitalisation(ctx context.Context, capitalisation *Capitalisation) error
+	// LastOverdraftCharge returns the latest overdraft charge of the wallet, nil when there is none, locked until
+	// the end of the transaction of ctx.
+	LastOverdraftCharge(ctx context.Context, walletID string) (*OverdraftCharge, error)
+	CreateOverdraftCharge(ctx context.Context, charge *OverdraftCharge) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.RunInTx(ctx, r.db, fn)
+}
+
+// currentTenantID returns the tenant every query must be scoped to.
+func currentTenantID(ctx context.Context) (string, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return t.ID, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCapitalisation(row rowScanner) (*Capitalisation, error) {
+	var (
+		capitalisation Capitalisation
+		accrued        string
+		carried        string
+		transactionID  sql.NullString
+	)
+
+	err := row.Scan(&capitalisation.ID, &capitalisation.WalletID, &capitalisation.PeriodEnd, &accrued,
+		&capitalisation.Paid, &carried, &transactionID, &capitalisation.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if capitalisation.Accrued, err = parseDecimal(accrued); err != nil {
+		return nil, err
+	}
+
+	if capitalisation.Carried, err = parseDecimal(carried); err != nil {
+		return nil, err
+	}
+
+	capitalisation.TransactionID = transactionID.String
+
+	return &capitalisation, nil
+}
+
+func (r *repository) ProductWallets(ctx context.Context) (_ map[string]string, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, product FROM wallets WHERE tenant_id = @tenant_id AND product IS NOT NULL`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "interest.Repository/ProductWallets", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, sql.Named("tenant_id", tenantID))
+	if err != nil {
+		return nil, errors.New("failed to list product wallets: " + err.Error())
+	}
+	defer rows.Close()
+
+	products := make(map[string]string)
+
+	for rows.Next() {
+		var walletID, product string
+
+		if err := rows.Scan(&walletID, &product); err != nil {
+			return nil, errors.New("failed to scan product wallet: " + err.Error())
+		}
+
+		products[walletID] = product
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list product wallets: " + err.Error())
+	}
+
+	return products, nil
+}
+
+func (r *repository) CreateAccrual(ctx context.Context, accrual *Accrual) (_ bool, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	query := `INSERT INTO interest_accruals (tenant_id, wallet_id, accrual_date, product, balance, amount, created_at)
+              SELECT @tenant_id, @wallet_id, @accrual_date, @product, @balance, CAST(@amount AS DECIMAL(28,12)),
+                  @created_at
+              WHERE NOT EXISTS (
+                  SELECT 1 FROM interest_accruals WITH (UPDLOCK, HOLDLOCK)
+                  WHERE tenant_id = @tenant_id AND wallet_id = @wallet_id AND accrual_date = @accrual_date
+              )`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "interest.Repository/CreateAccrual", query)
+	defer func() { tracing.End(span, err) }()
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("tenant_id", tenantID),
+		sql.Named("wallet_id", accrual.WalletID),
+		sql.Named("accrual_date", accrual.Date.Format(time.DateOnly)),
+		sql.Named("product", accrual.Product),
+		sql.Named("balance", accrual.Balance),
+		sql.Named("amount", formatDecimal(accrual.Amount)),
+		sql.Named("created_at", time.Now().UTC()),
+	)
+	if err != nil {
+		return false, errors.New("failed to insert interest accrual: " + err.Error())
+	}
+
+	created, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.New("failed to insert interest accrual: " + err.Error())
+	}
+
+	return created > 0, nil
+}
+
+func (r *repository) Unpaid(ctx context.Context, walletID string, until time.Time) (_ *Unpaid, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT COUNT(*), COALESCE(SUM(amount), 0), MIN(accrual_date), MAX(accrual_date)
+              FROM interest_accruals WITH (UPDLOCK, HOLDLOCK)
+              WHERE tenant_id = @tenant_id AND wallet_id = @wallet_id AND capitalisation_id IS NULL
+                  AND accrual_date <= @until`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "interest.Repository/Unpaid", query)
+	defer func() { tracing.End(span, err) }()
+
+	var (
+		unpaid Unpaid
+		amount string
+		from   sql.NullTime
+		to     sql.NullTime
+	)
+
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("tenant_id", tenantID),
+		sql.Named("wallet_id", walletID),
+		sql.Named("until", until.Format(time.DateOnly)),
+	).Scan(&unpaid.Days, &amount, &from, &to)
+	if err != nil {
+		return nil, errors.New("failed to sum unpaid interest: " + err.Error())
+	}
+
+	if unpaid.Amount, err = parseDecimal(amount); err != nil {
+		return nil, err
+	}
+
+	unpaid.From = from.Time
+	unpaid.To = to.Time
+
+	return &unpaid, nil
+}
+
+func (r *repository) UnpaidWallets(ctx context.Context, until time.Time) (_ []string, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT DISTINCT wallet_id FROM interest_accruals
+              WHERE tenant_id = @tenant_id AND capitalisation_id IS NULL AND accrual_date <= @until
+              ORDER BY wallet_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "interest.Repository/UnpaidWallets", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query,
+		sql.Named("tenant_id", tenantID),
+		sql.Named("until", until.Format(time.DateOnly)),
+	)
+	if err != nil {
+		return nil, errors.New("failed to list wallets with unpaid interest: " + err.Error())
+	}
+	defer rows.Close()
+
+	var walletIDs []string
+
+	for rows.Next() {
+		var walletID string
+
+		if err := rows.Scan(&walletID); err != nil {
+			return nil, errors.New("failed to scan wallet with unpaid interest: " + err.Error())
+		}
+
+		walletIDs = append(walletIDs, walletID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list wallets with unpaid interest: " + err.Error())
+	}
+
+	return walletIDs, nil
+}
+
+func (r *repository) LastCapitalisation(ctx context.Context, walletID string) (_ *Capitalisation, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT TOP 1 ` + capitalisationColumns + `
+              FROM interest_capitalisations
+              WHERE tenant_id = @tenant_id AND wallet_id = @wallet_id
+              ORDER BY period_end DESC`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "interest.Repository/LastCapitalisation", query)
+	defer func() { tracing.End(span, err) }()
+
+	capitalisation, err := scanCapitalisation(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("tenant_id", tenantID),
+		sql.Named("wallet_id", walletID),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, errors.New("failed to retrieve interest capitalisation: " + err.Error())
+	}
+
+	return capitalisation, nil
+}
+
+func (r *repository) CreateCapitalisation(ctx context.Context, capitalisation *Capitalisation) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	capitalisation.ID = uuid.New().String()
+	capitalisation.CreatedAt = time.Now().UTC()
+
+	query := `INSERT INTO interest_capitalisations (` + capitalisationColumns + `, tenant_id)
+              VALUES (@id, @wallet_id, @period_end, CAST(@accrued AS DECIMAL(28,12)), @paid,
+                  CAST(@carried AS DECIMAL(28,12)), @transaction_id, @created_at, @tenant_id);
+              UPDATE interest_accruals SET capitalisation_id = @id
+              WHERE tenant_id = @tenant_id AND wallet_id = @wallet_id AND capitalisation_id IS NULL
+                  AND accrual_date <= @period_end`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "interest.Repository/CreateCapitalisation", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("id", capitalisation.ID),
+		sql.Named("tenant_id", tenantID),
+		sql.Named("wallet_id", capitalisation.WalletID),
+		sql.Named("period_end", capitalisation.PeriodEnd.Format(time.DateOnly)),
+		sql.Named("accrued", formatDecimal(capitalisation.Accrued)),
+		sql.Named("paid", capitalisation.Paid),
+		sql.Named("carried", formatDecimal(capitalisation.Carried)),
+		sql.Named("transaction_id", sql.NullString{
+			String: capitalisation.TransactionID,
+			Valid:  capitalisation.TransactionID != "",
+		}),
+		sql.Named("created_at", capitalisation.CreatedAt),
+	)
+	if err != nil {
+		return errors.New("failed to insert interest capitalisation: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *repository) LastOverdraftCharge(ctx context.Context, walletID string) (_ *OverdraftCharge, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT TOP 1 wallet_id, charge_date, balance, interest, fee, charged, carried, transaction_id
+              FROM overdraft_charges WITH (UPDLOCK, HOLDLOCK)
+              WHERE tenant_id = @tenant_id AND wallet_id = @wallet_id
+              ORDER BY charge_date DESC`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "interest.Repository/LastOverdraftCharge", query)
+	defer func() { tracing.End(span, err) }()
+
+	var (
+		charge        OverdraftCharge
+		interest      string
+		carried       string
+		transactionID sql.NullString
+	)
+
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("tenant_id", tenantID),
+		sql.Named("wallet_id", walletID),
+	).Scan(&charge.WalletID, &charge.Date, &charge.Balance, &interest, &charge.Fee, &charge.Charged, &carried,
+		&transactionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, errors.New("failed to retrieve overdraft charge: " + err.Error())
+	}
+
+	if charge.Interest, err = parseDecimal(interest); err != nil {
+		return nil, err
+	}
+
+	if charge.Carried, err = parseDecimal(carried); err != nil {
+		return nil, err
+	}
+
+	charge.TransactionID = transactionID.String
+
+	return &charge, nil
+}
+
+func (r *repository) CreateOverdraftCharge(ctx context.Context, charge *OverdraftCharge) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO overdraft_charges (tenant_id, wallet_id, charge_date, balance, interest, fee, charged,
+                  carried, transaction_id, created_at)
+              VALUES (@tenant_id, @wallet_id, @charge_date, @balance, CAST(@interest AS DECIMAL(28,12)), @fee,
+                  @charged, CAST(@carried AS DECIMAL(28,12)), @transaction_id, @created_at)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "interest.Repository/CreateOverdraftCharge", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("tenant_id", tenantID),
+		sql.Named("wallet_id", charge.WalletID),
+		sql.Named("charge_date", charge.Date.Format(time.DateOnly)),
+		sql.Named("balance", charge.Balance),
+		sql.Named("interest", formatDecimal(charge.Interest)),
+		sql.Named("fee", charge.Fee),
+		sql.Named("charged", charge.Charged),
+		sql.Named("carried", formatDecimal(charge.Carried)),
+		sql.Named("transaction_id", sql.NullString{String: charge.TransactionID, Valid: charge.TransactionID != ""}),
+		sql.Named("created_at", time.Now().UTC()),
+	)
+	if err != nil {
+		return errors.New("failed to insert overdraft charge: " + err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// Package ledger implements the double-entry ledger behind the wallet balances. Every movement of money is a
+// journal of postings that sum up to zero, so money never appears or disappears: a deposit credits the wallet
+// account and debits the cash-in clearing account by the same amount.
+package ledger
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	ErrUnbalancedJournal = errors.New("journal postings do not sum up to zero")
+	ErrInvalidPosting    = errors.New("invalid posting")
+)
+
+// AccountType is the kind of a ledger account.
+type AccountType string
+
+const (
+	// AccountWallet holds the money owed to the owner of a customer wallet.
+	AccountWallet AccountType = "wallet"
+	// AccountCashInClearing is the counterpart of deposits until they are settled with the bank.
+	AccountCashInClearing AccountType = "cash_in_clearing"
+	// AccountCashOutClearing is the counterpart of withdrawals until they are paid out by the bank.
+	AccountCashOutClearing AccountType = "cash_out_clearing"
+	// AccountFees collects the fees charged to wallets.
+	AccountFees AccountType = "fees"
+	// AccountInterestExpense is the counterpart of the interest paid to savings wallets.
+	AccountInterestExpense AccountType = "interest_expense"
+	// AccountEscrow holds the money locked in escrows until it is released to the payee or refunded to the payer.
+	AccountEscrow AccountType = "escrow"
+	// AccountSuspense holds money whose origin is unknown, e.g. opening balances of wallets created before the
+	// ledger, until it is investigated.
+	AccountSuspense AccountType = "suspense"
+)
+
+// Account is a ledger account of a tenant in a single currency. Wallet accounts belong to one wallet,
+// the other accounts exist once per tenant and currency.
+type Account struct {
+	ID        string      `json:"id" db:"id"`
+	TenantID  string      `json:"tenant_id" db:"tenant_id"`
+	Type      AccountType `json:"type" db:"type"`
+	Currency  string      `json:"currency" db:"currency"`
+	WalletID  string      `json:"wallet_id,omitempty" db:"wallet_id"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+// Posting credits, when positive, or debits, when negative, an account by Amount.
+type Posting struct {
+	AccountID string  `json:"account_id" db:"account_id"`
+	Amount    float64 `json:"amount" db:"amount"`
+}
+
+// Journal is a balanced set of postings recording one movement of money in a single currency.
+type Journal struct {
+	ID          string    `json:"id" db:"id"`
+	TenantID    string    `json:"tenant_id" db:"tenant_id"`
+	Currency    string    `json:"currency" db:"currency"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	Postings    []Posting `json:"postings"`
+}
+
+// Validate checks that the journal has at least two postings of whole cents summing up to zero.
+func (j *Journal) Validate() error {
+	if len(j.Postings) < 2 {
+		return ErrInvalidPosting
+	}
+
+	var sum int64
+
+	for _, posting := range j.Postings {
+		if posting.AccountID == "" || !WholeCents(posting.Amount) || toCents(posting.Amount) == 0 {
+			return ErrInvalidPosting
+		}
+
+		sum += toCents(posting.Amount)
+	}
+
+	if sum != 0 {
+		return ErrUnbalancedJournal
+	}
+
+	return nil
+}
+
+// Imbalance is a violation of the ledger invariants.
+type Imbalance struct {
+	TenantID  string  `json:"tenant_id"`
+	Currency  string  `json:"currency"`
+	JournalID string  `json:"journal_id,omitempty"`
+	Sum       float64 `json:"sum"`
+}
+
+// InvariantReport is the outcome of checking that the postings of every journal, and so of every tenant and
+// currency, sum up to zero.
+type InvariantReport struct {
+	Balanced bool `json:"balanced"`
+	// Currencies are the tenant currencies whose postings do not sum up to zero.
+	Currencies []Imbalance `json:"currencies"`
+	// Journals are the journals whose postings do not sum up to zero.
+	Journals []Imbalance `json:"journals"`
+	// WalletBalances counts the wallets whose balance differs from the sum of the postings of their account.
+	WalletBalances int64 `json:"wallet_balances"`
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// WholeCents tells whether amount is a whole number of cents up to floating point precision.
+func WholeCents(amount float64) bool {
+	return math.Abs(amount*100-math.Round(amount*100)) < 1e-6
+}
@@ -0,0 +1,246 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/database"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
+)
+
+// Ledger stores the accounts and journals. Its methods run in the database transaction of the context,
+// so a journal is only kept together with the wallet change it records.
+type Ledger interface {
+	// WalletAccount returns the account of the wallet, opening it on first use.
+	WalletAccount(ctx context.Context, walletID string, currency string) (*Account, error)
+	// SystemAccount returns the account of the given type of the tenant of ctx, opening it on first use.
+	SystemAccount(ctx context.Context, accountType AccountType, currency string) (*Account, error)
+	// Post validates and records the journal.
+	Post(ctx context.Context, journal *Journal) error
+	// CheckInvariants reports the journals and tenant currencies whose postings do not sum up to zero,
+	// across all tenants.
+	CheckInvariants(ctx context.Context) (*InvariantReport, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewLedger(db *sql.DB) Ledger {
+	return &repository{db: db}
+}
+
+func (r *repository) WalletAccount(ctx context.Context, walletID string, currency string) (*Account, error) {
+	return r.account(ctx, AccountWallet, currency, walletID)
+}
+
+func (r *repository) SystemAccount(ctx context.Context, accountType AccountType, currency string) (*Account, error) {
+	if accountType == AccountWallet {
+		return nil, errors.New("wallet accounts belong to a wallet")
+	}
+
+	return r.account(ctx, accountType, currency, "")
+}
+
+// account returns the account, creating it when missing. MERGE with HOLDLOCK keeps concurrent callers from
+// opening the same account twice.
+func (r *repository) account(
+	ctx context.Context,
+	accountType AccountType,
+	currency string,
+	walletID string,
+) (_ *Account, err error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &Account{
+		ID:        uuid.New().String(),
+		TenantID:  t.ID,
+		Type:      accountType,
+		Currency:  currency,
+		WalletID:  walletID,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	merge := `MERGE ledger_accounts WITH (HOLDLOCK) AS target
+              USING (SELECT @tenant_id, @type, @currency, @wallet_id) AS source (tenant_id, type, currency, wallet_id)
+              ON target.tenant_id = source.tenant_id AND target.type = source.type
+                  AND target.currency = source.currency
+                  AND (target.wallet_id = source.wallet_id OR (target.wallet_id IS NULL AND source.wallet_id IS NULL))
+              WHEN NOT MATCHED THEN
+                  INSERT (id, tenant_id, type, currency, wallet_id, created_at)
+                  VALUES (@id, @tenant_id, @type, @currency, @wallet_id, @created_at);`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "ledger.Ledger/Account", merge)
+	defer func() { tracing.End(span, err) }()
+
+	walletIDArg := sql.NullString{String: walletID, Valid: walletID != ""}
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, merge,
+		sql.Named("id", account.ID),
+		sql.Named("tenant_id", account.TenantID),
+		sql.Named("type", string(account.Type)),
+		sql.Named("currency", account.Currency),
+		sql.Named("wallet_id", walletIDArg),
+		sql.Named("created_at", account.CreatedAt),
+	)
+	if err != nil {
+		return nil, errors.New("failed to open ledger account: " + err.Error())
+	}
+
+	query := `SELECT id, created_at FROM ledger_accounts
+              WHERE tenant_id = @tenant_id AND type = @type AND currency = @currency
+                  AND (wallet_id = @wallet_id OR (wallet_id IS NULL AND @wallet_id IS NULL))`
+
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("tenant_id", account.TenantID),
+		sql.Named("type", string(account.Type)),
+		sql.Named("currency", account.Currency),
+		sql.Named("wallet_id", walletIDArg),
+	).Scan(&account.ID, &account.CreatedAt)
+	if err != nil {
+		return nil, errors.New("failed to retrieve ledger account: " + err.Error())
+	}
+
+	return account, nil
+}
+
+func (r *repository) Post(ctx context.Context, journal *Journal) (err error) {
+	if err := journal.Validate(); err != nil {
+		return err
+	}
+
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	journal.ID = uuid.New().String()
+	journal.TenantID = t.ID
+	journal.CreatedAt = time.Now().UTC()
+
+	query := `INSERT INTO journals (id, tenant_id, currency, description, created_at)
+              VALUES (@id, @tenant_id, @currency, @description, @created_at)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "ledger.Ledger/Post", query)
+	defer func() { tracing.End(span, err) }()
+
+	conn := database.Conn(ctx, r.db)
+
+	_, err = conn.ExecContext(ctx, query,
+		sql.Named("id", journal.ID),
+		sql.Named("tenant_id", journal.TenantID),
+		sql.Named("currency", journal.Currency),
+		sql.Named("description", journal.Description),
+		sql.Named("created_at", journal.CreatedAt),
+	)
+	if err != nil {
+		return errors.New("failed to insert journal: " + err.Error())
+	}
+
+	// The account must belong to the tenant and currency of the journal, so postings never cross them.
+	posting := `INSERT INTO postings (journal_id, account_id, amount)
+                SELECT @journal_id, id, @amount FROM ledger_accounts
+                WHERE id = @account_id AND tenant_id = @tenant_id AND currency = @currency`
+
+	for _, p := range journal.Postings {
+		result, err := conn.ExecContext(ctx, posting,
+			sql.Named("journal_id", journal.ID),
+			sql.Named("account_id", p.AccountID),
+			sql.Named("amount", p.Amount),
+			sql.Named("tenant_id", journal.TenantID),
+			sql.Named("currency", journal.Currency),
+		)
+		if err != nil {
+			return errors.New("failed to insert posting: " + err.Error())
+		}
+
+		if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+			return ErrInvalidPosting
+		}
+	}
+
+	return nil
+}
+
+func (r *repository) CheckInvariants(ctx context.Context) (_ *InvariantReport, err error) {
+	report := &InvariantReport{
+		Currencies: make([]Imbalance, 0),
+		Journals:   make([]Imbalance, 0),
+	}
+
+	currencies := `SELECT a.tenant_id, a.currency, SUM(p.amount)
+                   FROM postings p JOIN ledger_accounts a ON a.id = p.account_id
+                   GROUP BY a.tenant_id, a.currency
+                   HAVING SUM(p.amount) <> 0`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "ledger.Ledger/CheckInvariants", currencies)
+	defer func() { tracing.End(span, err) }()
+
+	report.Currencies, err = r.imbalances(ctx, currencies, false)
+	if err != nil {
+		return nil, err
+	}
+
+	journals := `SELECT j.tenant_id, j.currency, j.id, COALESCE(SUM(p.amount), 0)
+                 FROM journals j LEFT JOIN postings p ON p.journal_id = j.id
+                 GROUP BY j.tenant_id, j.currency, j.id
+                 HAVING COALESCE(SUM(p.amount), 0) <> 0 OR COUNT(p.id) < 2`
+
+	report.Journals, err = r.imbalances(ctx, journals, true)
+	if err != nil {
+		return nil, err
+	}
+
+	walletBalances := `SELECT COUNT(*) FROM wallets w
+                       WHERE w.balance <> (SELECT COALESCE(SUM(p.amount), 0)
+                           FROM postings p JOIN ledger_accounts a ON a.id = p.account_id
+                           WHERE a.wallet_id = w.id)`
+
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, walletBalances).Scan(&report.WalletBalances)
+	if err != nil {
+		return nil, errors.New("failed to check wallet balances: " + err.Error())
+	}
+
+	report.Balanced = len(report.Currencies) == 0 && len(report.Journals) == 0 && report.WalletBalances == 0
+
+	return report, nil
+}
+
+func (r *repository) imbalances(ctx context.Context, query string, withJournal bool) ([]Imbalance, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.New("failed to check ledger invariants: " + err.Error())
+	}
+	defer rows.Close()
+
+	imbalances := make([]Imbalance, 0)
+
+	for rows.Next() {
+		var imbalance Imbalance
+
+		dest := []interface{}{&imbalance.TenantID, &imbalance.Currency}
+		if withJournal {
+			dest = append(dest, &imbalance.JournalID)
+		}
+
+		if err := rows.Scan(append(dest, &imbalance.Sum)...); err != nil {
+			return nil, errors.New("failed to scan ledger imbalance: " + err.Error())
+		}
+
+		imbalances = append(imbalances, imbalance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to check ledger invariants: " + err.Error())
+	}
+
+	return imbalances, nil
+}
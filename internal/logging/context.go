@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+)
+
+type contextKey struct{}
+
+var nopLogger logger.StructuredLogger = logger.NewStructuredNopLogger(logger.LogLevelInfo)
+
+// NewContext returns a copy of ctx carrying the request-scoped logger.
+func NewContext(ctx context.Context, log logger.StructuredLogger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// FromContext returns the request-scoped logger stored in ctx, falling back to fallback
+// or to a no-op logger when fallback is nil.
+func FromContext(ctx context.Context, fallback logger.StructuredLogger) logger.StructuredLogger {
+	log, ok := ctx.Value(contextKey{}).(logger.StructuredLogger)
+	if ok {
+		return log
+	}
+
+	if fallback != nil {
+		return fallback
+	}
+
+	return nopLogger
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"os"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/config"
+)
+
+var _ logger.StructuredLogger = (*Logger)(nil)
+
+// encoderConfig matches the encoder of logger.NewZapLogger, so log lines keep their format.
+var encoderConfig = zapcore.EncoderConfig{
+	MessageKey:     "msg",
+	LevelKey:       "level",
+	TimeKey:        "time",
+	NameKey:        "logger",
+	CallerKey:      "caller",
+	StacktraceKey:  "stacktrace",
+	LineEnding:     zapcore.DefaultLineEnding,
+	EncodeLevel:    zapcore.LowercaseLevelEncoder,
+	EncodeTime:     zapcore.ISO8601TimeEncoder,
+	EncodeDuration: zapcore.SecondsDurationEncoder,
+	EncodeCaller:   zapcore.ShortCallerEncoder,
+}
+
+// Logger is a JSON zap logger whose level can be changed at runtime.
+type Logger struct {
+	*zap.Logger
+	level zap.AtomicLevel
+}
+
+func NewLogger(cfg config.Log) (*Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid log level %s", cfg.Level)
+	}
+
+	atomicLevel := zap.NewAtomicLevelAt(level)
+
+	var cores []zapcore.Core
+
+	if cfg.StdoutEnabled {
+		cores = append(cores, zapcore.NewCore(
+			zapcore.NewJSONEncoder(encoderConfig),
+			zapcore.Lock(os.Stdout),
+			atomicLevel,
+		))
+	}
+
+	return &Logger{
+		Logger: zap.New(zapcore.NewTee(cores...), zap.AddCaller()),
+		level:  atomicLevel,
+	}, nil
+}
+
+func (l *Logger) GetLevel() zapcore.Level {
+	return l.level.Level()
+}
+
+// Level returns the level shared by the logger and its children. It serves `GET` and `PUT`
+// requests with a `{"level":"debug"}` body to read and change the level.
+func (l *Logger) Level() zap.AtomicLevel {
+	return l.level
+}
+
+func (l *Logger) With(fields ...zap.Field) logger.StructuredLogger {
+	return &Logger{
+		Logger: l.Logger.With(fields...),
+		level:  l.level,
+	}
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// NOTE: Requests not matching any route share one label value to keep the cardinality bounded.
+const unmatchedRoute = "unmatched"
+
+// Middleware records the count and latency of requests per chi route pattern.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		defer func() {
+			route := unmatchedRoute
+			if routeCtx := chi.RouteContext(r.Context()); routeCtx != nil && routeCtx.RoutePattern() != "" {
+				route = routeCtx.RoutePattern()
+			}
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			m.httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+			m.httpRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+		}()
+
+		next.ServeHTTP(ww, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// CountPanics wraps the response writer of http.Recovery to count the recovered panics.
+func (m *Metrics) CountPanics(responseWriter http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.httpPanics.Inc()
+		responseWriter(w, r)
+	}
+}
@@ -0,0 +1,248 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "wallet"
+
+// Metrics holds the Prometheus collectors of the service.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	httpPanics          prometheus.Counter
+
+	deposits          *prometheus.CounterVec
+	withdrawals       *prometheus.CounterVec
+	reversals         *prometheus.CounterVec
+	transfers         *prometheus.CounterVec
+	interestPayments  *prometheus.CounterVec
+	fees              *prometheus.CounterVec
+	escrowHolds       *prometheus.CounterVec
+	escrowReleases    *prometheus.CounterVec
+	escrowRefunds     *prometheus.CounterVec
+	adjustments       *prometheus.CounterVec
+	volume            *prometheus.CounterVec
+	insufficientFunds *prometheus.CounterVec
+
+	reconciliationMismatches prometheus.Gauge
+	reconciliationLastRun    prometheus.Gauge
+	reconciliationFrozen     prometheus.Counter
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by method, chi route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of HTTP requests by method and chi route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		httpPanics: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "panics_total",
+			Help:      "Number of panics recovered while serving HTTP requests.",
+		}),
+		deposits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "deposits_total",
+			Help:      "Number of successful deposits by currency.",
+		}, []string{"currency"}),
+		withdrawals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "withdrawals_total",
+			Help:      "Number of successful withdrawals by currency.",
+		}, []string{"currency"}),
+		reversals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reversals_total",
+			Help:      "Number of successful reversals by currency.",
+		}, []string{"currency"}),
+		transfers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transfers_total",
+			Help:      "Number of successful transfers between wallets by currency.",
+		}, []string{"currency"}),
+		interestPayments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "interest_payments_total",
+			Help:      "Number of interest capitalisations paid into savings wallets by currency.",
+		}, []string{"currency"}),
+		fees: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fees_total",
+			Help:      "Number of fees charged to wallets by currency.",
+		}, []string{"currency"}),
+		escrowHolds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "escrow_holds_total",
+			Help:      "Number of payments held in escrow by currency.",
+		}, []string{"currency"}),
+		escrowReleases: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "escrow_releases_total",
+			Help:      "Number of escrow payments released to payees by currency.",
+		}, []string{"currency"}),
+		escrowRefunds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "escrow_refunds_total",
+			Help:      "Number of escrow payments refunded to payers by currency.",
+		}, []string{"currency"}),
+		adjustments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "adjustments_total",
+			Help:      "Number of approved balance adjustments booked by currency.",
+		}, []string{"currency"}),
+		volume: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "volume_total",
+			Help:      "Amount moved by successful operations by operation and currency.",
+		}, []string{"operation", "currency"}),
+		insufficientFunds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "insufficient_funds_total",
+			Help:      "Number of withdrawals rejected for insufficient funds by currency.",
+		}, []string{"currency"}),
+		reconciliationMismatches: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "reconciliation",
+			Name:      "mismatches",
+			Help:      "Number of wallets whose balance did not reconcile in the last reconciliation run.",
+		}),
+		reconciliationLastRun: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "reconciliation",
+			Name:      "last_run_timestamp_seconds",
+			Help:      "Unix time of the last finished reconciliation run.",
+		}),
+		reconciliationFrozen: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "reconciliation",
+			Name:      "frozen_wallets_total",
+			Help:      "Number of wallets frozen by reconciliation runs.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.httpPanics,
+		m.deposits,
+		m.withdrawals,
+		m.reversals,
+		m.transfers,
+		m.interestPayments,
+		m.fees,
+		m.escrowHolds,
+		m.escrowReleases,
+		m.escrowRefunds,
+		m.adjustments,
+		m.volume,
+		m.insufficientFunds,
+		m.reconciliationMismatches,
+		m.reconciliationLastRun,
+		m.reconciliationFrozen,
+	)
+
+	return m
+}
+
+// RegisterDB exports the connection pool statistics of db.
+func (m *Metrics) RegisterDB(db *sql.DB, dbName string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Deposited implements wallet.Observer.
+func (m *Metrics) Deposited(currency string, amount float64) {
+	m.deposits.WithLabelValues(currency).Inc()
+	m.volume.WithLabelValues("deposit", currency).Add(amount)
+}
+
+// Withdrawn implements wallet.Observer.
+func (m *Metrics) Withdrawn(currency string, amount float64) {
+	m.withdrawals.WithLabelValues(currency).Inc()
+	m.volume.WithLabelValues("withdrawal", currency).Add(amount)
+}
+
+// Reversed implements wallet.Observer.
+func (m *Metrics) Reversed(currency string, amount float64) {
+	m.reversals.WithLabelValues(currency).Inc()
+	m.volume.WithLabelValues("reversal", currency).Add(amount)
+}
+
+// Transferred implements wallet.Observer.
+func (m *Metrics) Transferred(currency string, amount float64) {
+	m.transfers.WithLabelValues(currency).Inc()
+	m.volume.WithLabelValues("transfer", currency).Add(amount)
+}
+
+// InterestPaid implements wallet.Observer.
+func (m *Metrics) InterestPaid(currency string, amount float64) {
+	m.interestPayments.WithLabelValues(currency).Inc()
+	m.volume.WithLabelValues("interest", currency).Add(amount)
+}
+
+// FeeCharged implements wallet.Observer.
+func (m *Metrics) FeeCharged(currency string, amount float64) {
+	m.fees.WithLabelValues(currency).Inc()
+	m.volume.WithLabelValues("fee", currency).Add(amount)
+}
+
+// EscrowHeld implements wallet.Observer.
+func (m *Metrics) EscrowHeld(currency string, amount float64) {
+	m.escrowHolds.WithLabelValues(currency).Inc()
+	m.volume.WithLabelValues("escrow_hold", currency).Add(amount)
+}
+
+// EscrowReleased implements wallet.Observer.
+func (m *Metrics) EscrowReleased(currency string, amount float64) {
+	m.escrowReleases.WithLabelValues(currency).Inc()
+	m.volume.WithLabelValues("escrow_release", currency).Add(amount)
+}
+
+// EscrowRefunded implements wallet.Observer.
+func (m *Metrics) EscrowRefunded(currency string, amount float64) {
+	m.escrowRefunds.WithLabelValues(currency).Inc()
+	m.volume.WithLabelValues("escrow_refund", currency).Add(amount)
+}
+
+// Adjusted implements wallet.Observer.
+func (m *Metrics) Adjusted(currency string, amount float64) {
+	m.adjustments.WithLabelValues(currency).Inc()
+	m.volume.WithLabelValues("adjustment", currency).Add(amount)
+}
+
+// InsufficientFunds implements wallet.Observer.
+func (m *Metrics) InsufficientFunds(currency string) {
+	m.insufficientFunds.WithLabelValues(currency).Inc()
+}
+
+// Reconciled implements reconciliation.Observer.
+func (m *Metrics) Reconciled(mismatches, frozen int) {
+	m.reconciliationMismatches.Set(float64(mismatches))
+	m.reconciliationLastRun.SetToCurrentTime()
+	m.reconciliationFrozen.Add(float64(frozen))
+}
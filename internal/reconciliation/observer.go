@@ -0,0 +1,10 @@
+package reconciliation
+
+// Observer is notified about finished reconciliation runs, e.g. to record metrics.
+type Observer interface {
+	Reconciled(mismatches, frozen int)
+}
+
+type nopObserver struct{}
+
+func (nopObserver) Reconciled(int, int) {}
@@ -0,0 +1,255 @@
+// Package reconciliation recomputes the wallet balances from their recorded movements to detect balances
+// changed outside of the service, e.g. by SQL run against `wallets` directly.
+package reconciliation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/database"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+// Mismatch is a wallet whose stored balance differs from the sum of its transactions or of its ledger postings.
+type Mismatch struct {
+	TenantID         string        `json:"tenant_id"`
+	WalletID         string        `json:"wallet_id"`
+	Currency         string        `json:"currency"`
+	Status           wallet.Status `json:"status"`
+	Balance          float64       `json:"balance"`
+	MovementsBalance float64       `json:"movements_balance"`
+	LedgerBalance    float64       `json:"ledger_balance"`
+	Difference       float64       `json:"difference"`
+	// Frozen tells whether the wallet was frozen by this reconciliation.
+	Frozen bool `json:"frozen"`
+}
+
+// balanceColumns are the stored balance of the wallet w and the sums of its transactions and of its ledger postings.
+const balanceColumns = `w.balance,
+                  COALESCE((SELECT SUM(CASE t.direction WHEN 'credit' THEN t.amount ELSE -t.amount END)
+                      FROM transactions t WHERE t.wallet_id = w.id), 0),
+                  COALESCE((SELECT SUM(p.amount)
+                      FROM postings p JOIN ledger_accounts a ON a.id = p.account_id
+                      WHERE a.wallet_id = w.id), 0)`
+
+// Report is the outcome of a reconciliation run.
+type Report struct {
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    time.Time  `json:"finished_at"`
+	Wallets       int64      `json:"wallets"`
+	Mismatches    []Mismatch `json:"mismatches"`
+	FrozenWallets int        `json:"frozen_wallets"`
+}
+
+type Reconciler struct {
+	db       *sql.DB
+	wallets  wallet.Service
+	observer Observer
+}
+
+type Option func(*Reconciler)
+
+// WithObserver sets the Observer notified about finished runs.
+func WithObserver(observer Observer) Option {
+	return func(r *Reconciler) {
+		r.observer = observer
+	}
+}
+
+// NewReconciler creates a Reconciler freezing drifting wallets through the wallet service.
+func NewReconciler(db *sql.DB, wallets wallet.Service, options ...Option) *Reconciler {
+	r := &Reconciler{
+		db:       db,
+		wallets:  wallets,
+		observer: nopObserver{},
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+
+	return r
+}
+
+// Run compares the balance of every wallet of every tenant with the sum of its transactions and of its ledger
+// postings. With freeze, active wallets that do not reconcile are frozen.
+func (r *Reconciler) Run(ctx context.Context, freeze bool) (*Report, error) {
+	report := &Report{
+		StartedAt:  time.Now().UTC(),
+		Mismatches: make([]Mismatch, 0),
+	}
+
+	if err := r.compare(ctx, report); err != nil {
+		return nil, err
+	}
+
+	if freeze {
+		mismatches := report.Mismatches[:0]
+
+		for i := range report.Mismatches {
+			mismatch := report.Mismatches[i]
+
+			drifts, err := r.freeze(ctx, &mismatch)
+			if err != nil {
+				return nil, err
+			}
+
+			if !drifts {
+				continue
+			}
+
+			if mismatch.Frozen {
+				report.FrozenWallets++
+			}
+
+			mismatches = append(mismatches, mismatch)
+		}
+
+		report.Mismatches = mismatches
+	}
+
+	report.FinishedAt = time.Now().UTC()
+
+	r.observer.Reconciled(len(report.Mismatches), report.FrozenWallets)
+
+	logging.FromContext(ctx, nil).Info(
+		"Reconciliation finished",
+		zap.Int64("wallets", report.Wallets),
+		zap.Int("mismatches", len(report.Mismatches)),
+		zap.Int("frozen_wallets", report.FrozenWallets),
+	)
+
+	return report, nil
+}
+
+func (r *Reconciler) compare(ctx context.Context, report *Report) (err error) {
+	// NOTE: The wallets are read without a snapshot, so a movement committed between reading a wallet and summing
+	// its transactions shows up as a mismatch. Mismatches are checked again before freezing, see freeze.
+	query := `SELECT w.tenant_id, w.id, w.currency, w.status, ` + balanceColumns + `
+              FROM wallets w
+              ORDER BY w.tenant_id, w.id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "reconciliation.Reconciler/Run", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return errors.New("failed to read wallet balances: " + err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mismatch Mismatch
+
+		err := rows.Scan(&mismatch.TenantID, &mismatch.WalletID, &mismatch.Currency, &mismatch.Status,
+			&mismatch.Balance, &mismatch.MovementsBalance, &mismatch.LedgerBalance)
+		if err != nil {
+			return errors.New("failed to scan wallet balances: " + err.Error())
+		}
+
+		report.Wallets++
+
+		if mismatch.drifts() {
+			report.Mismatches = append(report.Mismatches, mismatch)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.New("failed to read wallet balances: " + err.Error())
+	}
+
+	return nil
+}
+
+// freeze checks the mismatch again under the lock of the wallet, which every movement takes before changing the
+// balance, and freezes the wallet in the same database transaction when it still drifts. It returns false when the
+// wallet reconciles after all, e.g. when a movement was committed while compare read it.
+func (r *Reconciler) freeze(ctx context.Context, mismatch *Mismatch) (bool, error) {
+	if mismatch.Status == wallet.StatusFrozen {
+		return true, nil
+	}
+
+	// The tenant only scopes the wallet queries, its limits do not apply to freezing.
+	ctx = tenant.NewContext(ctx, &tenant.Tenant{ID: mismatch.TenantID})
+
+	var drifts bool
+
+	err := database.RunInTx(ctx, r.db, func(ctx context.Context) error {
+		var err error
+
+		drifts, err = r.recheck(ctx, mismatch)
+		if err != nil || !drifts || mismatch.Status == wallet.StatusFrozen {
+			return err
+		}
+
+		reason := fmt.Sprintf(
+			"reconciliation: balance %.2f differs from movements %.2f and ledger %.2f",
+			mismatch.Balance, mismatch.MovementsBalance, mismatch.LedgerBalance,
+		)
+
+		if _, err := r.wallets.Freeze(ctx, mismatch.WalletID, reason); err != nil {
+			return errors.New("failed to freeze wallet " + mismatch.WalletID + ": " + err.Error())
+		}
+
+		mismatch.Status = wallet.StatusFrozen
+		mismatch.Frozen = true
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if !drifts {
+		logging.FromContext(ctx, nil).Info(
+			"Wallet reconciled when checked again",
+			zap.String("tenant_id", mismatch.TenantID),
+			zap.String("wallet_id", mismatch.WalletID),
+		)
+	}
+
+	return drifts, nil
+}
+
+// recheck reads the balances of the mismatching wallet again, locking it until the end of the transaction of ctx,
+// and tells whether it still drifts.
+func (r *Reconciler) recheck(ctx context.Context, mismatch *Mismatch) (_ bool, err error) {
+	query := `SELECT w.status, ` + balanceColumns + `
+              FROM wallets w WITH (UPDLOCK, ROWLOCK)
+              WHERE w.id = @id AND w.tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "reconciliation.Reconciler/Recheck", query)
+	defer func() { tracing.End(span, err) }()
+
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("id", mismatch.WalletID),
+		sql.Named("tenant_id", mismatch.TenantID),
+	).Scan(&mismatch.Status, &mismatch.Balance, &mismatch.MovementsBalance, &mismatch.LedgerBalance)
+	if err != nil {
+		return false, errors.New("failed to read wallet balances: " + err.Error())
+	}
+
+	return mismatch.drifts(), nil
+}
+
+// drifts tells whether the balance differs from the movements or the ledger, and sets the difference to the
+// movements.
+func (m *Mismatch) drifts() bool {
+	balance := toCents(m.Balance)
+	m.Difference = float64(balance-toCents(m.MovementsBalance)) / 100
+
+	return balance != toCents(m.MovementsBalance) || balance != toCents(m.LedgerBalance)
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
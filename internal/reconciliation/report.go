@@ -0,0 +1,62 @@
+package reconciliation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// WriteReport writes the report as JSON, or as CSV with one line per mismatch.
+func WriteReport(w io.Writer, report *Report, format string) error {
+	if format == FormatCSV {
+		return writeCSV(w, report)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+func writeCSV(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{
+		"tenant_id", "wallet_id", "currency", "status", "balance", "movements_balance", "ledger_balance",
+		"difference", "frozen",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, mismatch := range report.Mismatches {
+		err := writer.Write([]string{
+			mismatch.TenantID,
+			mismatch.WalletID,
+			mismatch.Currency,
+			string(mismatch.Status),
+			formatAmount(mismatch.Balance),
+			formatAmount(mismatch.MovementsBalance),
+			formatAmount(mismatch.LedgerBalance),
+			formatAmount(mismatch.Difference),
+			strconv.FormatBool(mismatch.Frozen),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,332 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+const (
+	// DefaultRetryInterval is how long a failed occurrence waits before it is retried.
+	DefaultRetryInterval = time.Hour
+	// DefaultMaxRetries is how many times a failed occurrence is retried before it is skipped, or its schedule
+	// cancelled when it failed for another reason than the transfer.
+	DefaultMaxRetries = 3
+)
+
+// transferErrors are the errors failing an occurrence. Other errors are retried until the retries run out,
+// then the schedule is cancelled.
+var transferErrors = []error{
+	wallet.ErrWalletNotFound,
+	wallet.ErrInvalidAmount,
+	wallet.ErrInsufficientFunds,
+	wallet.ErrLimitExceeded,
+	wallet.ErrWalletFrozen,
+	wallet.ErrSameWallet,
+	wallet.ErrCurrencyMismatch,
+}
+
+// Executor runs the due schedules of every tenant. The transfer of an occurrence, its run and the move of the
+// schedule to its next occurrence are committed in one database transaction holding the lock of the schedule,
+// so every occurrence transfers the money exactly once even when several executors run or one stops midway.
+type Executor struct {
+	repo          Repository
+	wallets       wallet.Service
+	tenants       *tenant.Registry
+	retryInterval time.Duration
+	maxRetries    int
+}
+
+type ExecutorOption func(*Executor)
+
+// WithRetryInterval sets how long a failed occurrence waits before it is retried, DefaultRetryInterval otherwise.
+func WithRetryInterval(interval time.Duration) ExecutorOption {
+	return func(e *Executor) {
+		e.retryInterval = interval
+	}
+}
+
+// WithMaxRetries sets how many times a failed occurrence is retried, DefaultMaxRetries otherwise.
+func WithMaxRetries(maxRetries int) ExecutorOption {
+	return func(e *Executor) {
+		e.maxRetries = maxRetries
+	}
+}
+
+// NewExecutor creates an Executor transferring with the limits of the tenants of the registry.
+func NewExecutor(
+	repo Repository,
+	wallets wallet.Service,
+	tenants *tenant.Registry,
+	options ...ExecutorOption,
+) *Executor {
+	e := &Executor{
+		repo:          repo,
+		wallets:       wallets,
+		tenants:       tenants,
+		retryInterval: DefaultRetryInterval,
+		maxRetries:    DefaultMaxRetries,
+	}
+
+	for _, opt := range options {
+		opt(e)
+	}
+
+	return e
+}
+
+// RunNext runs the schedule due the longest. It returns false when no schedule is due.
+func (e *Executor) RunNext(ctx context.Context) (bool, error) {
+	var due *Schedule
+
+	err := e.repo.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		due, err = e.repo.LockDue(ctx, time.Now().UTC())
+		if err != nil || due == nil {
+			return err
+		}
+
+		ctx, err = e.scheduleContext(ctx, due)
+		if err != nil {
+			// The tenant only scopes the schedule queries, it was removed from the configuration.
+			return e.cancel(tenant.NewContext(ctx, &tenant.Tenant{ID: due.TenantID}), due, err)
+		}
+
+		transaction, err := e.wallets.Transfer(ctx, due.FromWalletID, due.ToWalletID, due.Amount, due.Reference)
+		if err != nil {
+			return err
+		}
+
+		return e.succeed(ctx, due, transaction)
+	})
+
+	// NOTE: An interrupted run is retried as is, e.g. when the executor stops.
+	if err == nil || due == nil || ctx.Err() != nil {
+		return due != nil, err
+	}
+
+	failure := err
+
+	// NOTE: The failed run was rolled back with the transaction, its failure is recorded in another one.
+	return true, e.repo.WithinTx(ctx, func(ctx context.Context) error {
+		ctx, err := e.scheduleContext(ctx, due)
+		if err != nil {
+			return err
+		}
+
+		schedule, err := e.repo.GetForUpdate(ctx, due.ID)
+		if err != nil {
+			return err
+		}
+
+		// Another executor recorded the failure, or the schedule was changed, in between.
+		if schedule.Status != StatusActive || !schedule.NextRunAt.Equal(*due.NextRunAt) ||
+			schedule.Attempts != due.Attempts {
+			return nil
+		}
+
+		if !isTransferError(failure) {
+			return e.postpone(ctx, schedule, failure)
+		}
+
+		return e.fail(ctx, schedule, failure)
+	})
+}
+
+// scheduleContext returns ctx with the logger, tenant and audit actor of the schedule, whose transfers are
+// audited on behalf of whoever created it.
+func (e *Executor) scheduleContext(ctx context.Context, schedule *Schedule) (context.Context, error) {
+	log := logging.FromContext(ctx, nil).With(
+		zap.String("schedule_id", schedule.ID),
+		zap.String("tenant_id", schedule.TenantID),
+	)
+
+	ctx = logging.NewContext(ctx, log)
+	ctx = audit.NewContext(ctx, schedule.CreatedBy)
+
+	scheduleTenant, err := e.tenants.ByID(schedule.TenantID)
+	if err != nil {
+		return ctx, err
+	}
+
+	return tenant.NewContext(ctx, scheduleTenant), nil
+}
+
+func (e *Executor) succeed(ctx context.Context, schedule *Schedule, transaction *wallet.Transaction) error {
+	now := time.Now().UTC()
+
+	run := &Run{
+		ScheduleID:    schedule.ID,
+		DueAt:         *schedule.NextRunAt,
+		Attempt:       schedule.Attempts + 1,
+		Status:        RunSucceeded,
+		TransactionID: transaction.ID,
+		ExecutedAt:    now,
+	}
+
+	if err := e.repo.CreateRun(ctx, run); err != nil {
+		return err
+	}
+
+	schedule.LastRunAt = &now
+	schedule.LastError = ""
+
+	if err := schedule.advance(); err != nil {
+		return err
+	}
+
+	if err := e.repo.Update(ctx, schedule); err != nil {
+		return err
+	}
+
+	e.repo.AfterCommit(ctx, func() {
+		logging.FromContext(ctx, nil).Info(
+			"Scheduled transfer completed",
+			zap.Time("due_at", run.DueAt),
+			zap.String("transaction_id", transaction.ID),
+			zap.Timep("next_run_at", schedule.NextRunAt),
+		)
+	})
+
+	return nil
+}
+
+// fail records the failed attempt of the next occurrence of the schedule. Occurrences failing for insufficient
+// funds are retried when the schedule asks for it, as long as the retry comes before the following occurrence.
+func (e *Executor) fail(ctx context.Context, schedule *Schedule, failure error) error {
+	now := time.Now().UTC()
+
+	run := &Run{
+		ScheduleID: schedule.ID,
+		DueAt:      *schedule.NextRunAt,
+		Attempt:    schedule.Attempts + 1,
+		Status:     RunSkipped,
+		Error:      failure.Error(),
+		ExecutedAt: now,
+	}
+
+	following, err := schedule.following()
+	if err != nil {
+		return err
+	}
+
+	retryAt := now.Add(e.retryInterval)
+
+	if errors.Is(failure, wallet.ErrInsufficientFunds) && schedule.OnInsufficientFunds == PolicyRetry &&
+		schedule.Attempts < e.maxRetries && (following == nil || retryAt.Before(*following)) {
+		run.Status = RunFailed
+	}
+
+	if err := e.repo.CreateRun(ctx, run); err != nil {
+		return err
+	}
+
+	schedule.LastRunAt = &now
+	schedule.LastError = failure.Error()
+
+	if run.Status == RunFailed {
+		schedule.Attempts++
+		schedule.RetryAt = &retryAt
+	} else if err := schedule.advance(); err != nil {
+		return err
+	}
+
+	if err := e.repo.Update(ctx, schedule); err != nil {
+		return err
+	}
+
+	e.repo.AfterCommit(ctx, func() {
+		logging.FromContext(ctx, nil).Warn(
+			"Scheduled transfer failed",
+			zap.Time("due_at", run.DueAt),
+			zap.String("status", string(run.Status)),
+			logger.ErrorField(failure),
+			zap.Timep("retry_at", schedule.RetryAt),
+			zap.Timep("next_run_at", schedule.NextRunAt),
+		)
+	})
+
+	return nil
+}
+
+// postpone records the attempt of the next occurrence of the schedule interrupted by another error than a failed
+// transfer, e.g. the database being unavailable, and retries it after the retry interval. The schedule is cancelled
+// once the retries run out rather than skipping occurrences for errors that are not theirs.
+func (e *Executor) postpone(ctx context.Context, schedule *Schedule, cause error) error {
+	if schedule.Attempts >= e.maxRetries {
+		return e.cancel(ctx, schedule, cause)
+	}
+
+	now := time.Now().UTC()
+	retryAt := now.Add(e.retryInterval)
+
+	run := &Run{
+		ScheduleID: schedule.ID,
+		DueAt:      *schedule.NextRunAt,
+		Attempt:    schedule.Attempts + 1,
+		Status:     RunFailed,
+		Error:      cause.Error(),
+		ExecutedAt: now,
+	}
+
+	if err := e.repo.CreateRun(ctx, run); err != nil {
+		return err
+	}
+
+	schedule.LastRunAt = &now
+	schedule.LastError = cause.Error()
+	schedule.Attempts++
+	schedule.RetryAt = &retryAt
+
+	if err := e.repo.Update(ctx, schedule); err != nil {
+		return err
+	}
+
+	e.repo.AfterCommit(ctx, func() {
+		logging.FromContext(ctx, nil).Error(
+			"Scheduled transfer interrupted",
+			zap.Time("due_at", run.DueAt),
+			logger.ErrorField(cause),
+			zap.Timep("retry_at", schedule.RetryAt),
+		)
+	})
+
+	return nil
+}
+
+// cancel stops a schedule that cannot run anymore.
+func (e *Executor) cancel(ctx context.Context, schedule *Schedule, cause error) error {
+	schedule.Status = StatusCancelled
+	schedule.NextRunAt = nil
+	schedule.RetryAt = nil
+	schedule.LastError = cause.Error()
+
+	if err := e.repo.Update(ctx, schedule); err != nil {
+		return err
+	}
+
+	e.repo.AfterCommit(ctx, func() {
+		logging.FromContext(ctx, nil).Error("Schedule cancelled", logger.ErrorField(cause))
+	})
+
+	return nil
+}
+
+func isTransferError(err error) bool {
+	for _, transferErr := range transferErrors {
+		if errors.Is(err, transferErr) {
+			return true
+		}
+	}
+
+	return false
+}
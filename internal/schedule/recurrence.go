@@ -0,0 +1,192 @@
+package schedule
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+// maxRecurrenceGap bounds the search for the next occurrence, so expressions that never occur, e.g. on February
+// 30th, are detected. It covers February 29th.
+const maxRecurrenceGap = 5 * 365 * 24 * time.Hour
+
+// recurrenceMacros are the shorthands of common recurrences.
+var recurrenceMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var dayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// Recurrence is a cron expression evaluated in UTC: minute, hour, day of month, month and day of week, e.g.
+// `0 9 1 * *` for 09:00 on the first day of every month. Fields are `*`, values, ranges and lists, with an
+// optional `/step`; months and days of week may be named. As in cron, a day matches either the day of month or
+// the day of week when both are restricted.
+type Recurrence struct {
+	minutes     uint64
+	hours       uint64
+	daysOfMonth uint64
+	months      uint64
+	daysOfWeek  uint64
+	// anyDayOfMonth and anyDayOfWeek tell whether the day fields start with `*`.
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+// ParseRecurrence parses a cron expression of five fields or one of the @yearly, @monthly, @weekly, @daily and
+// @hourly shorthands.
+func ParseRecurrence(expression string) (*Recurrence, error) {
+	if macro, ok := recurrenceMacros[strings.ToLower(strings.TrimSpace(expression))]; ok {
+		expression = macro
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, ErrInvalidRecurrence
+	}
+
+	var (
+		r   Recurrence
+		err error
+	)
+
+	if r.minutes, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, err
+	}
+
+	if r.hours, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, err
+	}
+
+	if r.daysOfMonth, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, err
+	}
+
+	if r.months, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, err
+	}
+
+	// NOTE: 7 is accepted for Sunday, like 0.
+	if r.daysOfWeek, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, err
+	}
+
+	if r.daysOfWeek&(1<<7) != 0 {
+		r.daysOfWeek |= 1
+	}
+
+	r.anyDayOfMonth = strings.HasPrefix(fields[2], "*")
+	r.anyDayOfWeek = strings.HasPrefix(fields[4], "*")
+
+	return &r, nil
+}
+
+// parseField returns the bit set of the values of a comma-separated field within [low, high].
+func parseField(field string, low, high int, names map[string]int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, ErrInvalidRecurrence
+			}
+		}
+
+		start, end := low, high
+
+		if rangePart != "*" {
+			first, last, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+			if start, err = parseValue(first, low, high, names); err != nil {
+				return 0, err
+			}
+
+			end = start
+
+			switch {
+			case isRange:
+				if end, err = parseValue(last, low, high, names); err != nil || end < start {
+					return 0, ErrInvalidRecurrence
+				}
+			case hasStep:
+				end = high
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << value
+		}
+	}
+
+	return bits, nil
+}
+
+func parseValue(value string, low, high int, names map[string]int) (int, error) {
+	if named, ok := names[strings.ToUpper(value)]; ok {
+		return named, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < low || number > high {
+		return 0, ErrInvalidRecurrence
+	}
+
+	return number, nil
+}
+
+// Next returns the first occurrence strictly after t, or the zero time when there is none.
+func (r *Recurrence) Next(t time.Time) time.Time {
+	next := t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := next.Add(maxRecurrenceGap)
+
+	for next.Before(limit) {
+		switch {
+		case !has(r.months, int(next.Month())):
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !r.matchesDay(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !has(r.hours, next.Hour()):
+			next = next.Truncate(time.Hour).Add(time.Hour)
+		case !has(r.minutes, next.Minute()):
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+func (r *Recurrence) matchesDay(t time.Time) bool {
+	dayOfMonth := has(r.daysOfMonth, t.Day())
+	dayOfWeek := has(r.daysOfWeek, int(t.Weekday()))
+
+	if !r.anyDayOfMonth && !r.anyDayOfWeek {
+		return dayOfMonth || dayOfWeek
+	}
+
+	return dayOfMonth && dayOfWeek
+}
+
+func has(bits uint64, value int) bool {
+	return bits&(1<<value) != 0
+}
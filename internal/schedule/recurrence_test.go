@@ -0,0 +1,95 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{"empty", ""},
+		{"four fields", "* * * *"},
+		{"six fields", "* * * * * *"},
+		{"minute out of range", "60 * * * *"},
+		{"hour out of range", "* 24 * * *"},
+		{"day of month zero", "* * 0 * *"},
+		{"month out of range", "* * * 13 *"},
+		{"day of week out of range", "* * * * 8"},
+		{"zero step", "*/0 * * * *"},
+		{"reversed range", "5-1 * * * *"},
+		{"unknown name", "* * * FOO *"},
+		{"unknown macro", "@often"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseRecurrence(tc.expression); !errors.Is(err, ErrInvalidRecurrence) {
+				t.Errorf("ParseRecurrence(%q) error = %v, want %v", tc.expression, err, ErrInvalidRecurrence)
+			}
+		})
+	}
+}
+
+func TestRecurrence_Next(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2025, time.January, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		expression string
+		want       time.Time
+	}{
+		{"day of month", "0 9 1 * *", time.Date(2025, time.February, 1, 9, 0, 0, 0, time.UTC)},
+		{"strictly after", "30 10 * * *", time.Date(2025, time.January, 16, 10, 30, 0, 0, time.UTC)},
+		{"step", "*/15 * * * *", time.Date(2025, time.January, 15, 10, 45, 0, 0, time.UTC)},
+		{"step of a range", "0 8-18/4 * * *", time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)},
+		{"step from a value", "0 20/2 * * *", time.Date(2025, time.January, 15, 20, 0, 0, 0, time.UTC)},
+		{"list", "0 9,11 * * *", time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{"named days of week", "0 9 * * mon-fri", time.Date(2025, time.January, 16, 9, 0, 0, 0, time.UTC)},
+		{"named months", "0 0 1 JAN,JUL *", time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"sunday as 0", "0 0 * * 0", time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 0 * * 7", time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC)},
+		{"sunday in a range to 7", "0 0 * * 6-7", time.Date(2025, time.January, 18, 0, 0, 0, 0, time.UTC)},
+		{"day of month or day of week", "0 0 17 * MON", time.Date(2025, time.January, 17, 0, 0, 0, 0, time.UTC)},
+		{"day of week or day of month", "0 0 31 * THU", time.Date(2025, time.January, 16, 0, 0, 0, 0, time.UTC)},
+		{"day of week with any day of month", "0 0 * * MON", time.Date(2025, time.January, 20, 0, 0, 0, 0, time.UTC)},
+		// As in cron, a stepped `*` is not a restriction: the day must match both fields.
+		{"stepped any day of month and day of week", "0 0 */10 * MON",
+			time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)},
+		{"macro", "@monthly", time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"macro in upper case", "@WEEKLY", time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"never", "0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			recurrence, err := ParseRecurrence(tc.expression)
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q) error = %v", tc.expression, err)
+			}
+
+			if got := recurrence.Next(from); !got.Equal(tc.want) {
+				t.Errorf("Next = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRecurrence_Next_InUTC(t *testing.T) {
+	recurrence, err := ParseRecurrence("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 08:30 UTC.
+	from := time.Date(2025, time.January, 15, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	if got, want := recurrence.Next(from), time.Date(2025, time.January, 15, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
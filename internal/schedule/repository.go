@@ -0,0 +1,417 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/database"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+const scheduleColumns = `id, tenant_id, from_wallet_id, to_wallet_id, amount, currency, reference, recurrence,
+                  on_insufficient_funds, status, next_run_at, end_at, attempts, retry_at, last_run_at, last_error,
+                  actor_type, actor_id, actor_ip, created_at, updated_at`
+
+const runColumns = `schedule_id, due_at, attempt, status, transaction_id, error, executed_at`
+
+type Repository interface {
+	// WithinTx runs fn in a database transaction, see database.RunInTx.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// AfterCommit runs fn once the transaction of ctx commits, see database.AfterCommit.
+	AfterCommit(ctx context.Context, fn func())
+	Create(ctx context.Context, schedule *Schedule) error
+	Get(ctx context.Context, id string) (*Schedule, error)
+	// GetForUpdate returns the schedule locked until the end of the transaction of ctx.
+	GetForUpdate(ctx context.Context, id string) (*Schedule, error)
+	// List returns the schedules matching the filter, newest first.
+	List(ctx context.Context, filter Filter) ([]*Schedule, error)
+	// Update saves every field of the schedule but its wallets, currency and creation.
+	Update(ctx context.Context, schedule *Schedule) error
+	// LockDue returns the active schedule of any tenant due the longest at now, locked until the end of the
+	// transaction of ctx, nil when there is none. Schedules locked by other transactions are skipped.
+	LockDue(ctx context.Context, now time.Time) (*Schedule, error)
+	CreateRun(ctx context.Context, run *Run) error
+	// ListRuns returns the runs of the schedule, newest first.
+	ListRuns(ctx context.Context, scheduleID string) ([]*Run, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.RunInTx(ctx, r.db, fn)
+}
+
+func (r *repository) AfterCommit(ctx context.Context, fn func()) {
+	database.AfterCommit(ctx, fn)
+}
+
+// currentTenantID returns the tenant every query must be scoped to.
+func currentTenantID(ctx context.Context) (string, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return t.ID, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSchedule(row rowScanner) (*Schedule, error) {
+	var (
+		schedule   Schedule
+		reference  sql.NullString
+		recurrence sql.NullString
+		nextRunAt  sql.NullTime
+		endAt      sql.NullTime
+		retryAt    sql.NullTime
+		lastRunAt  sql.NullTime
+		lastError  sql.NullString
+		actorID    sql.NullString
+		actorIP    sql.NullString
+	)
+
+	err := row.Scan(&schedule.ID, &schedule.TenantID, &schedule.FromWalletID, &schedule.ToWalletID, &schedule.Amount,
+		&schedule.Currency, &reference, &recurrence, &schedule.OnInsufficientFunds, &schedule.Status, &nextRunAt,
+		&endAt, &schedule.Attempts, &retryAt, &lastRunAt, &lastError, &schedule.CreatedBy.Type, &actorID, &actorIP,
+		&schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.Reference = reference.String
+	schedule.Recurrence = recurrence.String
+	schedule.NextRunAt = timePtr(nextRunAt)
+	schedule.EndAt = timePtr(endAt)
+	schedule.RetryAt = timePtr(retryAt)
+	schedule.LastRunAt = timePtr(lastRunAt)
+	schedule.LastError = lastError.String
+	schedule.CreatedBy.ID = actorID.String
+	schedule.CreatedBy.IP = actorIP.String
+
+	return &schedule, nil
+}
+
+func scanRun(row rowScanner) (*Run, error) {
+	var (
+		run           Run
+		transactionID sql.NullString
+		message       sql.NullString
+	)
+
+	err := row.Scan(&run.ScheduleID, &run.DueAt, &run.Attempt, &run.Status, &transactionID, &message,
+		&run.ExecutedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	run.TransactionID = transactionID.String
+	run.Error = message.String
+
+	return &run, nil
+}
+
+func timePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Time
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullTime(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: *value, Valid: true}
+}
+
+func (r *repository) Create(ctx context.Context, schedule *Schedule) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	schedule.ID = uuid.New().String()
+	schedule.TenantID = tenantID
+
+	query := `INSERT INTO schedules (id, tenant_id, from_wallet_id, to_wallet_id, amount, currency, reference,
+                  recurrence, on_insufficient_funds, status, next_run_at, end_at, attempts, actor_type, actor_id,
+                  actor_ip, created_at, updated_at)
+              VALUES (@id, @tenant_id, @from_wallet_id, @to_wallet_id, @amount, @currency, @reference, @recurrence,
+                  @on_insufficient_funds, @status, @next_run_at, @end_at, 0, @actor_type, @actor_id, @actor_ip,
+                  @created_at, @updated_at)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "schedule.Repository/Create", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("id", schedule.ID),
+		sql.Named("tenant_id", schedule.TenantID),
+		sql.Named("from_wallet_id", schedule.FromWalletID),
+		sql.Named("to_wallet_id", schedule.ToWalletID),
+		sql.Named("amount", schedule.Amount),
+		sql.Named("currency", schedule.Currency),
+		sql.Named("reference", nullString(schedule.Reference)),
+		sql.Named("recurrence", nullString(schedule.Recurrence)),
+		sql.Named("on_insufficient_funds", string(schedule.OnInsufficientFunds)),
+		sql.Named("status", string(schedule.Status)),
+		sql.Named("next_run_at", nullTime(schedule.NextRunAt)),
+		sql.Named("end_at", nullTime(schedule.EndAt)),
+		sql.Named("actor_type", string(schedule.CreatedBy.Type)),
+		sql.Named("actor_id", nullString(schedule.CreatedBy.ID)),
+		sql.Named("actor_ip", nullString(schedule.CreatedBy.IP)),
+		sql.Named("created_at", schedule.CreatedAt),
+		sql.Named("updated_at", schedule.UpdatedAt),
+	)
+	if err != nil {
+		return errors.New("failed to insert schedule into database: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *repository) Get(ctx context.Context, id string) (_ *Schedule, err error) {
+	query := `SELECT ` + scheduleColumns + `
+              FROM schedules WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "schedule.Repository/Get", query)
+	defer func() { tracing.End(span, err) }()
+
+	return r.get(ctx, query, id)
+}
+
+func (r *repository) GetForUpdate(ctx context.Context, id string) (_ *Schedule, err error) {
+	query := `SELECT ` + scheduleColumns + `
+              FROM schedules WITH (UPDLOCK, ROWLOCK) WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "schedule.Repository/GetForUpdate", query)
+	defer func() { tracing.End(span, err) }()
+
+	return r.get(ctx, query, id)
+}
+
+func (r *repository) get(ctx context.Context, query string, id string) (*Schedule, error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := scanSchedule(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrScheduleNotFound
+		}
+		return nil, errors.New("failed to retrieve schedule: " + err.Error())
+	}
+
+	return schedule, nil
+}
+
+func (r *repository) List(ctx context.Context, filter Filter) (_ []*Schedule, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + scheduleColumns + `
+              FROM schedules
+              WHERE tenant_id = @tenant_id
+                AND (@wallet_id = '' OR from_wallet_id = @wallet_id OR to_wallet_id = @wallet_id)
+                AND (@status = '' OR status = @status)
+              ORDER BY created_at DESC`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "schedule.Repository/List", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query,
+		sql.Named("tenant_id", tenantID),
+		sql.Named("wallet_id", filter.WalletID),
+		sql.Named("status", string(filter.Status)),
+	)
+	if err != nil {
+		return nil, errors.New("failed to list schedules: " + err.Error())
+	}
+	defer rows.Close()
+
+	schedules := make([]*Schedule, 0)
+
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, errors.New("failed to scan schedule: " + err.Error())
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list schedules: " + err.Error())
+	}
+
+	return schedules, nil
+}
+
+func (r *repository) Update(ctx context.Context, schedule *Schedule) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE schedules
+              SET amount = @amount, reference = @reference, recurrence = @recurrence,
+                  on_insufficient_funds = @on_insufficient_funds, status = @status, next_run_at = @next_run_at,
+                  end_at = @end_at, attempts = @attempts, retry_at = @retry_at, last_run_at = @last_run_at,
+                  last_error = @last_error, updated_at = @updated_at
+              WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "schedule.Repository/Update", query)
+	defer func() { tracing.End(span, err) }()
+
+	schedule.UpdatedAt = time.Now().UTC()
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("amount", schedule.Amount),
+		sql.Named("reference", nullString(schedule.Reference)),
+		sql.Named("recurrence", nullString(schedule.Recurrence)),
+		sql.Named("on_insufficient_funds", string(schedule.OnInsufficientFunds)),
+		sql.Named("status", string(schedule.Status)),
+		sql.Named("next_run_at", nullTime(schedule.NextRunAt)),
+		sql.Named("end_at", nullTime(schedule.EndAt)),
+		sql.Named("attempts", schedule.Attempts),
+		sql.Named("retry_at", nullTime(schedule.RetryAt)),
+		sql.Named("last_run_at", nullTime(schedule.LastRunAt)),
+		sql.Named("last_error", nullString(wallet.Truncate(schedule.LastError, maxErrorLength))),
+		sql.Named("updated_at", schedule.UpdatedAt),
+		sql.Named("id", schedule.ID),
+		sql.Named("tenant_id", tenantID),
+	)
+	if err != nil {
+		return errors.New("failed to update schedule: " + err.Error())
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return errors.New("failed to update schedule: " + err.Error())
+	}
+
+	if updated == 0 {
+		return ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) LockDue(ctx context.Context, now time.Time) (_ *Schedule, err error) {
+	// NOTE: READPAST skips the schedules being run by other executors instead of waiting for them.
+	query := `SELECT TOP 1 ` + scheduleColumns + `
+              FROM schedules WITH (UPDLOCK, READPAST, ROWLOCK)
+              WHERE status = 'active' AND next_run_at <= @now AND (retry_at IS NULL OR retry_at <= @now)
+              ORDER BY COALESCE(retry_at, next_run_at)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "schedule.Repository/LockDue", query)
+	defer func() { tracing.End(span, err) }()
+
+	schedule, err := scanSchedule(database.Conn(ctx, r.db).QueryRowContext(ctx, query, sql.Named("now", now)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.New("failed to lock due schedule: " + err.Error())
+	}
+
+	return schedule, nil
+}
+
+func (r *repository) CreateRun(ctx context.Context, run *Run) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO schedule_runs (schedule_id, due_at, attempt, tenant_id, status, transaction_id, error,
+                  executed_at)
+              VALUES (@schedule_id, @due_at, @attempt, @tenant_id, @status, @transaction_id, @error, @executed_at)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "schedule.Repository/CreateRun", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("schedule_id", run.ScheduleID),
+		sql.Named("due_at", run.DueAt),
+		sql.Named("attempt", run.Attempt),
+		sql.Named("tenant_id", tenantID),
+		sql.Named("status", string(run.Status)),
+		sql.Named("transaction_id", nullString(run.TransactionID)),
+		sql.Named("error", nullString(wallet.Truncate(run.Error, maxErrorLength))),
+		sql.Named("executed_at", run.ExecutedAt),
+	)
+	if err != nil {
+		return errors.New("failed to insert schedule run into database: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *repository) ListRuns(ctx context.Context, scheduleID string) (_ []*Run, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + runColumns + `
+              FROM schedule_runs
+              WHERE schedule_id = @schedule_id AND tenant_id = @tenant_id
+              ORDER BY executed_at DESC, due_at DESC, attempt DESC`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "schedule.Repository/ListRuns", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query,
+		sql.Named("schedule_id", scheduleID),
+		sql.Named("tenant_id", tenantID),
+	)
+	if err != nil {
+		return nil, errors.New("failed to list schedule runs: " + err.Error())
+	}
+	defer rows.Close()
+
+	runs := make([]*Run, 0)
+
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, errors.New("failed to scan schedule run: " + err.Error())
+		}
+
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list schedule runs: " + err.Error())
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,149 @@
+// Package schedule runs standing orders: transfers between wallets of a tenant due once at a future instant or
+// recurring, e.g. 50 EUR to a savings wallet every month. Due schedules are executed by an Executor.
+package schedule
+
+import (
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+)
+
+// Status is the state of a schedule.
+type Status string
+
+const (
+	// StatusActive schedules have a next run.
+	StatusActive Status = "active"
+	// StatusCompleted schedules ran their last occurrence, successfully or not.
+	StatusCompleted Status = "completed"
+	// StatusCancelled schedules were cancelled before their last occurrence.
+	StatusCancelled Status = "cancelled"
+)
+
+// Policy tells what happens to an occurrence failing for insufficient funds.
+type Policy string
+
+const (
+	// PolicySkip gives up the occurrence, recurring schedules continue with the next one.
+	PolicySkip Policy = "skip"
+	// PolicyRetry tries the occurrence again later, a limited number of times and never past the next occurrence.
+	PolicyRetry Policy = "retry"
+)
+
+// RunStatus is the outcome of an attempt to run an occurrence of a schedule.
+type RunStatus string
+
+const (
+	RunSucceeded RunStatus = "succeeded"
+	// RunFailed attempts are retried later.
+	RunFailed RunStatus = "failed"
+	// RunSkipped attempts failed and their occurrence was given up.
+	RunSkipped RunStatus = "skipped"
+)
+
+type Schedule struct {
+	ID           string  `json:"id" db:"id"`
+	TenantID     string  `json:"tenant_id" db:"tenant_id"`
+	FromWalletID string  `json:"from_wallet_id" db:"from_wallet_id"`
+	ToWalletID   string  `json:"to_wallet_id" db:"to_wallet_id"`
+	Amount       float64 `json:"amount" db:"amount"`
+	Currency     string  `json:"currency" db:"currency"`
+	Reference    string  `json:"reference,omitempty" db:"reference"`
+	// Recurrence is the cron expression of recurring schedules, empty for one-off schedules, see Recurrence.
+	Recurrence          string `json:"recurrence,omitempty" db:"recurrence"`
+	OnInsufficientFunds Policy `json:"on_insufficient_funds" db:"on_insufficient_funds"`
+	Status              Status `json:"status" db:"status"`
+	// NextRunAt is the next occurrence, nil once the schedule is not active anymore.
+	NextRunAt *time.Time `json:"next_run_at,omitempty" db:"next_run_at"`
+	// EndAt is the instant after which a recurring schedule has no occurrence.
+	EndAt *time.Time `json:"end_at,omitempty" db:"end_at"`
+	// Attempts counts the failed attempts of the next occurrence, retried at RetryAt.
+	Attempts  int        `json:"attempts" db:"attempts"`
+	RetryAt   *time.Time `json:"retry_at,omitempty" db:"retry_at"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty" db:"last_run_at"`
+	// LastError tells why the last attempt failed.
+	LastError string      `json:"last_error,omitempty" db:"last_error"`
+	CreatedBy audit.Actor `json:"created_by"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// Run is an attempt to run an occurrence of a schedule.
+type Run struct {
+	ScheduleID string `json:"schedule_id" db:"schedule_id"`
+	// DueAt is the occurrence the attempt ran.
+	DueAt         time.Time `json:"due_at" db:"due_at"`
+	Attempt       int       `json:"attempt" db:"attempt"`
+	Status        RunStatus `json:"status" db:"status"`
+	TransactionID string    `json:"transaction_id,omitempty" db:"transaction_id"`
+	Error         string    `json:"error,omitempty" db:"error"`
+	ExecutedAt    time.Time `json:"executed_at" db:"executed_at"`
+}
+
+// Instruction is a transfer to schedule.
+type Instruction struct {
+	FromWalletID string
+	ToWalletID   string
+	Amount       float64
+	Reference    string
+	// StartAt is when a one-off transfer runs. Recurring transfers first run at their first occurrence from
+	// StartAt, or from now when it is zero.
+	StartAt             time.Time
+	Recurrence          string
+	EndAt               *time.Time
+	OnInsufficientFunds Policy
+}
+
+// Changes are the updates of a schedule. Nil fields are left unchanged.
+type Changes struct {
+	Amount              *float64
+	Reference           *string
+	StartAt             *time.Time
+	Recurrence          *string
+	EndAt               *time.Time
+	OnInsufficientFunds *Policy
+}
+
+// Filter narrows down the schedules returned by List. Empty fields match every schedule.
+type Filter struct {
+	// WalletID matches the schedules from or to the wallet.
+	WalletID string
+	Status   Status
+}
+
+// following returns the occurrence after the current one, nil when the schedule has no further occurrence.
+func (s *Schedule) following() (*time.Time, error) {
+	if s.Recurrence == "" || s.NextRunAt == nil {
+		return nil, nil
+	}
+
+	recurrence, err := ParseRecurrence(s.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	next := recurrence.Next(*s.NextRunAt)
+	if next.IsZero() || (s.EndAt != nil && next.After(*s.EndAt)) {
+		return nil, nil
+	}
+
+	return &next, nil
+}
+
+// advance moves the schedule to its next occurrence, or completes it.
+func (s *Schedule) advance() error {
+	next, err := s.following()
+	if err != nil {
+		return err
+	}
+
+	s.NextRunAt = next
+	s.Attempts = 0
+	s.RetryAt = nil
+
+	if next == nil {
+		s.Status = StatusCompleted
+	}
+
+	return nil
+}
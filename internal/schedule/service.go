@@ -0,0 +1,338 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+var (
+	ErrScheduleNotFound  = errors.New("schedule not found")
+	ErrScheduleNotActive = errors.New("schedule is not active")
+	ErrInvalidStart      = errors.New("invalid schedule start")
+	ErrInvalidEnd        = errors.New("invalid schedule end")
+	ErrNoOccurrence      = errors.New("schedule has no occurrence")
+	ErrInvalidPolicy     = errors.New("invalid insufficient funds policy")
+	ErrReferenceTooLong  = errors.New("reference too long")
+)
+
+// maxErrorLength is the length of the error columns.
+const maxErrorLength = 500
+
+// Service manages the schedules of a tenant. Schedules are run by an Executor.
+type Service interface {
+	// Create schedules a transfer between two wallets of the same currency.
+	Create(ctx context.Context, instruction Instruction) (*Schedule, error)
+	Get(ctx context.Context, id string) (*Schedule, error)
+	List(ctx context.Context, filter Filter) ([]*Schedule, error)
+	// Update changes an active schedule. A new start or recurrence moves the next run to the first occurrence
+	// from the start, or from now for recurring schedules, and drops the pending retries.
+	Update(ctx context.Context, id string, changes Changes) (*Schedule, error)
+	// Cancel stops an active schedule. Its runs are kept.
+	Cancel(ctx context.Context, id string) (*Schedule, error)
+	// ListRuns returns the runs of the schedule, newest first.
+	ListRuns(ctx context.Context, id string) ([]*Run, error)
+}
+
+// ResourceType is the audit log resource type of schedules.
+const ResourceType = "schedule"
+
+// Audit log actions of schedules. Their transfers are audited by the wallets.
+const (
+	AuditActionCreated   = "schedule.created"
+	AuditActionUpdated   = "schedule.updated"
+	AuditActionCancelled = "schedule.cancelled"
+)
+
+type service struct {
+	repo     Repository
+	wallets  wallet.Service
+	auditLog audit.Recorder
+}
+
+type serviceOption func(*service)
+
+// WithAuditLog sets the audit log recording every change of a schedule in the same database transaction.
+func WithAuditLog(auditLog audit.Recorder) serviceOption {
+	return func(s *service) {
+		s.auditLog = auditLog
+	}
+}
+
+func NewService(repo Repository, wallets wallet.Service, options ...serviceOption) Service {
+	s := &service{
+		repo:     repo,
+		wallets:  wallets,
+		auditLog: audit.NopRecorder{},
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *service) Create(ctx context.Context, instruction Instruction) (*Schedule, error) {
+	now := time.Now().UTC()
+
+	schedule := &Schedule{
+		FromWalletID:        instruction.FromWalletID,
+		ToWalletID:          instruction.ToWalletID,
+		Amount:              instruction.Amount,
+		Reference:           instruction.Reference,
+		Recurrence:          strings.TrimSpace(instruction.Recurrence),
+		OnInsufficientFunds: instruction.OnInsufficientFunds,
+		Status:              StatusActive,
+		EndAt:               instruction.EndAt,
+		CreatedBy:           audit.ActorFromContext(ctx),
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+
+	if schedule.OnInsufficientFunds == "" {
+		schedule.OnInsufficientFunds = PolicySkip
+	}
+
+	if err := validate(schedule); err != nil {
+		return nil, err
+	}
+
+	if err := plan(schedule, instruction.StartAt, now); err != nil {
+		return nil, err
+	}
+
+	from, err := s.wallets.GetWallet(ctx, schedule.FromWalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.wallets.GetWallet(ctx, schedule.ToWalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if from.Currency != to.Currency {
+		return nil, wallet.ErrCurrencyMismatch
+	}
+
+	schedule.Currency = from.Currency
+
+	err = s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, schedule); err != nil {
+			return err
+		}
+
+		return s.auditLog.Record(ctx, AuditActionCreated, ResourceType, schedule.ID, nil, schedule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Schedule created",
+		zap.String("schedule_id", schedule.ID),
+		zap.String("wallet_id", schedule.FromWalletID),
+		zap.String("to_wallet_id", schedule.ToWalletID),
+		zap.Float64("amount", schedule.Amount),
+		zap.String("recurrence", schedule.Recurrence),
+		zap.Timep("next_run_at", schedule.NextRunAt),
+	)
+
+	return schedule, nil
+}
+
+func (s *service) Get(ctx context.Context, id string) (*Schedule, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *service) List(ctx context.Context, filter Filter) ([]*Schedule, error) {
+	return s.repo.List(ctx, filter)
+}
+
+func (s *service) Update(ctx context.Context, id string, changes Changes) (*Schedule, error) {
+	var schedule *Schedule
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		schedule, err = s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if schedule.Status != StatusActive {
+			return ErrScheduleNotActive
+		}
+
+		before := *schedule
+
+		if changes.Amount != nil {
+			schedule.Amount = *changes.Amount
+		}
+
+		if changes.Reference != nil {
+			schedule.Reference = *changes.Reference
+		}
+
+		if changes.OnInsufficientFunds != nil {
+			schedule.OnInsufficientFunds = *changes.OnInsufficientFunds
+		}
+
+		if changes.EndAt != nil {
+			schedule.EndAt = changes.EndAt
+		}
+
+		if changes.Recurrence != nil {
+			schedule.Recurrence = strings.TrimSpace(*changes.Recurrence)
+		}
+
+		if err := validate(schedule); err != nil {
+			return err
+		}
+
+		switch {
+		case changes.StartAt != nil || changes.Recurrence != nil:
+			var start time.Time
+			if changes.StartAt != nil {
+				start = *changes.StartAt
+			}
+
+			if err := plan(schedule, start, time.Now().UTC()); err != nil {
+				return err
+			}
+		case schedule.EndAt != nil && schedule.NextRunAt.After(*schedule.EndAt):
+			return ErrNoOccurrence
+		}
+
+		if err := s.repo.Update(ctx, schedule); err != nil {
+			return err
+		}
+
+		return s.auditLog.Record(ctx, AuditActionUpdated, ResourceType, schedule.ID, &before, schedule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Schedule updated",
+		zap.String("schedule_id", schedule.ID),
+		zap.Float64("amount", schedule.Amount),
+		zap.String("recurrence", schedule.Recurrence),
+		zap.Timep("next_run_at", schedule.NextRunAt),
+	)
+
+	return schedule, nil
+}
+
+func (s *service) Cancel(ctx context.Context, id string) (*Schedule, error) {
+	var schedule *Schedule
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		schedule, err = s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if schedule.Status != StatusActive {
+			return ErrScheduleNotActive
+		}
+
+		before := *schedule
+
+		schedule.Status = StatusCancelled
+		schedule.NextRunAt = nil
+		schedule.RetryAt = nil
+
+		if err := s.repo.Update(ctx, schedule); err != nil {
+			return err
+		}
+
+		return s.auditLog.Record(ctx, AuditActionCancelled, ResourceType, schedule.ID, &before, schedule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Schedule cancelled",
+		zap.String("schedule_id", schedule.ID),
+	)
+
+	return schedule, nil
+}
+
+func (s *service) ListRuns(ctx context.Context, id string) ([]*Run, error) {
+	schedule, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListRuns(ctx, schedule.ID)
+}
+
+// validate checks the fields of the schedule that do not depend on its wallets or on time.
+func validate(schedule *Schedule) error {
+	switch {
+	case schedule.FromWalletID == schedule.ToWalletID:
+		return wallet.ErrSameWallet
+	case schedule.Amount <= 0 || !wallet.WholeCents(schedule.Amount):
+		return wallet.ErrInvalidAmount
+	case utf8.RuneCountInString(schedule.Reference) > wallet.MaxReferenceLength:
+		return ErrReferenceTooLong
+	case schedule.OnInsufficientFunds != PolicySkip && schedule.OnInsufficientFunds != PolicyRetry:
+		return ErrInvalidPolicy
+	case schedule.EndAt != nil && schedule.Recurrence == "":
+		return ErrInvalidEnd
+	}
+
+	return nil
+}
+
+// plan sets the next run of the schedule: start for one-off schedules, which must be after now, the first
+// occurrence from start, or from now when start is earlier, for recurring schedules.
+func plan(schedule *Schedule, start time.Time, now time.Time) error {
+	schedule.Attempts = 0
+	schedule.RetryAt = nil
+
+	if schedule.Recurrence == "" {
+		if !start.After(now) {
+			return ErrInvalidStart
+		}
+
+		// NOTE: DATETIME columns do not store nanoseconds, runs are identified by their due time.
+		next := start.UTC().Truncate(time.Second)
+		schedule.NextRunAt = &next
+
+		return nil
+	}
+
+	recurrence, err := ParseRecurrence(schedule.Recurrence)
+	if err != nil {
+		return err
+	}
+
+	if start.Before(now) {
+		start = now
+	}
+
+	next := recurrence.Next(start.Add(-time.Nanosecond))
+	if next.IsZero() || (schedule.EndAt != nil && next.After(*schedule.EndAt)) {
+		return ErrNoOccurrence
+	}
+
+	schedule.NextRunAt = &next
+
+	return nil
+}
@@ -0,0 +1,361 @@
+package settlement
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported settlement file format")
+	ErrInvalidFile       = errors.New("invalid settlement file")
+)
+
+// ParseError tells which line of a settlement file is invalid.
+type ParseError struct {
+	Line    int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Message
+}
+
+func (e *ParseError) Unwrap() error {
+	return ErrInvalidFile
+}
+
+// Parse reads the credit lines of a settlement file. Invalid files are reported as a *ParseError.
+func Parse(format Format, content []byte) (*Statement, error) {
+	var (
+		statement *Statement
+		err       error
+	)
+
+	switch format {
+	case FormatCSV:
+		statement, err = parseCSV(content)
+	case FormatCamt053:
+		statement, err = parseCamt053(content)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := sha256.Sum256(content)
+	statement.Format = format
+	statement.Checksum = hex.EncodeToString(checksum[:])
+
+	return statement, nil
+}
+
+// csvColumns are the columns of CSV settlement files. The header names the columns, in any order.
+var csvColumns = struct {
+	bookingDate, amount, currency, reference, description string
+}{"booking_date", "amount", "currency", "reference", "description"}
+
+// parseCSV reads a CSV file with a header. Negative amounts are debits.
+func parseCSV(content []byte) (*Statement, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, &ParseError{Line: 1, Message: "missing header"}
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, required := range []string{csvColumns.bookingDate, csvColumns.amount, csvColumns.currency} {
+		if _, ok := columns[required]; !ok {
+			return nil, &ParseError{Line: 1, Message: "missing column " + required}
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	statement := &Statement{Lines: make([]Line, 0)}
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, &ParseError{Line: row, Message: err.Error()}
+		}
+
+		amount, err := strconv.ParseFloat(field(record, csvColumns.amount), 64)
+		if err != nil {
+			return nil, &ParseError{Line: row, Message: "invalid amount"}
+		}
+
+		if amount < 0 {
+			statement.SkippedDebits++
+			continue
+		}
+
+		bookingDate, err := time.Parse(time.DateOnly, field(record, csvColumns.bookingDate))
+		if err != nil {
+			return nil, &ParseError{Line: row, Message: "invalid booking date, expected YYYY-MM-DD"}
+		}
+
+		line := Line{
+			Number:      len(statement.Lines) + 1,
+			Reference:   field(record, csvColumns.reference),
+			Amount:      amount,
+			Currency:    strings.ToUpper(field(record, csvColumns.currency)),
+			BookingDate: bookingDate,
+			Description: wallet.Truncate(field(record, csvColumns.description), maxDescriptionLength),
+		}
+
+		if err := validateLine(line); err != "" {
+			return nil, &ParseError{Line: row, Message: err}
+		}
+
+		statement.Lines = append(statement.Lines, line)
+	}
+
+	return statement, nil
+}
+
+// camtDocument is the subset of an ISO 20022 camt.053 bank to customer statement used for matching.
+// Element names are matched in any namespace, so every camt.053 version is accepted.
+type camtDocument struct {
+	XMLName    xml.Name        `xml:"Document"`
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	Entries []camtEntry `xml:"Ntry"`
+}
+
+type camtEntry struct {
+	Amount             camtAmount        `xml:"Amt"`
+	CreditDebit        string            `xml:"CdtDbtInd"`
+	Status             camtStatus        `xml:"Sts"`
+	BookingDate        camtDate          `xml:"BookgDt"`
+	AccountServicerRef string            `xml:"AcctSvcrRef"`
+	AdditionalInfo     string            `xml:"AddtlNtryInf"`
+	Transactions       []camtTransaction `xml:"NtryDtls>TxDtls"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// camtStatus is a code, `<Sts>BOOK</Sts>` up to version 2 and `<Sts><Cd>BOOK</Cd></Sts>` since.
+type camtStatus struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+func (s camtStatus) value() string {
+	if s.Code != "" {
+		return strings.TrimSpace(s.Code)
+	}
+
+	return strings.TrimSpace(s.Text)
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtTransaction struct {
+	Amount            *camtAmount `xml:"Amt"`
+	InstructedAmount  *camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+	CreditDebit       string      `xml:"CdtDbtInd"`
+	EndToEndID        string      `xml:"Refs>EndToEndId"`
+	CreditorReference string      `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	Unstructured      []string    `xml:"RmtInf>Ustrd"`
+}
+
+// camtNotProvided is the end-to-end ID of transfers sent without one.
+const camtNotProvided = "NOTPROVIDED"
+
+// parseCamt053 reads the booked credit entries of a camt.053 file. Batch booked entries with several
+// transaction details yield one line per transaction.
+func parseCamt053(content []byte) (*Statement, error) {
+	var document camtDocument
+
+	if err := xml.Unmarshal(content, &document); err != nil {
+		return nil, &ParseError{Message: "invalid camt.053 document: " + err.Error()}
+	}
+
+	statement := &Statement{Lines: make([]Line, 0)}
+	entryNumber := 0
+
+	for _, stmt := range document.Statements {
+		for _, entry := range stmt.Entries {
+			entryNumber++
+
+			if status := entry.Status.value(); status != "" && status != "BOOK" {
+				continue
+			}
+
+			if entry.CreditDebit != "CRDT" {
+				statement.SkippedDebits++
+				continue
+			}
+
+			bookingDate, err := entry.BookingDate.parse()
+			if err != nil {
+				return nil, &ParseError{Line: entryNumber, Message: "invalid booking date"}
+			}
+
+			lines, err := entryLines(entry, bookingDate)
+			if err != nil {
+				return nil, &ParseError{Line: entryNumber, Message: err.Error()}
+			}
+
+			for _, line := range lines {
+				line.Number = len(statement.Lines) + 1
+
+				if err := validateLine(line); err != "" {
+					return nil, &ParseError{Line: entryNumber, Message: err}
+				}
+
+				statement.Lines = append(statement.Lines, line)
+			}
+		}
+	}
+
+	return statement, nil
+}
+
+func entryLines(entry camtEntry, bookingDate time.Time) ([]Line, error) {
+	if len(entry.Transactions) <= 1 {
+		line := Line{
+			Reference:   entry.AccountServicerRef,
+			Currency:    strings.ToUpper(entry.Amount.Currency),
+			BookingDate: bookingDate,
+			Description: wallet.Truncate(strings.TrimSpace(entry.AdditionalInfo), maxDescriptionLength),
+		}
+
+		amount, err := strconv.ParseFloat(strings.TrimSpace(entry.Amount.Value), 64)
+		if err != nil {
+			return nil, errors.New("invalid amount")
+		}
+
+		line.Amount = amount
+
+		if len(entry.Transactions) == 1 {
+			applyTransactionDetails(&line, entry.Transactions[0])
+		}
+
+		return []Line{line}, nil
+	}
+
+	lines := make([]Line, 0, len(entry.Transactions))
+
+	for _, transaction := range entry.Transactions {
+		if transaction.CreditDebit == "DBIT" {
+			continue
+		}
+
+		amount := transaction.Amount
+		if amount == nil {
+			amount = transaction.InstructedAmount
+		}
+
+		if amount == nil {
+			return nil, errors.New("missing amount of batch booked transaction")
+		}
+
+		value, err := strconv.ParseFloat(strings.TrimSpace(amount.Value), 64)
+		if err != nil {
+			return nil, errors.New("invalid amount")
+		}
+
+		line := Line{
+			Amount:      value,
+			Currency:    strings.ToUpper(amount.Currency),
+			BookingDate: bookingDate,
+		}
+		applyTransactionDetails(&line, transaction)
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// applyTransactionDetails takes the reference of the line from the end-to-end ID, the structured creditor
+// reference or the first unstructured remittance information, in that order.
+func applyTransactionDetails(line *Line, transaction camtTransaction) {
+	description := strings.TrimSpace(strings.Join(transaction.Unstructured, " "))
+	if description != "" {
+		line.Description = wallet.Truncate(description, maxDescriptionLength)
+	}
+
+	switch {
+	case transaction.EndToEndID != "" && transaction.EndToEndID != camtNotProvided:
+		line.Reference = strings.TrimSpace(transaction.EndToEndID)
+	case transaction.CreditorReference != "":
+		line.Reference = strings.TrimSpace(transaction.CreditorReference)
+	case len(transaction.Unstructured) > 0:
+		line.Reference = strings.TrimSpace(transaction.Unstructured[0])
+	}
+}
+
+func (d camtDate) parse() (time.Time, error) {
+	if d.Date != "" {
+		return time.Parse(time.DateOnly, strings.TrimSpace(d.Date))
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05"} {
+		if dateTime, err := time.Parse(layout, strings.TrimSpace(d.DateTime)); err == nil {
+			return time.Date(dateTime.Year(), dateTime.Month(), dateTime.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+
+	return time.Time{}, errors.New("invalid date")
+}
+
+// maxDescriptionLength is the length of the stored descriptions, longer ones are truncated.
+const maxDescriptionLength = 500
+
+// validateLine returns why the line cannot be matched, or an empty string.
+func validateLine(line Line) string {
+	switch {
+	case line.Amount <= 0 || !wallet.WholeCents(line.Amount):
+		return "amount must be positive with at most two decimals"
+	case len(line.Currency) != 3:
+		return "currency must be a 3-letter ISO code"
+	case utf8.RuneCountInString(line.Reference) > wallet.MaxReferenceLength:
+		return "reference must be at most " + strconv.Itoa(wallet.MaxReferenceLength) + " characters"
+	}
+
+	return ""
+}
@@ -0,0 +1,53 @@
+package settlement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/spreadsheet"
+)
+
+// WriteItemsCSV writes the items as CSV, one line per item, e.g. for the list of unmatched items of an import.
+func WriteItemsCSV(w io.Writer, items []*Item) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{
+		"line", "booking_date", "amount", "currency", "reference", "description", "status", "transaction_id",
+		"candidate_ids", "resolved_by", "resolved_at", "resolution_note",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		resolvedAt := ""
+		if item.ResolvedAt != nil {
+			resolvedAt = item.ResolvedAt.UTC().Format(time.RFC3339)
+		}
+
+		err := writer.Write([]string{
+			strconv.Itoa(item.Line),
+			item.BookingDate.Format(time.DateOnly),
+			strconv.FormatFloat(item.Amount, 'f', 2, 64),
+			item.Currency,
+			spreadsheet.Cell(item.Reference),
+			spreadsheet.Cell(item.Description),
+			string(item.Status),
+			item.TransactionID,
+			strings.Join(item.CandidateIDs, " "),
+			spreadsheet.Cell(item.ResolvedBy),
+			resolvedAt,
+			spreadsheet.Cell(item.ResolutionNote),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
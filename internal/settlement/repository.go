@@ -0,0 +1,505 @@
+package settlement
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/database"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
+)
+
+const importColumns = `i.id, i.tenant_id, i.format, i.file_name, i.checksum, i.lines, i.imported_by, i.imported_at,
+                  COALESCE(SUM(CASE s.status WHEN 'matched' THEN 1 ELSE 0 END), 0),
+                  COALESCE(SUM(CASE s.status WHEN 'unmatched' THEN 1 ELSE 0 END), 0),
+                  COALESCE(SUM(CASE s.status WHEN 'ambiguous' THEN 1 ELSE 0 END), 0),
+                  COALESCE(SUM(CASE s.status WHEN 'resolved' THEN 1 ELSE 0 END), 0),
+                  COALESCE(SUM(CASE s.status WHEN 'dismissed' THEN 1 ELSE 0 END), 0)`
+
+const importGroupBy = `GROUP BY i.id, i.tenant_id, i.format, i.file_name, i.checksum, i.lines, i.imported_by,
+                  i.imported_at`
+
+const itemColumns = `id, tenant_id, import_id, line, reference, amount, currency, booking_date, description, status,
+              transaction_id, candidate_ids, resolved_by, resolved_at, resolution_note`
+
+// maxCandidates bounds the deposits returned for a line, listing more would not help an operator.
+const maxCandidates = 20
+
+// DepositQuery selects the deposits not matched to a settlement item yet that may match a line.
+type DepositQuery struct {
+	Currency  string
+	MinAmount float64
+	MaxAmount float64
+	// Reference selects the deposits with this reference. When empty, the deposits without a reference
+	// created between From and To are selected instead.
+	Reference string
+	From      time.Time
+	To        time.Time
+}
+
+type Repository interface {
+	// WithinTx runs fn in a database transaction, see database.RunInTx.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// CreateImport records the import. It returns ErrDuplicateImport when a file with the same checksum
+	// was already imported for the tenant.
+	CreateImport(ctx context.Context, settlementImport *Import) error
+	GetImport(ctx context.Context, id string) (*Import, error)
+	ListImports(ctx context.Context) ([]*Import, error)
+	CreateItem(ctx context.Context, item *Item) error
+	GetItem(ctx context.Context, importID string, id string) (*Item, error)
+	// ListItems returns the items of the import in file order, only those with status unless it is empty.
+	ListItems(ctx context.Context, importID string, status Status) ([]*Item, error)
+	// Resolve closes an open item. It returns ErrItemNotOpen when the item was resolved in the meantime.
+	Resolve(ctx context.Context, id string, status Status, resolution Resolution) (*Item, error)
+	// FindDeposits returns the IDs of the deposits selected by query, oldest first.
+	FindDeposits(ctx context.Context, query DepositQuery) ([]string, error)
+	// IsMatched tells whether the deposit is matched to a settlement item.
+	IsMatched(ctx context.Context, transactionID string) (bool, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.RunInTx(ctx, r.db, fn)
+}
+
+// currentTenantID returns the tenant every query must be scoped to.
+func currentTenantID(ctx context.Context) (string, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return t.ID, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanImport(row rowScanner) (*Import, error) {
+	var settlementImport Import
+
+	err := row.Scan(&settlementImport.ID, &settlementImport.TenantID, &settlementImport.Format,
+		&settlementImport.FileName, &settlementImport.Checksum, &settlementImport.Lines,
+		&settlementImport.ImportedBy, &settlementImport.ImportedAt, &settlementImport.Summary.Matched,
+		&settlementImport.Summary.Unmatched, &settlementImport.Summary.Ambiguous,
+		&settlementImport.Summary.Resolved, &settlementImport.Summary.Dismissed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settlementImport, nil
+}
+
+func scanItem(row rowScanner) (*Item, error) {
+	var (
+		item           Item
+		reference      sql.NullString
+		description    sql.NullString
+		transactionID  sql.NullString
+		candidateIDs   sql.NullString
+		resolvedBy     sql.NullString
+		resolvedAt     sql.NullTime
+		resolutionNote sql.NullString
+	)
+
+	err := row.Scan(&item.ID, &item.TenantID, &item.ImportID, &item.Line, &reference, &item.Amount,
+		&item.Currency, &item.BookingDate, &description, &item.Status, &transactionID, &candidateIDs,
+		&resolvedBy, &resolvedAt, &resolutionNote)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Reference = reference.String
+	item.Description = description.String
+	item.TransactionID = transactionID.String
+	item.ResolvedBy = resolvedBy.String
+	item.ResolutionNote = resolutionNote.String
+
+	if candidateIDs.String != "" {
+		item.CandidateIDs = strings.Split(candidateIDs.String, ",")
+	}
+
+	if resolvedAt.Valid {
+		item.ResolvedAt = &resolvedAt.Time
+	}
+
+	return &item, nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func (r *repository) CreateImport(ctx context.Context, settlementImport *Import) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	settlementImport.ID = uuid.New().String()
+	settlementImport.TenantID = tenantID
+
+	// NOTE: The range lock on the checksum serialises concurrent imports of the same file.
+	query := `INSERT INTO settlement_imports (id, tenant_id, format, file_name, checksum, lines, imported_by,
+                  imported_at)
+              SELECT @id, @tenant_id, @format, @file_name, @checksum, @lines, @imported_by, @imported_at
+              WHERE NOT EXISTS (
+                  SELECT 1 FROM settlement_imports WITH (UPDLOCK, HOLDLOCK)
+                  WHERE tenant_id = @tenant_id AND checksum = @checksum)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "settlement.Repository/CreateImport", query)
+	defer func() { tracing.End(span, err) }()
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("id", settlementImport.ID),
+		sql.Named("tenant_id", settlementImport.TenantID),
+		sql.Named("format", string(settlementImport.Format)),
+		sql.Named("file_name", settlementImport.FileName),
+		sql.Named("checksum", settlementImport.Checksum),
+		sql.Named("lines", settlementImport.Lines),
+		sql.Named("imported_by", settlementImport.ImportedBy),
+		sql.Named("imported_at", settlementImport.ImportedAt),
+	)
+	if err != nil {
+		return errors.New("failed to insert settlement import into database: " + err.Error())
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return errors.New("failed to insert settlement import into database: " + err.Error())
+	}
+
+	if inserted == 0 {
+		return ErrDuplicateImport
+	}
+
+	return nil
+}
+
+func (r *repository) GetImport(ctx context.Context, id string) (_ *Import, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + importColumns + `
+              FROM settlement_imports i LEFT JOIN settlement_items s ON s.import_id = i.id
+              WHERE i.id = @id AND i.tenant_id = @tenant_id
+              ` + importGroupBy
+
+	ctx, span := tracing.StartQuerySpan(ctx, "settlement.Repository/GetImport", query)
+	defer func() { tracing.End(span, err) }()
+
+	settlementImport, err := scanImport(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrImportNotFound
+		}
+		return nil, errors.New("failed to retrieve settlement import: " + err.Error())
+	}
+
+	return settlementImport, nil
+}
+
+func (r *repository) ListImports(ctx context.Context) (_ []*Import, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + importColumns + `
+              FROM settlement_imports i LEFT JOIN settlement_items s ON s.import_id = i.id
+              WHERE i.tenant_id = @tenant_id
+              ` + importGroupBy + `
+              ORDER BY i.imported_at DESC`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "settlement.Repository/ListImports", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, sql.Named("tenant_id", tenantID))
+	if err != nil {
+		return nil, errors.New("failed to list settlement imports: " + err.Error())
+	}
+	defer rows.Close()
+
+	imports := make([]*Import, 0)
+
+	for rows.Next() {
+		settlementImport, err := scanImport(rows)
+		if err != nil {
+			return nil, errors.New("failed to scan settlement import: " + err.Error())
+		}
+
+		imports = append(imports, settlementImport)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list settlement imports: " + err.Error())
+	}
+
+	return imports, nil
+}
+
+func (r *repository) CreateItem(ctx context.Context, item *Item) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	item.ID = uuid.New().String()
+	item.TenantID = tenantID
+
+	query := `INSERT INTO settlement_items (id, tenant_id, import_id, line, reference, amount, currency,
+                  booking_date, description, status, transaction_id, candidate_ids)
+              VALUES (@id, @tenant_id, @import_id, @line, @reference, @amount, @currency,
+                  @booking_date, @description, @status, @transaction_id, @candidate_ids)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "settlement.Repository/CreateItem", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("id", item.ID),
+		sql.Named("tenant_id", item.TenantID),
+		sql.Named("import_id", item.ImportID),
+		sql.Named("line", item.Line),
+		sql.Named("reference", nullString(item.Reference)),
+		sql.Named("amount", item.Amount),
+		sql.Named("currency", item.Currency),
+		sql.Named("booking_date", item.BookingDate),
+		sql.Named("description", nullString(item.Description)),
+		sql.Named("status", string(item.Status)),
+		sql.Named("transaction_id", nullString(item.TransactionID)),
+		sql.Named("candidate_ids", nullString(strings.Join(item.CandidateIDs, ","))),
+	)
+	if err != nil {
+		return errors.New("failed to insert settlement item into database: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *repository) GetItem(ctx context.Context, importID string, id string) (_ *Item, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + itemColumns + `
+              FROM settlement_items WHERE id = @id AND import_id = @import_id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "settlement.Repository/GetItem", query)
+	defer func() { tracing.End(span, err) }()
+
+	item, err := scanItem(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("id", id),
+		sql.Named("import_id", importID),
+		sql.Named("tenant_id", tenantID),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrItemNotFound
+		}
+		return nil, errors.New("failed to retrieve settlement item: " + err.Error())
+	}
+
+	return item, nil
+}
+
+func (r *repository) ListItems(ctx context.Context, importID string, status Status) (_ []*Item, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []string{"tenant_id = @tenant_id", "import_id = @import_id"}
+	args := []interface{}{sql.Named("tenant_id", tenantID), sql.Named("import_id", importID)}
+
+	if status != "" {
+		conditions = append(conditions, "status = @status")
+		args = append(args, sql.Named("status", string(status)))
+	}
+
+	query := `SELECT ` + itemColumns + `
+              FROM settlement_items WHERE ` + strings.Join(conditions, " AND ") + `
+              ORDER BY line`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "settlement.Repository/ListItems", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.New("failed to list settlement items: " + err.Error())
+	}
+	defer rows.Close()
+
+	items := make([]*Item, 0)
+
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, errors.New("failed to scan settlement item: " + err.Error())
+		}
+
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list settlement items: " + err.Error())
+	}
+
+	return items, nil
+}
+
+func (r *repository) Resolve(
+	ctx context.Context,
+	id string,
+	status Status,
+	resolution Resolution,
+) (_ *Item, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE settlement_items
+              SET status = @status, transaction_id = @transaction_id, resolved_by = @resolved_by,
+                  resolved_at = @resolved_at, resolution_note = @resolution_note
+              OUTPUT ` + outputColumns() + `
+              WHERE id = @id AND tenant_id = @tenant_id AND status IN (@unmatched, @ambiguous)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "settlement.Repository/Resolve", query)
+	defer func() { tracing.End(span, err) }()
+
+	item, err := scanItem(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("status", string(status)),
+		sql.Named("transaction_id", nullString(resolution.TransactionID)),
+		sql.Named("resolved_by", resolution.ResolvedBy),
+		sql.Named("resolved_at", time.Now().UTC()),
+		sql.Named("resolution_note", resolution.Note),
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+		sql.Named("unmatched", string(StatusUnmatched)),
+		sql.Named("ambiguous", string(StatusAmbiguous)),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrItemNotOpen
+		}
+		return nil, errors.New("failed to resolve settlement item: " + err.Error())
+	}
+
+	return item, nil
+}
+
+func (r *repository) FindDeposits(ctx context.Context, depositQuery DepositQuery) (_ []string, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []string{
+		"t.tenant_id = @tenant_id",
+		"t.type = 'deposit'",
+		"t.currency = @currency",
+		"t.amount BETWEEN @min_amount AND @max_amount",
+		// Fully reversed deposits were paid back, the bank cannot settle them anymore.
+		"t.reversed_amount < t.amount",
+		"NOT EXISTS (SELECT 1 FROM settlement_items s WHERE s.transaction_id = t.id)",
+	}
+	args := []interface{}{
+		sql.Named("tenant_id", tenantID),
+		sql.Named("currency", depositQuery.Currency),
+		sql.Named("min_amount", depositQuery.MinAmount),
+		sql.Named("max_amount", depositQuery.MaxAmount),
+		sql.Named("limit", maxCandidates),
+	}
+
+	if depositQuery.Reference != "" {
+		conditions = append(conditions, "t.reference = @reference")
+		args = append(args, sql.Named("reference", depositQuery.Reference))
+	} else {
+		conditions = append(conditions, "t.reference IS NULL", "t.created_at >= @from", "t.created_at < @to")
+		args = append(args, sql.Named("from", depositQuery.From), sql.Named("to", depositQuery.To))
+	}
+
+	query := `SELECT TOP (@limit) t.id
+              FROM transactions t WHERE ` + strings.Join(conditions, " AND ") + `
+              ORDER BY t.created_at`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "settlement.Repository/FindDeposits", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.New("failed to find deposits: " + err.Error())
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.New("failed to scan deposit: " + err.Error())
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to find deposits: " + err.Error())
+	}
+
+	return ids, nil
+}
+
+func (r *repository) IsMatched(ctx context.Context, transactionID string) (_ bool, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	query := `SELECT COUNT(*) FROM settlement_items
+              WHERE transaction_id = @transaction_id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "settlement.Repository/IsMatched", query)
+	defer func() { tracing.End(span, err) }()
+
+	var count int
+
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("transaction_id", transactionID),
+		sql.Named("tenant_id", tenantID),
+	).Scan(&count)
+	if err != nil {
+		return false, errors.New("failed to check settlement item: " + err.Error())
+	}
+
+	return count > 0, nil
+}
+
+// outputColumns returns itemColumns prefixed for an OUTPUT clause.
+func outputColumns() string {
+	columns := strings.Split(itemColumns, ",")
+	for i, column := range columns {
+		columns[i] = "inserted." + strings.TrimSpace(column)
+	}
+
+	return strings.Join(columns, ", ")
+}
@@ -0,0 +1,359 @@
+package settlement
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+var (
+	ErrImportNotFound    = errors.New("settlement import not found")
+	ErrItemNotFound      = errors.New("settlement item not found")
+	ErrDuplicateImport   = errors.New("settlement file already imported")
+	ErrItemNotOpen       = errors.New("settlement item is not open")
+	ErrNotDeposit        = errors.New("transaction is not a deposit")
+	ErrCurrencyMismatch  = errors.New("transaction currency differs from the settlement item")
+	ErrAlreadyMatched    = errors.New("deposit already matched to a settlement item")
+	ErrDepositReversed   = errors.New("deposit is fully reversed")
+	ErrOperatorRequired  = errors.New("operator is required")
+	ErrNoteRequired      = errors.New("resolution note is required")
+	ErrFileNameRequired  = errors.New("file name is required")
+	ErrEmptyStatement    = errors.New("settlement file has no credit lines")
+	ErrInvalidItemStatus = errors.New("invalid settlement item status")
+)
+
+// Service imports settlement files, matches their lines against the deposits and lets operators resolve
+// the lines that could not be matched automatically.
+type Service interface {
+	Import(ctx context.Context, statement *Statement, fileName string, importedBy string) (*Import, error)
+	GetImport(ctx context.Context, id string) (*Import, error)
+	ListImports(ctx context.Context) ([]*Import, error)
+	ListItems(ctx context.Context, importID string, status Status) ([]*Item, error)
+	Resolve(ctx context.Context, importID string, itemID string, resolution Resolution) (*Item, error)
+}
+
+// Audit log resource types and actions. Resolved items are audited as `settlement_item.<status>`,
+// e.g. `settlement_item.dismissed`.
+const (
+	ImportResourceType = "settlement_import"
+	ItemResourceType   = "settlement_item"
+
+	AuditActionImported = "settlement_import.imported"
+)
+
+// DefaultTolerance matches amounts exactly and deposits made up to three days around the booking date.
+var DefaultTolerance = Tolerance{Date: 72 * time.Hour}
+
+type service struct {
+	repo      Repository
+	wallets   wallet.Service
+	auditLog  audit.Recorder
+	tolerance Tolerance
+}
+
+type serviceOption func(*service)
+
+// WithAuditLog sets the audit log recording imports and resolutions in the same database transaction.
+func WithAuditLog(auditLog audit.Recorder) serviceOption {
+	return func(s *service) {
+		s.auditLog = auditLog
+	}
+}
+
+// WithTolerance sets how far a deposit may be from a line to match it, DefaultTolerance otherwise.
+func WithTolerance(tolerance Tolerance) serviceOption {
+	return func(s *service) {
+		s.tolerance = tolerance
+	}
+}
+
+func NewService(repo Repository, wallets wallet.Service, options ...serviceOption) Service {
+	s := &service{
+		repo:      repo,
+		wallets:   wallets,
+		auditLog:  audit.NopRecorder{},
+		tolerance: DefaultTolerance,
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s
+}
+
+// Import records the statement and matches each line against the deposits of the tenant not matched yet.
+// A deposit is matched to one line at most, across all imports.
+func (s *service) Import(
+	ctx context.Context,
+	statement *Statement,
+	fileName string,
+	importedBy string,
+) (*Import, error) {
+	if importedBy == "" {
+		return nil, ErrOperatorRequired
+	}
+
+	if strings.TrimSpace(fileName) == "" {
+		return nil, ErrFileNameRequired
+	}
+
+	if len(statement.Lines) == 0 {
+		return nil, ErrEmptyStatement
+	}
+
+	settlementImport := &Import{
+		Format:     statement.Format,
+		FileName:   fileName,
+		Checksum:   statement.Checksum,
+		Lines:      len(statement.Lines),
+		ImportedBy: importedBy,
+		ImportedAt: time.Now().UTC(),
+	}
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateImport(ctx, settlementImport); err != nil {
+			return err
+		}
+
+		settlementImport.Summary = Summary{}
+		matched := make(map[string]bool)
+
+		for _, line := range statement.Lines {
+			item, err := s.match(ctx, line, matched)
+			if err != nil {
+				return err
+			}
+
+			item.ImportID = settlementImport.ID
+
+			if err := s.repo.CreateItem(ctx, item); err != nil {
+				return err
+			}
+
+			settlementImport.Summary.add(item.Status)
+		}
+
+		return s.auditLog.Record(
+			ctx, AuditActionImported, ImportResourceType, settlementImport.ID, nil, settlementImport,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Settlement file imported",
+		zap.String("import_id", settlementImport.ID),
+		zap.String("file_name", settlementImport.FileName),
+		zap.Int("lines", settlementImport.Lines),
+		zap.Int("matched", settlementImport.Summary.Matched),
+		zap.Int("unmatched", settlementImport.Summary.Unmatched),
+		zap.Int("ambiguous", settlementImport.Summary.Ambiguous),
+	)
+
+	return settlementImport, nil
+}
+
+// match looks the line up by reference first. Lines without a reference, or whose reference matches no deposit,
+// are matched by amount and date against the deposits without a reference: a deposit carrying another reference
+// is another payment. Deposits in matched were taken by earlier lines of the file.
+func (s *service) match(ctx context.Context, line Line, matched map[string]bool) (*Item, error) {
+	item := &Item{
+		Line:        line.Number,
+		Reference:   line.Reference,
+		Amount:      line.Amount,
+		Currency:    line.Currency,
+		BookingDate: line.BookingDate,
+		Description: line.Description,
+	}
+
+	query := DepositQuery{
+		Currency:  line.Currency,
+		MinAmount: line.Amount - s.tolerance.Amount,
+		MaxAmount: line.Amount + s.tolerance.Amount,
+	}
+
+	var candidates []string
+
+	if line.Reference != "" {
+		query.Reference = line.Reference
+
+		ids, err := s.repo.FindDeposits(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates = unmatched(ids, matched)
+	}
+
+	if len(candidates) == 0 {
+		query.Reference = ""
+		query.From = line.BookingDate.Add(-s.tolerance.Date)
+		query.To = line.BookingDate.Add(24*time.Hour + s.tolerance.Date)
+
+		ids, err := s.repo.FindDeposits(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates = unmatched(ids, matched)
+	}
+
+	switch len(candidates) {
+	case 0:
+		item.Status = StatusUnmatched
+	case 1:
+		item.Status = StatusMatched
+		item.TransactionID = candidates[0]
+		matched[candidates[0]] = true
+	default:
+		item.Status = StatusAmbiguous
+		item.CandidateIDs = candidates
+	}
+
+	return item, nil
+}
+
+func unmatched(ids []string, matched map[string]bool) []string {
+	result := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		if !matched[id] {
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
+
+func (s *service) GetImport(ctx context.Context, id string) (*Import, error) {
+	return s.repo.GetImport(ctx, id)
+}
+
+func (s *service) ListImports(ctx context.Context) ([]*Import, error) {
+	return s.repo.ListImports(ctx)
+}
+
+func (s *service) ListItems(ctx context.Context, importID string, status Status) ([]*Item, error) {
+	switch status {
+	case "", StatusMatched, StatusUnmatched, StatusAmbiguous, StatusResolved, StatusDismissed:
+	default:
+		return nil, ErrInvalidItemStatus
+	}
+
+	if _, err := s.repo.GetImport(ctx, importID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListItems(ctx, importID, status)
+}
+
+// Resolve matches an unmatched or ambiguous item to a deposit chosen by the operator, or dismisses it.
+// The deposit must have the currency of the item and not be matched to another item; its amount may differ,
+// e.g. when the bank deducted fees.
+func (s *service) Resolve(ctx context.Context, importID string, itemID string, resolution Resolution) (*Item, error) {
+	if resolution.ResolvedBy == "" {
+		return nil, ErrOperatorRequired
+	}
+
+	if strings.TrimSpace(resolution.Note) == "" {
+		return nil, ErrNoteRequired
+	}
+
+	var item *Item
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetItem(ctx, importID, itemID)
+		if err != nil {
+			return err
+		}
+
+		if !before.Status.Open() {
+			return ErrItemNotOpen
+		}
+
+		status := StatusDismissed
+
+		if resolution.TransactionID != "" {
+			if err := s.checkDeposit(ctx, before, resolution.TransactionID); err != nil {
+				return err
+			}
+
+			status = StatusResolved
+		}
+
+		item, err = s.repo.Resolve(ctx, before.ID, status, resolution)
+		if err != nil {
+			return err
+		}
+
+		return s.auditLog.Record(ctx, ItemResourceType+"."+string(item.Status), ItemResourceType, item.ID, before, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Settlement item resolved",
+		zap.String("import_id", item.ImportID),
+		zap.String("item_id", item.ID),
+		zap.String("status", string(item.Status)),
+		zap.String("transaction_id", item.TransactionID),
+		zap.String("resolved_by", item.ResolvedBy),
+	)
+
+	return item, nil
+}
+
+func (s *service) checkDeposit(ctx context.Context, item *Item, transactionID string) error {
+	transaction, err := s.wallets.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+
+	if transaction.Type != wallet.TransactionDeposit {
+		return ErrNotDeposit
+	}
+
+	if transaction.Currency != item.Currency {
+		return ErrCurrencyMismatch
+	}
+
+	if transaction.ReversibleAmount() <= 0 {
+		return ErrDepositReversed
+	}
+
+	matched, err := s.repo.IsMatched(ctx, transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	if matched {
+		return ErrAlreadyMatched
+	}
+
+	return nil
+}
+
+func (s *Summary) add(status Status) {
+	switch status {
+	case StatusMatched:
+		s.Matched++
+	case StatusUnmatched:
+		s.Unmatched++
+	case StatusAmbiguous:
+		s.Ambiguous++
+	case StatusResolved:
+		s.Resolved++
+	case StatusDismissed:
+		s.Dismissed++
+	}
+}
@@ -0,0 +1,114 @@
+// Package settlement matches the credit lines of the settlement files of the bank, CSV or ISO 20022 camt.053
+// statements, against the recorded deposits so operators can investigate the breaks.
+package settlement
+
+import (
+	"time"
+)
+
+// Format is the file format of a settlement file.
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatCamt053 Format = "camt053"
+)
+
+// Status is the matching state of a settlement item.
+type Status string
+
+const (
+	// StatusMatched items were matched to exactly one deposit when the file was imported.
+	StatusMatched Status = "matched"
+	// StatusUnmatched items match no deposit.
+	StatusUnmatched Status = "unmatched"
+	// StatusAmbiguous items match several deposits, listed as candidates.
+	StatusAmbiguous Status = "ambiguous"
+	// StatusResolved items were matched to a deposit by an operator.
+	StatusResolved Status = "resolved"
+	// StatusDismissed items were closed by an operator without a deposit, e.g. a payment returned to the payer.
+	StatusDismissed Status = "dismissed"
+)
+
+// Open tells whether the item is a break waiting for an operator.
+func (s Status) Open() bool {
+	return s == StatusUnmatched || s == StatusAmbiguous
+}
+
+// Line is a credit line of a settlement file.
+type Line struct {
+	// Number is the position of the line among the lines of the file, starting at 1.
+	Number      int
+	Reference   string
+	Amount      float64
+	Currency    string
+	BookingDate time.Time
+	Description string
+}
+
+// Statement is a parsed settlement file.
+type Statement struct {
+	Format Format
+	// Checksum is the SHA-256 of the file, so the same file is never imported twice.
+	Checksum string
+	Lines    []Line
+	// SkippedDebits is the number of debit lines, which are not matched against deposits.
+	SkippedDebits int
+}
+
+// Summary counts the items of an import by status.
+type Summary struct {
+	Matched   int `json:"matched"`
+	Unmatched int `json:"unmatched"`
+	Ambiguous int `json:"ambiguous"`
+	Resolved  int `json:"resolved"`
+	Dismissed int `json:"dismissed"`
+}
+
+// Import is an imported settlement file.
+type Import struct {
+	ID         string    `json:"id" db:"id"`
+	TenantID   string    `json:"tenant_id" db:"tenant_id"`
+	Format     Format    `json:"format" db:"format"`
+	FileName   string    `json:"file_name" db:"file_name"`
+	Checksum   string    `json:"checksum" db:"checksum"`
+	Lines      int       `json:"lines" db:"lines"`
+	ImportedBy string    `json:"imported_by" db:"imported_by"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+	Summary    Summary   `json:"summary"`
+}
+
+// Item is a line of an imported settlement file and the deposit it was matched to.
+type Item struct {
+	ID             string     `json:"id" db:"id"`
+	TenantID       string     `json:"tenant_id" db:"tenant_id"`
+	ImportID       string     `json:"import_id" db:"import_id"`
+	Line           int        `json:"line" db:"line"`
+	Reference      string     `json:"reference,omitempty" db:"reference"`
+	Amount         float64    `json:"amount" db:"amount"`
+	Currency       string     `json:"currency" db:"currency"`
+	BookingDate    time.Time  `json:"booking_date" db:"booking_date"`
+	Description    string     `json:"description,omitempty" db:"description"`
+	Status         Status     `json:"status" db:"status"`
+	TransactionID  string     `json:"transaction_id,omitempty" db:"transaction_id"`
+	CandidateIDs   []string   `json:"candidate_ids,omitempty" db:"candidate_ids"`
+	ResolvedBy     string     `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	ResolutionNote string     `json:"resolution_note,omitempty" db:"resolution_note"`
+}
+
+// Resolution closes an unmatched or ambiguous item. The item is matched to the deposit TransactionID,
+// or dismissed when TransactionID is empty.
+type Resolution struct {
+	TransactionID string
+	Note          string
+	ResolvedBy    string
+}
+
+// Tolerance bounds how far a deposit may be from a line to match it.
+type Tolerance struct {
+	// Amount is the largest difference between the amounts.
+	Amount float64
+	// Date is the largest difference between the booking date and the deposit time.
+	Date time.Duration
+}
@@ -0,0 +1,50 @@
+package snapshot
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// WriteReport writes the report as JSON, or as CSV with one line per wallet.
+func WriteReport(w io.Writer, report *Report, format string) error {
+	if format == FormatCSV {
+		return writeCSV(w, report)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+func writeCSV(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"date", "wallet_id", "currency", "balance", "snapshot"}); err != nil {
+		return err
+	}
+
+	for _, balance := range report.Balances {
+		err := writer.Write([]string{
+			report.Date,
+			balance.WalletID,
+			balance.Currency,
+			strconv.FormatFloat(balance.Balance, 'f', 2, 64),
+			strconv.FormatBool(balance.Snapshot),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,199 @@
+// Package snapshot records the end-of-day balance of every wallet and reports the balances of the wallets at the
+// end of a day, e.g. for the month-end close. Snapshots also bound the transactions summed up to compute the
+// balance of a wallet at a past instant, see wallet.Repository.
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+var ErrDayNotOver = errors.New("day is not over")
+
+// Balance is the balance of a wallet at the end of a day.
+type Balance struct {
+	WalletID string  `json:"wallet_id"`
+	Currency string  `json:"currency"`
+	Balance  float64 `json:"balance"`
+	// Snapshot tells whether the balance was read from a snapshot rather than computed from the transactions.
+	Snapshot bool `json:"snapshot"`
+}
+
+// Total is the sum of the end-of-day balances of the wallets in a currency.
+type Total struct {
+	Currency string  `json:"currency"`
+	Wallets  int     `json:"wallets"`
+	Balance  float64 `json:"balance"`
+}
+
+// Report lists the balances of the wallets of a tenant at the end of Date, i.e. of their transactions created
+// before the next day (UTC). Wallets created later are left out.
+type Report struct {
+	TenantID    string    `json:"tenant_id"`
+	Date        string    `json:"date"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Totals      []Total   `json:"totals"`
+	Balances    []Balance `json:"balances"`
+}
+
+type Snapshotter struct {
+	db *sql.DB
+}
+
+func NewSnapshotter(db *sql.DB) *Snapshotter {
+	return &Snapshotter{db: db}
+}
+
+// Day returns the UTC day of t, truncated to midnight.
+func Day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// Take records the balance at the end of day of every wallet of every tenant existing by then. Wallets already
+// snapshotted for the day are skipped, so taking the snapshots again is safe. It returns the number of recorded
+// snapshots and ErrDayNotOver when the day has not ended yet.
+func (s *Snapshotter) Take(ctx context.Context, day time.Time) (_ int64, err error) {
+	day = Day(day)
+	end := day.AddDate(0, 0, 1)
+
+	if end.After(time.Now()) {
+		return 0, ErrDayNotOver
+	}
+
+	// NOTE: Each snapshot starts from the previous one of the wallet, so only the transactions of the days since
+	// are summed up.
+	query := `INSERT INTO balance_snapshots (tenant_id, wallet_id, balance_date, balance, currency, taken_at)
+              SELECT w.tenant_id, w.id, @day,
+                  COALESCE(p.balance, 0) + COALESCE((
+                      SELECT SUM(CASE t.direction WHEN 'credit' THEN t.amount ELSE -t.amount END)
+                      FROM transactions t
+                      WHERE t.wallet_id = w.id AND t.tenant_id = w.tenant_id AND t.created_at < @end
+                          AND (p.balance_date IS NULL
+                              OR t.created_at >= DATEADD(day, 1, CAST(p.balance_date AS DATETIME)))
+                  ), 0),
+                  w.currency, @taken_at
+              FROM wallets w
+              OUTER APPLY (
+                  SELECT TOP 1 s.balance, s.balance_date
+                  FROM balance_snapshots s
+                  WHERE s.tenant_id = w.tenant_id AND s.wallet_id = w.id AND s.balance_date < @day
+                  ORDER BY s.balance_date DESC
+              ) p
+              WHERE w.created_at < @end
+                  AND NOT EXISTS (
+                      SELECT 1 FROM balance_snapshots s WITH (UPDLOCK, HOLDLOCK)
+                      WHERE s.tenant_id = w.tenant_id AND s.wallet_id = w.id AND s.balance_date = @day
+                  )`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "snapshot.Snapshotter/Take", query)
+	defer func() { tracing.End(span, err) }()
+
+	result, err := s.db.ExecContext(ctx, query,
+		sql.Named("day", day.Format(time.DateOnly)),
+		sql.Named("end", end),
+		sql.Named("taken_at", time.Now().UTC()),
+	)
+	if err != nil {
+		return 0, errors.New("failed to take balance snapshots: " + err.Error())
+	}
+
+	taken, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.New("failed to take balance snapshots: " + err.Error())
+	}
+
+	logging.FromContext(ctx, nil).Info(
+		"Balance snapshots taken",
+		zap.String("date", day.Format(time.DateOnly)),
+		zap.Int64("snapshots", taken),
+	)
+
+	return taken, nil
+}
+
+// EndOfDay reports the balances of the wallets of the tenant in ctx at the end of day. Balances are read from
+// the snapshots of the day, and computed from the transactions of the wallets not snapshotted.
+func (s *Snapshotter) EndOfDay(ctx context.Context, day time.Time) (_ *Report, err error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	day = Day(day)
+	end := day.AddDate(0, 0, 1)
+
+	if end.After(time.Now()) {
+		return nil, ErrDayNotOver
+	}
+
+	query := `SELECT w.id, w.currency, CASE WHEN s.balance IS NULL THEN 0 ELSE 1 END,
+                  COALESCE(s.balance, (
+                      SELECT COALESCE(SUM(CASE t.direction WHEN 'credit' THEN t.amount ELSE -t.amount END), 0)
+                      FROM transactions t
+                      WHERE t.wallet_id = w.id AND t.tenant_id = w.tenant_id AND t.created_at < @end
+                  ))
+              FROM wallets w
+              LEFT JOIN balance_snapshots s
+                  ON s.tenant_id = w.tenant_id AND s.wallet_id = w.id AND s.balance_date = @day
+              WHERE w.tenant_id = @tenant_id AND w.created_at < @end
+              ORDER BY w.currency, w.id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "snapshot.Snapshotter/EndOfDay", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := s.db.QueryContext(ctx, query,
+		sql.Named("tenant_id", t.ID),
+		sql.Named("day", day.Format(time.DateOnly)),
+		sql.Named("end", end),
+	)
+	if err != nil {
+		return nil, errors.New("failed to read end-of-day balances: " + err.Error())
+	}
+	defer rows.Close()
+
+	report := &Report{
+		TenantID:    t.ID,
+		Date:        day.Format(time.DateOnly),
+		GeneratedAt: time.Now().UTC(),
+		Totals:      make([]Total, 0),
+		Balances:    make([]Balance, 0),
+	}
+
+	for rows.Next() {
+		var balance Balance
+
+		if err := rows.Scan(&balance.WalletID, &balance.Currency, &balance.Snapshot, &balance.Balance); err != nil {
+			return nil, errors.New("failed to scan end-of-day balance: " + err.Error())
+		}
+
+		report.add(balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to read end-of-day balances: " + err.Error())
+	}
+
+	return report, nil
+}
+
+// add appends the balance to the report, which expects the balances ordered by currency.
+func (r *Report) add(balance Balance) {
+	r.Balances = append(r.Balances, balance)
+
+	if len(r.Totals) == 0 || r.Totals[len(r.Totals)-1].Currency != balance.Currency {
+		r.Totals = append(r.Totals, Total{Currency: balance.Currency})
+	}
+
+	total := &r.Totals[len(r.Totals)-1]
+	total.Wallets++
+	total.Balance = wallet.RoundCents(total.Balance + balance.Balance)
+}
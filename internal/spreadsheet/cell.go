@@ -0,0 +1,17 @@
+// Package spreadsheet keeps the CSV files of the service safe to open in spreadsheet applications.
+package spreadsheet
+
+import "strings"
+
+// formulaPrefixes are the first characters making spreadsheet applications read a cell as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// Cell returns value for a CSV cell of text coming from clients, operators or bank files. Values read as formulas,
+// e.g. `=HYPERLINK(...)`, are prefixed with a `'` so they are shown as text instead of being evaluated.
+func Cell(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
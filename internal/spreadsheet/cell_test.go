@@ -0,0 +1,26 @@
+package spreadsheet
+
+import "testing"
+
+func TestCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"INV-42", "INV-42"},
+		{"=HYPERLINK(\"https://evil.test\")", "'=HYPERLINK(\"https://evil.test\")"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+	}
+
+	for _, tc := range tests {
+		if got := Cell(tc.value); got != tc.want {
+			t.Errorf("Cell(%q) = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/spreadsheet"
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+// writeCSV writes one line per movement, framed by the opening and closing balance lines.
+func writeCSV(w io.Writer, statement *wallet.Statement) error {
+	writer := csv.NewWriter(w)
+
+	records := [][]string{
+		{"date", "transaction_id", "type", "reference", "description", "amount", "balance"},
+		{formatTime(statement.From), "", "opening_balance", "", "", "", formatAmount(statement.OpeningBalance)},
+	}
+
+	for _, entry := range statement.Entries {
+		amount := entry.Amount
+		if entry.Direction == wallet.DirectionDebit {
+			amount = -amount
+		}
+
+		records = append(records, []string{
+			formatTime(entry.CreatedAt),
+			entry.TransactionID,
+			string(entry.Type),
+			spreadsheet.Cell(entry.Reference),
+			spreadsheet.Cell(entry.Reason),
+			formatAmount(amount),
+			formatAmount(entry.Balance),
+		})
+	}
+
+	records = append(records, []string{
+		formatTime(statement.To), "", "closing_balance", "", "", "", formatAmount(statement.ClosingBalance),
+	})
+
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
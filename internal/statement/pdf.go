@@ -0,0 +1,230 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+// The PDF is laid out on A4 pages in the monospaced standard Courier font, so the columns align without
+// font metrics and no font has to be embedded.
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	pageMargin   = 50
+	fontSize     = 8
+	titleSize    = 12
+	lineHeight   = 11
+	linesPerPage = (pageHeight-2*pageMargin)/lineHeight - 2
+)
+
+const (
+	dateColumn        = 16
+	typeColumn        = 15
+	descriptionColumn = 38
+	amountColumn      = 15
+)
+
+type pdfLine struct {
+	text string
+	bold bool
+	size int
+}
+
+// writePDF renders the statement as a PDF document, the table header repeated on every page.
+func writePDF(w io.Writer, statement *wallet.Statement) error {
+	header := pdfLine{
+		text: tableRow("Date", "Type", "Reference / description", "Amount", "Balance"),
+		bold: true,
+	}
+
+	lines := []pdfLine{
+		{text: "Account statement", bold: true, size: titleSize},
+		{},
+		{text: "Wallet:   " + statement.WalletID},
+		{text: "Currency: " + statement.Currency},
+		{text: "Period:   " + formatPDFTime(statement.From) + " - " + formatPDFTime(statement.To)},
+		{},
+		header,
+		{text: tableRow(
+			formatPDFTime(statement.From), "", "Opening balance", "", formatAmount(statement.OpeningBalance),
+		)},
+	}
+
+	for _, entry := range statement.Entries {
+		amount := entry.Amount
+		if entry.Direction == wallet.DirectionDebit {
+			amount = -amount
+		}
+
+		description := entry.Reference
+		if description == "" {
+			description = entry.Reason
+		}
+
+		lines = append(lines, pdfLine{text: tableRow(
+			formatPDFTime(entry.CreatedAt),
+			string(entry.Type),
+			description,
+			formatAmount(amount),
+			formatAmount(entry.Balance),
+		)})
+	}
+
+	lines = append(lines,
+		pdfLine{text: tableRow(
+			formatPDFTime(statement.To), "", "Closing balance", "", formatAmount(statement.ClosingBalance),
+		)},
+		pdfLine{},
+		pdfLine{text: "Total credits: " + formatAmount(statement.TotalCredits) + " " + statement.Currency},
+		pdfLine{text: "Total debits:  " + formatAmount(statement.TotalDebits) + " " + statement.Currency},
+		pdfLine{text: "Generated at:  " + formatPDFTime(statement.GeneratedAt)},
+	)
+
+	return renderPDF(w, paginate(lines, header))
+}
+
+func tableRow(date, transactionType, description, amount, balance string) string {
+	return pad(date, dateColumn) + " " + pad(transactionType, typeColumn) + " " +
+		pad(description, descriptionColumn) + " " + padLeft(amount, amountColumn) + " " + padLeft(balance, amountColumn)
+}
+
+func pad(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+
+	return value + strings.Repeat(" ", width-len(runes))
+}
+
+func padLeft(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) >= width {
+		return value
+	}
+
+	return strings.Repeat(" ", width-len(runes)) + value
+}
+
+func formatPDFTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04")
+}
+
+// paginate splits the lines into pages, starting every page after the first with header.
+func paginate(lines []pdfLine, header pdfLine) [][]pdfLine {
+	pages := make([][]pdfLine, 0, len(lines)/linesPerPage+1)
+	page := make([]pdfLine, 0, linesPerPage)
+
+	for _, line := range lines {
+		if len(page) == linesPerPage {
+			pages = append(pages, page)
+			page = []pdfLine{header}
+		}
+
+		page = append(page, line)
+	}
+
+	return append(pages, page)
+}
+
+// renderPDF writes a PDF 1.4 document with one text page per element of pages.
+func renderPDF(w io.Writer, pages [][]pdfLine) error {
+	var (
+		buf     bytes.Buffer
+		offsets []int
+	)
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 4 are the catalog, the page tree and the fonts, followed by a page and a content stream per page.
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		content := pageContent(page, i+1, len(pages))
+
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> "+
+				"/Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i,
+		))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
+
+func pageContent(lines []pdfLine, number int, count int) string {
+	var content strings.Builder
+
+	y := pageHeight - pageMargin
+
+	for _, line := range lines {
+		if line.text != "" {
+			font, size := "F1", fontSize
+			if line.bold {
+				font = "F2"
+			}
+
+			if line.size != 0 {
+				size = line.size
+			}
+
+			fmt.Fprintf(&content, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, pageMargin, y, pdfText(line.text))
+		}
+
+		y -= lineHeight
+	}
+
+	fmt.Fprintf(&content, "BT /F1 %d Tf %d %d Td (%s) Tj ET", fontSize, pageMargin, pageMargin/2,
+		pdfText(fmt.Sprintf("Page %d of %d", number, count)))
+
+	return content.String()
+}
+
+// pdfText encodes text as the bytes of a PDF literal string. Characters outside Latin-1 are replaced with `?`.
+func pdfText(text string) string {
+	encoded := make([]byte, 0, len(text))
+
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			encoded = append(encoded, '\\', byte(r))
+		case r < 0x20 || r > 0xff || (r >= 0x7f && r < 0xa0):
+			encoded = append(encoded, '?')
+		default:
+			encoded = append(encoded, byte(r))
+		}
+	}
+
+	return string(encoded)
+}
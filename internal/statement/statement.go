@@ -0,0 +1,85 @@
+// Package statement renders wallet statements as JSON, CSV or PDF. Rendering needs no external service,
+// so statements can be produced offline with the `statement` command.
+package statement
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/wallet"
+)
+
+// Format is the file format of a rendered statement.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+	FormatPDF  Format = "pdf"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported statement format")
+	ErrInvalidPeriod     = errors.New("invalid statement period")
+)
+
+// ParseFormat returns the format named by value, FormatJSON when value is empty.
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatCSV, FormatPDF:
+		return Format(value), nil
+	}
+
+	return "", ErrUnsupportedFormat
+}
+
+// ContentType returns the media type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv"
+	case FormatPDF:
+		return "application/pdf"
+	}
+
+	return "application/json"
+}
+
+// FileName returns the name of the file the statement is downloaded as.
+func FileName(statement *wallet.Statement, format Format) string {
+	return "statement-" + statement.WalletID + "-" + statement.From.Format(time.DateOnly) + "." + string(format)
+}
+
+// ParsePeriod parses the bounds of a statement period, each either a date (`YYYY-MM-DD`, UTC) or an RFC 3339
+// timestamp. A date as to includes the whole day.
+func ParsePeriod(from string, to string) (time.Time, time.Time, error) {
+	start, err := wallet.ParseBound(from, false)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+
+	end, err := wallet.ParseBound(to, true)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+
+	return start, end, nil
+}
+
+// Write renders the statement in format.
+func Write(w io.Writer, statement *wallet.Statement, format Format) error {
+	switch format {
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(statement)
+	case FormatCSV:
+		return writeCSV(w, statement)
+	case FormatPDF:
+		return writePDF(w, statement)
+	}
+
+	return ErrUnsupportedFormat
+}
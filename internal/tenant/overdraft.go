@@ -0,0 +1,67 @@
+package tenant
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Overdraft are the terms of the overdrafts of the wallets of the tenant, granted per wallet by a credit limit.
+type Overdraft struct {
+	// Rate is the annual interest rate in percent charged on the overdrawn balance at the end of every day.
+	Rate json.Number `json:"rate"`
+	// DayCount is the day-count convention of Rate, as for products.
+	DayCount string `json:"day_count"`
+	// DailyFee is charged for every day a wallet ends overdrawn, in whole cents.
+	DailyFee json.Number `json:"daily_fee"`
+	// AlertThresholds are the utilisations of the credit limit in percent, e.g. 80, alerted when a wallet
+	// crosses them upwards. Empty disables the alerts.
+	AlertThresholds []float64 `json:"alert_thresholds"`
+}
+
+// Charges tells whether overdrawn wallets are charged interest or fees.
+func (o *Overdraft) Charges() bool {
+	return positive(o.Rate) || positive(o.DailyFee)
+}
+
+func positive(value json.Number) bool {
+	decimal, ok := new(big.Rat).SetString(value.String())
+
+	return ok && decimal.Sign() > 0
+}
+
+// validate checks the terms, sets the default day count and sorts the thresholds.
+func (o *Overdraft) validate() error {
+	switch o.DayCount {
+	case "":
+		o.DayCount = DayCountActual365
+	case DayCountActual365, DayCountActual360, DayCountActualActual:
+	default:
+		return fmt.Errorf("overdraft: unknown day count %q", o.DayCount)
+	}
+
+	for name, value := range map[string]json.Number{"rate": o.Rate, "daily_fee": o.DailyFee} {
+		if value == "" {
+			continue
+		}
+
+		if decimal, ok := new(big.Rat).SetString(value.String()); !ok || decimal.Sign() < 0 {
+			return fmt.Errorf("overdraft: %s must be a decimal not negative", name)
+		}
+	}
+
+	if fee, ok := new(big.Rat).SetString(o.DailyFee.String()); ok && !fee.Mul(fee, big.NewRat(100, 1)).IsInt() {
+		return fmt.Errorf("overdraft: daily_fee must be whole cents")
+	}
+
+	for _, threshold := range o.AlertThresholds {
+		if threshold <= 0 {
+			return fmt.Errorf("overdraft: alert thresholds must be greater than zero")
+		}
+	}
+
+	sort.Float64s(o.AlertThresholds)
+
+	return nil
+}
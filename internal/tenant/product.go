@@ -0,0 +1,99 @@
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrUnknownProduct = errors.New("unknown wallet product")
+
+// Day-count conventions of the interest of a product, see Product.DayCount.
+const (
+	DayCountActual365    = "act/365"
+	DayCountActual360    = "act/360"
+	DayCountActualActual = "act/act"
+)
+
+// Capitalisation periods of the interest of a product, see Product.Capitalisation.
+const (
+	CapitaliseMonthly   = "monthly"
+	CapitaliseQuarterly = "quarterly"
+	CapitaliseYearly    = "yearly"
+)
+
+// Product is a kind of wallet the tenant offers, e.g. a savings wallet earning interest.
+type Product struct {
+	ID string `json:"id"`
+	// DayCount is the day-count convention dividing the annual rates into daily ones: act/365 (the default),
+	// act/360 or act/act, which divides by the number of days of the year of the accrual.
+	DayCount string `json:"day_count"`
+	// Capitalisation is how often the accrued interest is paid into the wallet: monthly (the default),
+	// quarterly or yearly.
+	Capitalisation string `json:"capitalisation"`
+	// Tiers are the annual rates by balance band, ordered by their From. A product without tiers earns nothing.
+	Tiers []InterestTier `json:"tiers"`
+}
+
+// InterestTier is the annual rate earned by the part of the balance from From up to the From of the next tier.
+type InterestTier struct {
+	From json.Number `json:"from"`
+	// Rate is the annual rate in percent, e.g. 1.25.
+	Rate json.Number `json:"rate"`
+}
+
+// Product returns the product of the tenant with the given id.
+func (t *Tenant) Product(id string) (*Product, error) {
+	for i := range t.Products {
+		if t.Products[i].ID == id {
+			return &t.Products[i], nil
+		}
+	}
+
+	return nil, ErrUnknownProduct
+}
+
+// validate checks the product and sets the defaults of its conventions.
+func (p *Product) validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("product without id")
+	}
+
+	switch p.DayCount {
+	case "":
+		p.DayCount = DayCountActual365
+	case DayCountActual365, DayCountActual360, DayCountActualActual:
+	default:
+		return fmt.Errorf("product %q: unknown day count %q", p.ID, p.DayCount)
+	}
+
+	switch p.Capitalisation {
+	case "":
+		p.Capitalisation = CapitaliseMonthly
+	case CapitaliseMonthly, CapitaliseQuarterly, CapitaliseYearly:
+	default:
+		return fmt.Errorf("product %q: unknown capitalisation %q", p.ID, p.Capitalisation)
+	}
+
+	previous := new(big.Rat).SetInt64(-1)
+
+	for i, tier := range p.Tiers {
+		from, ok := new(big.Rat).SetString(tier.From.String())
+		if tier.From == "" {
+			from, ok = new(big.Rat), true
+		}
+
+		if !ok || from.Sign() < 0 || from.Cmp(previous) <= 0 {
+			return fmt.Errorf("product %q: tier %d: from must be increasing and not negative", p.ID, i)
+		}
+
+		if rate, ok := new(big.Rat).SetString(tier.Rate.String()); !ok || rate.Sign() < 0 {
+			return fmt.Errorf("product %q: tier %d: rate must be a percentage not negative", p.ID, i)
+		}
+
+		previous = from
+	}
+
+	return nil
+}
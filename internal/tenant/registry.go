@@ -0,0 +1,104 @@
+package tenant
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type fileConfig struct {
+	Tenants []*Tenant `json:"tenants"`
+}
+
+// Registry holds the tenants known to the deployment.
+type Registry struct {
+	tenants map[string]*Tenant
+}
+
+func NewRegistry(tenants ...*Tenant) (*Registry, error) {
+	registry := &Registry{tenants: make(map[string]*Tenant, len(tenants))}
+
+	for _, t := range tenants {
+		if t.ID == "" {
+			return nil, fmt.Errorf("tenant without id")
+		}
+
+		if _, ok := registry.tenants[t.ID]; ok {
+			return nil, fmt.Errorf("duplicate tenant %q", t.ID)
+		}
+
+		if err := t.Overdraft.validate(); err != nil {
+			return nil, fmt.Errorf("tenant %q: %w", t.ID, err)
+		}
+
+		products := make(map[string]bool, len(t.Products))
+
+		for i := range t.Products {
+			if err := t.Products[i].validate(); err != nil {
+				return nil, fmt.Errorf("tenant %q: %w", t.ID, err)
+			}
+
+			if products[t.Products[i].ID] {
+				return nil, fmt.Errorf("tenant %q: duplicate product %q", t.ID, t.Products[i].ID)
+			}
+
+			products[t.Products[i].ID] = true
+		}
+
+		registry.tenants[t.ID] = t
+	}
+
+	return registry, nil
+}
+
+// LoadRegistry reads the tenants from a JSON file. When path is empty a registry containing
+// only the unrestricted defaultID tenant is returned, so single-brand deployments need no extra config.
+func LoadRegistry(path, defaultID string) (*Registry, error) {
+	if path == "" {
+		return NewRegistry(&Tenant{ID: defaultID})
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+
+	var cfg fileConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse tenants file: %w", err)
+	}
+
+	return NewRegistry(cfg.Tenants...)
+}
+
+func (r *Registry) ByID(id string) (*Tenant, error) {
+	t, ok := r.tenants[id]
+	if !ok {
+		return nil, ErrTenantNotFound
+	}
+
+	return t, nil
+}
+
+func (r *Registry) ByAPIKey(apiKey string) (*Tenant, error) {
+	for _, t := range r.tenants {
+		for _, key := range t.APIKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1 {
+				return t, nil
+			}
+		}
+	}
+
+	return nil, ErrTenantNotFound
+}
+
+// All returns every registered tenant.
+func (r *Registry) All() []*Tenant {
+	result := make([]*Tenant, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		result = append(result, t)
+	}
+
+	return result
+}
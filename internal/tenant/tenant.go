@@ -0,0 +1,68 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrTenantNotFound = errors.New("tenant not found")
+	ErrMissingTenant  = errors.New("tenant missing from context")
+)
+
+// Limits are the per-tenant monetary limits. A zero value disables the limit.
+type Limits struct {
+	MaxBalance    float64 `json:"max_balance"`
+	MaxDeposit    float64 `json:"max_deposit"`
+	MaxWithdrawal float64 `json:"max_withdrawal"`
+}
+
+type Tenant struct {
+	ID                string   `json:"id"`
+	APIKeys           []string `json:"api_keys"`
+	AllowedCurrencies []string `json:"allowed_currencies"`
+	Limits            Limits   `json:"limits"`
+	// Products are the kinds of wallets the tenant offers. Wallets without a product earn no interest.
+	Products []Product `json:"products"`
+	// Overdraft are the terms of the wallets with a credit limit.
+	Overdraft Overdraft `json:"overdraft"`
+}
+
+// AllowsCurrency reports whether wallets in the given currency may be opened for the tenant.
+// An empty AllowedCurrencies list allows every currency.
+func (t *Tenant) AllowsCurrency(currency string) bool {
+	if len(t.AllowedCurrencies) == 0 {
+		return true
+	}
+
+	for _, allowed := range t.AllowedCurrencies {
+		if strings.EqualFold(allowed, currency) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequiresAPIKey reports whether requests for the tenant must be authenticated with one of its API keys.
+func (t *Tenant) RequiresAPIKey() bool {
+	return len(t.APIKeys) > 0
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the tenant.
+func NewContext(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the tenant stored in ctx.
+func FromContext(ctx context.Context) (*Tenant, error) {
+	t, ok := ctx.Value(contextKey{}).(*Tenant)
+	if !ok || t == nil {
+		return nil, ErrMissingTenant
+	}
+
+	return t, nil
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const instrumentationName = "tribe-payments-wallet-golang-interview-assignment"
+
+var (
+	sqlStringLiteral  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumericLiteral = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+)
+
+// Tracer returns the tracer of the service from the global tracer provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// End records err on the span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// StartQuerySpan starts a client span named name for a SQL query. The query text is recorded sanitized.
+func StartQuerySpan(ctx context.Context, name, query string) (context.Context, trace.Span) {
+	sanitized := SanitizeSQL(query)
+	operation, _, _ := strings.Cut(sanitized, " ")
+
+	return Tracer().Start(
+		ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMSSQL,
+			semconv.DBOperationName(strings.ToUpper(operation)),
+			semconv.DBQueryText(sanitized),
+		),
+	)
+}
+
+// SanitizeSQL replaces the literals of query with `?` and collapses its whitespace,
+// so no values end up in the traces.
+func SanitizeSQL(query string) string {
+	query = sqlStringLiteral.ReplaceAllString(query, "?")
+	query = sqlNumericLiteral.ReplaceAllString(query, "?")
+
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// LogFields returns the trace and span ID of the span in ctx as log fields.
+func LogFields(ctx context.Context) []zap.Field {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return nil
+	}
+
+	return []zap.Field{
+		zap.String("trace_id", spanCtx.TraceID().String()),
+		zap.String("span_id", spanCtx.SpanID().String()),
+	}
+}
@@ -0,0 +1,40 @@
+package wallet
+
+// Observer is notified about the outcome of wallet operations, e.g. to record business metrics.
+type Observer interface {
+	Deposited(currency string, amount float64)
+	Withdrawn(currency string, amount float64)
+	Reversed(currency string, amount float64)
+	Transferred(currency string, amount float64)
+	InterestPaid(currency string, amount float64)
+	FeeCharged(currency string, amount float64)
+	EscrowHeld(currency string, amount float64)
+	EscrowReleased(currency string, amount float64)
+	EscrowRefunded(currency string, amount float64)
+	Adjusted(currency string, amount float64)
+	InsufficientFunds(currency string)
+}
+
+type nopObserver struct{}
+
+func (nopObserver) Deposited(string, float64) {}
+
+func (nopObserver) Withdrawn(string, float64) {}
+
+func (nopObserver) Reversed(string, float64) {}
+
+func (nopObserver) Transferred(string, float64) {}
+
+func (nopObserver) InterestPaid(string, float64) {}
+
+func (nopObserver) FeeCharged(string, float64) {}
+
+func (nopObserver) EscrowHeld(string, float64) {}
+
+func (nopObserver) EscrowReleased(string, float64) {}
+
+func (nopObserver) EscrowRefunded(string, float64) {}
+
+func (nopObserver) Adjusted(string, float64) {}
+
+func (nopObserver) InsufficientFunds(string) {}
@@ -0,0 +1,36 @@
+package wallet
+
+import (
+	"time"
+)
+
+// maxOverdraftAlerts is how many alerts Overdraft returns.
+const maxOverdraftAlerts = 100
+
+// OverdraftAlert records that the utilisation of the credit limit of a wallet crossed an alert threshold of its
+// tenant upwards.
+type OverdraftAlert struct {
+	ID       string `json:"id"`
+	WalletID string `json:"wallet_id"`
+	// Threshold and Utilisation are percentages of the credit limit.
+	Threshold   float64 `json:"threshold"`
+	Utilisation float64 `json:"utilisation"`
+	Balance     float64 `json:"balance"`
+	CreditLimit float64 `json:"credit_limit"`
+	// TransactionID is the movement crossing the threshold, empty when the credit limit was lowered.
+	TransactionID string    `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Overdraft is the use of the credit limit of a wallet.
+type Overdraft struct {
+	WalletID         string  `json:"wallet_id"`
+	Currency         string  `json:"currency"`
+	Balance          float64 `json:"balance"`
+	CreditLimit      float64 `json:"credit_limit"`
+	AvailableBalance float64 `json:"available_balance"`
+	// Utilisation is the part of the credit limit in use, in percent.
+	Utilisation float64 `json:"utilisation"`
+	// Alerts are the latest alerts of the wallet, newest first.
+	Alerts []*OverdraftAlert `json:"alerts"`
+}
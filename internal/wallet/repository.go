@@ -7,12 +7,46 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/database"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
 )
 
 type Repository interface {
-	Create(ctx context.Context, currency string) (*Wallet, error)
+	// WithinTx runs fn in a database transaction, see database.RunInTx.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// AfterCommit runs fn once the transaction of ctx commits, see database.AfterCommit.
+	AfterCommit(ctx context.Context, fn func())
+	Create(ctx context.Context, currency string, product string) (*Wallet, error)
 	Get(ctx context.Context, id string) (*Wallet, error)
-	UpdateBalance(ctx context.Context, id string, amount float64) error
+	// GetForUpdate returns the wallet locked until the end of the transaction of ctx, so the checks of a movement
+	// still hold when it is recorded.
+	GetForUpdate(ctx context.Context, id string) (*Wallet, error)
+	// AddToBalance adds amount, negative for debits, to the balance of the wallet and returns the updated wallet.
+	// Unless overdraw is set, it fails with ErrInsufficientFunds when a debit would take the balance below the
+	// credit limit of the wallet. The stored balance is never recomputed from the ledger, so a balance changed
+	// outside of the service keeps drifting until the reconciliation reports it. Like every update, it increments
+	// the version of the wallet and fails with ErrVersionMismatch when ctx expects another version,
+	// see NewVersionContext.
+	AddToBalance(ctx context.Context, id string, amount float64, overdraw bool) (*Wallet, error)
+	SetStatus(ctx context.Context, id string, status Status, reason string) (*Wallet, error)
+	SetCreditLimit(ctx context.Context, id string, creditLimit float64) (*Wallet, error)
+	CreateTransaction(ctx context.Context, transaction *Transaction) error
+	GetTransaction(ctx context.Context, id string) (*Transaction, error)
+	// ListTransactions returns the transactions of the wallet created in [from, to), in the order they were recorded.
+	ListTransactions(ctx context.Context, walletID string, from time.Time, to time.Time) ([]*Transaction, error)
+	// BalanceAt returns the balance of the wallet from its transactions created before at, starting from its
+	// latest balance snapshot of a day ended by then.
+	BalanceAt(ctx context.Context, walletID string, at time.Time) (float64, error)
+	// AddReversedAmount records that amount of the transaction was reversed. It returns ErrReversalExceedsOriginal
+	// when the reversals would exceed the original amount.
+	AddReversedAmount(ctx context.Context, id string, amount float64) error
+	CreateOverdraftAlert(ctx context.Context, alert *OverdraftAlert) error
+	// ListOverdraftAlerts returns at most limit overdraft alerts of the wallet, newest first.
+	ListOverdraftAlerts(ctx context.Context, walletID string, limit int) ([]*OverdraftAlert, error)
 }
 
 type repository struct {
@@ -23,32 +57,160 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
+func (r *repository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.RunInTx(ctx, r.db, fn)
+}
+
+func (r *repository) AfterCommit(ctx context.Context, fn func()) {
+	database.AfterCommit(ctx, fn)
+}
+
+const walletColumns = `id, tenant_id, balance, currency, status, status_reason, product, credit_limit, created_at,
+               updated_at, version`
+
+// walletOutputColumns are the walletColumns of an OUTPUT clause.
+const walletOutputColumns = `inserted.id, inserted.tenant_id, inserted.balance, inserted.currency,
+                     inserted.status, inserted.status_reason, inserted.product, inserted.credit_limit,
+                     inserted.created_at, inserted.updated_at, inserted.version`
+
+const transactionColumns = `id, tenant_id, wallet_id, type, direction, amount, currency, balance_after,
+                  reversal_of, reversed_amount, reason, reference, journal_id, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWallet(row rowScanner) (*Wallet, error) {
+	var (
+		wallet       Wallet
+		statusReason sql.NullString
+		product      sql.NullString
+	)
+
+	err := row.Scan(&wallet.ID, &wallet.TenantID, &wallet.Balance, &wallet.Currency, &wallet.Status,
+		&statusReason, &product, &wallet.CreditLimit, &wallet.CreatedAt, &wallet.UpdatedAt, &wallet.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.StatusReason = statusReason.String
+	wallet.Product = product.String
+
+	return &wallet, nil
+}
+
+func scanTransaction(row rowScanner) (*Transaction, error) {
+	var (
+		transaction Transaction
+		reversalOf  sql.NullString
+		reason      sql.NullString
+		reference   sql.NullString
+		journalID   sql.NullString
+	)
+
+	err := row.Scan(&transaction.ID, &transaction.TenantID, &transaction.WalletID, &transaction.Type,
+		&transaction.Direction, &transaction.Amount, &transaction.Currency, &transaction.BalanceAfter,
+		&reversalOf, &transaction.ReversedAmount, &reason, &reference, &journalID, &transaction.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.ReversalOf = reversalOf.String
+	transaction.Reason = reason.String
+	transaction.Reference = reference.String
+	transaction.JournalID = journalID.String
+
+	return &transaction, nil
+}
+
 func generateID() string {
 	return uuid.New().String()
 }
 
-func (r *repository) Create(ctx context.Context, currency string) (*Wallet, error) {
+// currentTenantID returns the tenant every query must be scoped to.
+func currentTenantID(ctx context.Context) (string, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return t.ID, nil
+}
+
+// versionParam is the `@version` parameter of the updates of a wallet, the version expected by ctx or NULL.
+func versionParam(ctx context.Context) sql.NamedArg {
+	version, ok := expectedVersion(ctx)
+
+	return sql.Named("version", sql.NullInt64{Int64: version, Valid: ok && version != AnyVersion})
+}
+
+// updateMissed tells why an update of the wallet matched no row: the wallet does not exist, or it is not at the
+// version expected by ctx.
+func (r *repository) updateMissed(ctx context.Context, id string) error {
+	if _, ok := expectedVersion(ctx); !ok {
+		return ErrWalletNotFound
+	}
+
+	if _, err := r.Get(ctx, id); err != nil {
+		return CheckFound(ctx, err)
+	}
+
+	return ErrVersionMismatch
+}
+
+// balanceMissed tells why an update of the balance of the wallet matched no row: the reasons of updateMissed, or
+// the debit would exceed the credit limit of the wallet.
+func (r *repository) balanceMissed(ctx context.Context, id string) error {
+	wallet, err := r.Get(ctx, id)
+	if err != nil {
+		return CheckFound(ctx, err)
+	}
+
+	if err := checkVersion(ctx, wallet); err != nil {
+		return err
+	}
+
+	return ErrInsufficientFunds
+}
+
+func (r *repository) Create(ctx context.Context, currency string, product string) (_ *Wallet, err error) {
 	if currency == "" {
 		return nil, errors.New("currency cannot be empty")
 	}
 
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	wallet := &Wallet{
 		ID:        generateID(), // Ensure a unique ID is generated
+		TenantID:  tenantID,
 		Currency:  currency,
+		Product:   product,
 		Balance:   0,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Status:    StatusActive,
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		Version:   1,
 	}
 
-	query := `INSERT INTO wallets (id, currency, balance, created_at, updated_at) 
-              VALUES (@id, @currency, @balance, @created_at, @updated_at)`
+	query := `INSERT INTO wallets (id, tenant_id, currency, product, balance, status, created_at, updated_at, version) 
+              VALUES (@id, @tenant_id, @currency, @product, @balance, @status, @created_at, @updated_at, @version)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/Create", query)
+	defer func() { tracing.End(span, err) }()
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
 		sql.Named("id", wallet.ID),
+		sql.Named("tenant_id", wallet.TenantID),
 		sql.Named("currency", wallet.Currency),
+		sql.Named("product", sql.NullString{String: wallet.Product, Valid: wallet.Product != ""}),
 		sql.Named("balance", wallet.Balance),
+		sql.Named("status", string(wallet.Status)),
 		sql.Named("created_at", wallet.CreatedAt),
 		sql.Named("updated_at", wallet.UpdatedAt),
+		sql.Named("version", wallet.Version),
 	)
 	if err != nil {
 		return nil, errors.New("failed to insert wallet into database: " + err.Error())
@@ -57,19 +219,40 @@ func (r *repository) Create(ctx context.Context, currency string) (*Wallet, erro
 	return wallet, nil
 }
 
-func (r *repository) Get(ctx context.Context, id string) (*Wallet, error) {
+func (r *repository) Get(ctx context.Context, id string) (_ *Wallet, err error) {
+	query := `SELECT ` + walletColumns + ` 
+              FROM wallets WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/Get", query)
+	defer func() { tracing.End(span, err) }()
+
+	return r.get(ctx, query, id)
+}
+
+func (r *repository) GetForUpdate(ctx context.Context, id string) (_ *Wallet, err error) {
+	query := `SELECT ` + walletColumns + ` 
+              FROM wallets WITH (UPDLOCK, ROWLOCK) WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/GetForUpdate", query)
+	defer func() { tracing.End(span, err) }()
+
+	return r.get(ctx, query, id)
+}
+
+func (r *repository) get(ctx context.Context, query string, id string) (*Wallet, error) {
 	if id == "" {
 		return nil, errors.New("wallet ID cannot be empty")
 	}
 
-	wallet := &Wallet{}
-	query := `SELECT id, balance, currency, created_at, updated_at 
-              FROM wallets WHERE id = @id`
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	err := r.db.QueryRowContext(ctx, query,
+	wallet, err := scanWallet(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
 		sql.Named("id", id),
-	).Scan(&wallet.ID, &wallet.Balance, &wallet.Currency,
-		&wallet.CreatedAt, &wallet.UpdatedAt)
+		sql.Named("tenant_id", tenantID),
+	))
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -81,35 +264,396 @@ func (r *repository) Get(ctx context.Context, id string) (*Wallet, error) {
 	return wallet, nil
 }
 
-func (r *repository) UpdateBalance(ctx context.Context, id string, amount float64) error {
+func (r *repository) AddToBalance(
+	ctx context.Context,
+	id string,
+	amount float64,
+	overdraw bool,
+) (_ *Wallet, err error) {
 	if id == "" {
-		return errors.New("wallet ID cannot be empty")
+		return nil, errors.New("wallet ID cannot be empty")
 	}
-	if amount == 0 {
-		return errors.New("amount must be non-zero")
+
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	query := `UPDATE wallets 
-              SET balance = balance + @amount, updated_at = @updated_at 
-              WHERE id = @id`
+              SET balance = balance + CAST(@amount AS DECIMAL(20,2)), updated_at = @updated_at,
+                  version = version + 1 
+              OUTPUT ` + walletOutputColumns + `
+              WHERE id = @id AND tenant_id = @tenant_id AND (@version IS NULL OR version = @version)
+                  AND (@overdraw = 1 OR @amount >= 0 OR balance + CAST(@amount AS DECIMAL(20,2)) >= -credit_limit)`
 
-	result, err := r.db.ExecContext(ctx, query,
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/AddToBalance", query)
+	defer func() { tracing.End(span, err) }()
+
+	wallet, err := scanWallet(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
 		sql.Named("amount", amount),
-		sql.Named("updated_at", time.Now()),
+		sql.Named("updated_at", time.Now().UTC()),
 		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+		sql.Named("overdraw", overdraw),
+		versionParam(ctx),
+	))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, r.balanceMissed(ctx, id)
+		}
+		return nil, errors.New("failed to update wallet balance: " + err.Error())
+	}
+
+	logging.FromContext(ctx, nil).Debug(
+		"Wallet balance updated",
+		zap.String("wallet_id", wallet.ID),
+		zap.Float64("balance", wallet.Balance),
 	)
+
+	return wallet, nil
+}
+
+func (r *repository) SetStatus(ctx context.Context, id string, status Status, reason string) (_ *Wallet, err error) {
+	tenantID, err := currentTenantID(ctx)
 	if err != nil {
-		return errors.New("failed to update wallet balance: " + err.Error())
+		return nil, err
 	}
 
-	rows, err := result.RowsAffected()
+	query := `UPDATE wallets 
+              SET status = @status, status_reason = @status_reason, updated_at = @updated_at, version = version + 1 
+              OUTPUT ` + walletOutputColumns + `
+              WHERE id = @id AND tenant_id = @tenant_id AND (@version IS NULL OR version = @version)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/SetStatus", query)
+	defer func() { tracing.End(span, err) }()
+
+	wallet, err := scanWallet(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("status", string(status)),
+		sql.Named("status_reason", sql.NullString{String: reason, Valid: reason != ""}),
+		sql.Named("updated_at", time.Now().UTC()),
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+		versionParam(ctx),
+	))
 	if err != nil {
-		return errors.New("failed to check affected rows: " + err.Error())
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, r.updateMissed(ctx, id)
+		}
+		return nil, errors.New("failed to update wallet status: " + err.Error())
 	}
 
-	if rows == 0 {
-		return ErrWalletNotFound
+	return wallet, nil
+}
+
+func (r *repository) SetCreditLimit(ctx context.Context, id string, creditLimit float64) (_ *Wallet, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE wallets 
+              SET credit_limit = @credit_limit, updated_at = @updated_at, version = version + 1 
+              OUTPUT ` + walletOutputColumns + `
+              WHERE id = @id AND tenant_id = @tenant_id AND (@version IS NULL OR version = @version)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/SetCreditLimit", query)
+	defer func() { tracing.End(span, err) }()
+
+	wallet, err := scanWallet(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("credit_limit", creditLimit),
+		sql.Named("updated_at", time.Now().UTC()),
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+		versionParam(ctx),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, r.updateMissed(ctx, id)
+		}
+		return nil, errors.New("failed to update wallet credit limit: " + err.Error())
+	}
+
+	return wallet, nil
+}
+
+func (r *repository) CreateTransaction(ctx context.Context, transaction *Transaction) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	transaction.ID = generateID()
+	transaction.TenantID = tenantID
+	transaction.CreatedAt = time.Now().UTC()
+
+	query := `INSERT INTO transactions (id, tenant_id, wallet_id, type, direction, amount, currency, balance_after,
+                  reversal_of, reversed_amount, reason, reference, journal_id, created_at)
+              VALUES (@id, @tenant_id, @wallet_id, @type, @direction, @amount, @currency, @balance_after,
+                  @reversal_of, 0, @reason, @reference, @journal_id, @created_at)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/CreateTransaction", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("id", transaction.ID),
+		sql.Named("tenant_id", transaction.TenantID),
+		sql.Named("wallet_id", transaction.WalletID),
+		sql.Named("type", string(transaction.Type)),
+		sql.Named("direction", string(transaction.Direction)),
+		sql.Named("amount", transaction.Amount),
+		sql.Named("currency", transaction.Currency),
+		sql.Named("balance_after", transaction.BalanceAfter),
+		sql.Named("reversal_of", sql.NullString{String: transaction.ReversalOf, Valid: transaction.ReversalOf != ""}),
+		sql.Named("reason", sql.NullString{String: transaction.Reason, Valid: transaction.Reason != ""}),
+		sql.Named("reference", sql.NullString{String: transaction.Reference, Valid: transaction.Reference != ""}),
+		sql.Named("journal_id", sql.NullString{String: transaction.JournalID, Valid: transaction.JournalID != ""}),
+		sql.Named("created_at", transaction.CreatedAt),
+	)
+	if err != nil {
+		return errors.New("failed to insert transaction into database: " + err.Error())
 	}
 
 	return nil
 }
+
+func (r *repository) GetTransaction(ctx context.Context, id string) (_ *Transaction, err error) {
+	if id == "" {
+		return nil, errors.New("transaction ID cannot be empty")
+	}
+
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + transactionColumns + `
+              FROM transactions WHERE id = @id AND tenant_id = @tenant_id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/GetTransaction", query)
+	defer func() { tracing.End(span, err) }()
+
+	transaction, err := scanTransaction(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+	))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, errors.New("failed to retrieve transaction: " + err.Error())
+	}
+
+	return transaction, nil
+}
+
+func (r *repository) ListTransactions(
+	ctx context.Context,
+	walletID string,
+	from time.Time,
+	to time.Time,
+) (_ []*Transaction, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + transactionColumns + `
+              FROM transactions
+              WHERE wallet_id = @wallet_id AND tenant_id = @tenant_id AND created_at >= @from AND created_at < @to
+              ORDER BY sequence`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/ListTransactions", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query,
+		sql.Named("wallet_id", walletID),
+		sql.Named("tenant_id", tenantID),
+		sql.Named("from", from),
+		sql.Named("to", to),
+	)
+	if err != nil {
+		return nil, errors.New("failed to list transactions: " + err.Error())
+	}
+	defer rows.Close()
+
+	transactions := make([]*Transaction, 0)
+
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, errors.New("failed to scan transaction: " + err.Error())
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list transactions: " + err.Error())
+	}
+
+	return transactions, nil
+}
+
+func (r *repository) BalanceAt(ctx context.Context, walletID string, at time.Time) (_ float64, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// NOTE: Starting from the latest snapshot ending before at only sums the transactions created since.
+	query := `SELECT COALESCE(s.balance, 0) + COALESCE((
+                  SELECT SUM(CASE t.direction WHEN 'credit' THEN t.amount ELSE -t.amount END)
+                  FROM transactions t
+                  WHERE t.wallet_id = @wallet_id AND t.tenant_id = @tenant_id AND t.created_at < @at
+                      AND (s.balance_date IS NULL
+                          OR t.created_at >= DATEADD(day, 1, CAST(s.balance_date AS DATETIME)))
+              ), 0)
+              FROM (SELECT 1 AS anchor) a
+              OUTER APPLY (
+                  SELECT TOP 1 balance, balance_date
+                  FROM balance_snapshots
+                  WHERE wallet_id = @wallet_id AND tenant_id = @tenant_id AND balance_date < CAST(@at AS DATE)
+                  ORDER BY balance_date DESC
+              ) s`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/BalanceAt", query)
+	defer func() { tracing.End(span, err) }()
+
+	var balance float64
+
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		sql.Named("wallet_id", walletID),
+		sql.Named("tenant_id", tenantID),
+		sql.Named("at", at),
+	).Scan(&balance)
+	if err != nil {
+		return 0, errors.New("failed to compute balance: " + err.Error())
+	}
+
+	return balance, nil
+}
+
+func (r *repository) AddReversedAmount(ctx context.Context, id string, amount float64) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE transactions
+              SET reversed_amount = reversed_amount + @amount
+              WHERE id = @id AND tenant_id = @tenant_id AND reversed_amount + @amount <= amount`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/AddReversedAmount", query)
+	defer func() { tracing.End(span, err) }()
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("amount", amount),
+		sql.Named("id", id),
+		sql.Named("tenant_id", tenantID),
+	)
+	if err != nil {
+		return errors.New("failed to update reversed amount: " + err.Error())
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.New("failed to update reversed amount: " + err.Error())
+	}
+
+	if affected == 0 {
+		return ErrReversalExceedsOriginal
+	}
+
+	return nil
+}
+
+func (r *repository) CreateOverdraftAlert(ctx context.Context, alert *OverdraftAlert) (err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	alert.ID = generateID()
+	alert.CreatedAt = time.Now().UTC()
+
+	query := `INSERT INTO overdraft_alerts (id, tenant_id, wallet_id, threshold, utilisation, balance, credit_limit,
+                  transaction_id, created_at)
+              VALUES (@id, @tenant_id, @wallet_id, @threshold, @utilisation, @balance, @credit_limit,
+                  @transaction_id, @created_at)`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/CreateOverdraftAlert", query)
+	defer func() { tracing.End(span, err) }()
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		sql.Named("id", alert.ID),
+		sql.Named("tenant_id", tenantID),
+		sql.Named("wallet_id", alert.WalletID),
+		sql.Named("threshold", alert.Threshold),
+		sql.Named("utilisation", alert.Utilisation),
+		sql.Named("balance", alert.Balance),
+		sql.Named("credit_limit", alert.CreditLimit),
+		sql.Named("transaction_id", sql.NullString{String: alert.TransactionID, Valid: alert.TransactionID != ""}),
+		sql.Named("created_at", alert.CreatedAt),
+	)
+	if err != nil {
+		return errors.New("failed to insert overdraft alert: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *repository) ListOverdraftAlerts(
+	ctx context.Context,
+	walletID string,
+	limit int,
+) (_ []*OverdraftAlert, err error) {
+	tenantID, err := currentTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT TOP (@limit) id, wallet_id, threshold, utilisation, balance, credit_limit, transaction_id,
+                  created_at
+              FROM overdraft_alerts
+              WHERE wallet_id = @wallet_id AND tenant_id = @tenant_id
+              ORDER BY created_at DESC, id`
+
+	ctx, span := tracing.StartQuerySpan(ctx, "wallet.Repository/ListOverdraftAlerts", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query,
+		sql.Named("limit", limit),
+		sql.Named("wallet_id", walletID),
+		sql.Named("tenant_id", tenantID),
+	)
+	if err != nil {
+		return nil, errors.New("failed to list overdraft alerts: " + err.Error())
+	}
+	defer rows.Close()
+
+	alerts := []*OverdraftAlert{}
+
+	for rows.Next() {
+		var (
+			alert         OverdraftAlert
+			transactionID sql.NullString
+		)
+
+		err := rows.Scan(&alert.ID, &alert.WalletID, &alert.Threshold, &alert.Utilisation, &alert.Balance,
+			&alert.CreditLimit, &transactionID, &alert.CreatedAt)
+		if err != nil {
+			return nil, errors.New("failed to scan overdraft alert: " + err.Error())
+		}
+
+		alert.TransactionID = transactionID.String
+		alerts = append(alerts, &alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("failed to list overdraft alerts: " + err.Error())
+	}
+
+	return alerts, nil
+}
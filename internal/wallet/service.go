@@ -3,57 +3,1040 @@ package wallet
 import (
 	"context"
 	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/audit"
+	"tribe-payments-wallet-golang-interview-assignment/internal/ledger"
+	"tribe-payments-wallet-golang-interview-assignment/internal/logging"
+	"tribe-payments-wallet-golang-interview-assignment/internal/tenant"
 )
 
 var (
-	ErrInsufficientFunds = errors.New("insufficient funds")
-	ErrWalletNotFound    = errors.New("wallet not found")
-	ErrInvalidAmount     = errors.New("invalid amount")
+	ErrInsufficientFunds  = errors.New("insufficient funds")
+	ErrWalletNotFound     = errors.New("wallet not found")
+	ErrInvalidAmount      = errors.New("invalid amount")
+	ErrCurrencyNotAllowed = errors.New("currency not allowed")
+	ErrLimitExceeded      = errors.New("limit exceeded")
+	ErrWalletFrozen       = errors.New("wallet is frozen")
+	ErrInvalidPeriod      = errors.New("invalid period")
+	ErrFutureAsOf         = errors.New("as of is in the future")
+	ErrVersionMismatch    = errors.New("wallet version mismatch")
+	ErrSameWallet         = errors.New("cannot transfer to the same wallet")
+	ErrCurrencyMismatch   = errors.New("wallets have different currencies")
+	ErrInvalidCreditLimit = errors.New("invalid credit limit")
+
+	ErrTransactionNotFound     = errors.New("transaction not found")
+	ErrNotReversible           = errors.New("transaction cannot be reversed")
+	ErrAlreadyReversed         = errors.New("transaction already reversed")
+	ErrReversalExceedsOriginal = errors.New("reversal exceeds the original amount")
 )
 
 type Service interface {
-	CreateWallet(ctx context.Context, currency string) (*Wallet, error)
+	// CreateWallet opens a wallet, of the given product of the tenant when product is not empty.
+	CreateWallet(ctx context.Context, currency string, product string) (*Wallet, error)
 	GetWallet(ctx context.Context, id string) (*Wallet, error)
-	Deposit(ctx context.Context, id string, amount float64) error
-	Withdraw(ctx context.Context, id string, amount float64) error
+	// GetWalletAsOf returns the wallet with its balance from the transactions created before asOf.
+	// It returns ErrWalletNotFound when the wallet did not exist yet.
+	GetWalletAsOf(ctx context.Context, id string, asOf time.Time) (*Wallet, error)
+	// Deposit credits amount to the wallet. The optional reference is the payment reference of the deposit.
+	Deposit(ctx context.Context, id string, amount float64, reference string) (*Transaction, error)
+	Withdraw(ctx context.Context, id string, amount float64) (*Transaction, error)
+	GetTransaction(ctx context.Context, id string) (*Transaction, error)
+	Reverse(ctx context.Context, transactionID string, amount float64, reason string) (*Transaction, error)
+	// Transfer moves amount between two wallets of the same currency and returns the debit of the source wallet.
+	// The optional reference is recorded on both sides.
+	Transfer(ctx context.Context, fromID string, toID string, amount float64, reference string) (*Transaction, error)
+	// PayInterest credits the interest accrued by the wallet. The limits of the tenant do not apply.
+	PayInterest(ctx context.Context, id string, amount float64, reason string) (*Transaction, error)
+	// ChargeFee debits a fee from the wallet, even beyond its credit limit.
+	ChargeFee(ctx context.Context, id string, amount float64, reason string) (*Transaction, error)
+	// HoldEscrow debits amount from the wallet into the escrow account of the tenant, within its available balance.
+	HoldEscrow(ctx context.Context, id string, amount float64, reason string) (*Transaction, error)
+	// ReleaseEscrow credits amount held in escrow to the payee wallet. The limits of the tenant do not apply.
+	ReleaseEscrow(ctx context.Context, id string, amount float64, reason string) (*Transaction, error)
+	// RefundEscrow credits amount held in escrow back to the payer wallet. The limits of the tenant do not apply.
+	RefundEscrow(ctx context.Context, id string, amount float64, reason string) (*Transaction, error)
+	// Adjust books a balance correction approved by operators. The limits of the tenant do not apply, debits stay
+	// within the available balance.
+	Adjust(ctx context.Context, id string, direction Direction, amount float64, reason string) (*Transaction, error)
+	// Statement returns the movements of the wallet in [from, to), at most MaxStatementPeriod apart.
+	Statement(ctx context.Context, id string, from time.Time, to time.Time) (*Statement, error)
+	// Freeze makes the wallet reject every movement until it is unfrozen.
+	Freeze(ctx context.Context, id string, reason string) (*Wallet, error)
+	Unfreeze(ctx context.Context, id string) (*Wallet, error)
+	// SetCreditLimit sets how far below zero the balance of the wallet may go. Lowering it below the overdrawn
+	// balance only rejects further debits.
+	SetCreditLimit(ctx context.Context, id string, creditLimit float64) (*Wallet, error)
+	// Overdraft returns the use of the credit limit of the wallet with its latest alerts.
+	Overdraft(ctx context.Context, id string) (*Overdraft, error)
 }
 
+// ResourceType is the audit log resource type of wallets.
+const ResourceType = "wallet"
+
+// Audit log actions of wallets. Movements are audited as `wallet.<transaction type>`, e.g. `wallet.deposit`.
+const (
+	AuditActionCreated  = "wallet.created"
+	AuditActionFrozen   = "wallet.frozen"
+	AuditActionUnfrozen = "wallet.unfrozen"
+	// AuditActionCreditLimitSet is the change of the credit limit of a wallet.
+	AuditActionCreditLimitSet = "wallet.credit_limit_set"
+)
+
 type service struct {
-	repo Repository
+	repo     Repository
+	ledger   ledger.Ledger
+	observer Observer
+	auditLog audit.Recorder
+}
+
+type serviceOption func(*service)
+
+// WithObserver sets the Observer notified about completed operations.
+func WithObserver(observer Observer) serviceOption {
+	return func(s *service) {
+		s.observer = observer
+	}
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+// WithAuditLog sets the audit log recording every wallet mutation in the same database transaction.
+func WithAuditLog(auditLog audit.Recorder) serviceOption {
+	return func(s *service) {
+		s.auditLog = auditLog
+	}
+}
+
+// NewService creates the wallet service. Every movement is recorded as a balanced journal of the ledger and
+// applied to the stored wallet balance.
+func NewService(repo Repository, ledgerRepo ledger.Ledger, options ...serviceOption) Service {
+	s := &service{
+		repo:     repo,
+		ledger:   ledgerRepo,
+		observer: nopObserver{},
+		auditLog: audit.NopRecorder{},
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s
 }
 
-func (s *service) CreateWallet(ctx context.Context, currency string) (*Wallet, error) {
-	return s.repo.Create(ctx, currency)
+func (s *service) CreateWallet(ctx context.Context, currency string, product string) (*Wallet, error) {
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.AllowsCurrency(currency) {
+		return nil, ErrCurrencyNotAllowed
+	}
+
+	if product != "" {
+		if _, err := t.Product(product); err != nil {
+			return nil, err
+		}
+	}
+
+	var wallet *Wallet
+
+	err = s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		wallet, err = s.repo.Create(ctx, currency, product)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.ledger.WalletAccount(ctx, wallet.ID, wallet.Currency); err != nil {
+			return err
+		}
+
+		return s.auditLog.Record(ctx, AuditActionCreated, ResourceType, wallet.ID, nil, wallet)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		logging.FromContext(ctx, nil).Info(
+			"Wallet created",
+			zap.String("wallet_id", wallet.ID),
+			zap.String("currency", wallet.Currency),
+			zap.String("product", wallet.Product),
+		)
+	})
+
+	return wallet, nil
 }
 
 func (s *service) GetWallet(ctx context.Context, id string) (*Wallet, error) {
 	return s.repo.Get(ctx, id)
 }
 
-func (s *service) Deposit(ctx context.Context, id string, amount float64) error {
-	if amount <= 0 {
-		return ErrInvalidAmount
+func (s *service) GetWalletAsOf(ctx context.Context, id string, asOf time.Time) (*Wallet, error) {
+	if asOf.After(time.Now()) {
+		return nil, ErrFutureAsOf
+	}
+
+	wallet, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !wallet.CreatedAt.Before(asOf) {
+		return nil, ErrWalletNotFound
+	}
+
+	wallet.Balance, err = s.repo.BalanceAt(ctx, wallet.ID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+func (s *service) GetTransaction(ctx context.Context, id string) (*Transaction, error) {
+	return s.repo.GetTransaction(ctx, id)
+}
+
+func (s *service) Deposit(ctx context.Context, id string, amount float64, reference string) (*Transaction, error) {
+	if amount <= 0 || !WholeCents(amount) {
+		return nil, ErrInvalidAmount
+	}
+
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Limits.MaxDeposit > 0 && amount > t.Limits.MaxDeposit {
+		return nil, ErrLimitExceeded
+	}
+
+	transaction := &Transaction{
+		WalletID:  id,
+		Type:      TransactionDeposit,
+		Direction: DirectionCredit,
+		Amount:    amount,
+		Reference: reference,
+	}
+
+	err = s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		return s.move(ctx, transaction, ledger.AccountCashInClearing)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		s.observer.Deposited(transaction.Currency, amount)
+
+		logging.FromContext(ctx, nil).Info(
+			"Deposit completed",
+			zap.String("wallet_id", id),
+			zap.String("transaction_id", transaction.ID),
+			zap.Float64("amount", amount),
+			zap.String("currency", transaction.Currency),
+		)
+	})
+
+	return transaction, nil
+}
+
+func (s *service) PayInterest(ctx context.Context, id string, amount float64, reason string) (*Transaction, error) {
+	if amount <= 0 || !WholeCents(amount) {
+		return nil, ErrInvalidAmount
+	}
+
+	transaction := &Transaction{
+		WalletID:  id,
+		Type:      TransactionInterest,
+		Direction: DirectionCredit,
+		Amount:    amount,
+		Reason:    reason,
+	}
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		return s.move(ctx, transaction, ledger.AccountInterestExpense)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		s.observer.InterestPaid(transaction.Currency, amount)
+
+		logging.FromContext(ctx, nil).Info(
+			"Interest paid",
+			zap.String("wallet_id", id),
+			zap.String("transaction_id", transaction.ID),
+			zap.Float64("amount", amount),
+			zap.String("currency", transaction.Currency),
+		)
+	})
+
+	return transaction, nil
+}
+
+func (s *service) ChargeFee(ctx context.Context, id string, amount float64, reason string) (*Transaction, error) {
+	if amount <= 0 || !WholeCents(amount) {
+		return nil, ErrInvalidAmount
+	}
+
+	transaction := &Transaction{
+		WalletID:  id,
+		Type:      TransactionFee,
+		Direction: DirectionDebit,
+		Amount:    amount,
+		Reason:    reason,
+	}
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		return s.move(ctx, transaction, ledger.AccountFees)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		s.observer.FeeCharged(transaction.Currency, amount)
+
+		logging.FromContext(ctx, nil).Info(
+			"Fee charged",
+			zap.String("wallet_id", id),
+			zap.String("transaction_id", transaction.ID),
+			zap.Float64("amount", amount),
+			zap.String("currency", transaction.Currency),
+		)
+	})
+
+	return transaction, nil
+}
+
+func (s *service) HoldEscrow(ctx context.Context, id string, amount float64, reason string) (*Transaction, error) {
+	if amount <= 0 || !WholeCents(amount) {
+		return nil, ErrInvalidAmount
+	}
+
+	transaction := &Transaction{
+		WalletID:  id,
+		Type:      TransactionEscrowHold,
+		Direction: DirectionDebit,
+		Amount:    amount,
+		Reason:    reason,
+	}
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		return s.move(ctx, transaction, ledger.AccountEscrow)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		s.observer.EscrowHeld(transaction.Currency, amount)
+
+		logging.FromContext(ctx, nil).Info(
+			"Escrow held",
+			zap.String("wallet_id", id),
+			zap.String("transaction_id", transaction.ID),
+			zap.Float64("amount", amount),
+			zap.String("currency", transaction.Currency),
+		)
+	})
+
+	return transaction, nil
+}
+
+func (s *service) ReleaseEscrow(ctx context.Context, id string, amount float64, reason string) (*Transaction, error) {
+	transaction, err := s.payOutEscrow(ctx, TransactionEscrowRelease, id, amount, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		s.observer.EscrowReleased(transaction.Currency, amount)
+	})
+
+	return transaction, nil
+}
+
+func (s *service) RefundEscrow(ctx context.Context, id string, amount float64, reason string) (*Transaction, error) {
+	transaction, err := s.payOutEscrow(ctx, TransactionEscrowRefund, id, amount, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		s.observer.EscrowRefunded(transaction.Currency, amount)
+	})
+
+	return transaction, nil
+}
+
+// payOutEscrow credits amount from the escrow account of the tenant to the wallet.
+func (s *service) payOutEscrow(
+	ctx context.Context,
+	transactionType TransactionType,
+	id string,
+	amount float64,
+	reason string,
+) (*Transaction, error) {
+	if amount <= 0 || !WholeCents(amount) {
+		return nil, ErrInvalidAmount
+	}
+
+	transaction := &Transaction{
+		WalletID:  id,
+		Type:      transactionType,
+		Direction: DirectionCredit,
+		Amount:    amount,
+		Reason:    reason,
+	}
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		return s.move(ctx, transaction, ledger.AccountEscrow)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		logging.FromContext(ctx, nil).Info(
+			"Escrow paid out",
+			zap.String("wallet_id", id),
+			zap.String("transaction_id", transaction.ID),
+			zap.String("type", string(transactionType)),
+			zap.Float64("amount", amount),
+			zap.String("currency", transaction.Currency),
+		)
+	})
+
+	return transaction, nil
+}
+
+func (s *service) Adjust(
+	ctx context.Context,
+	id string,
+	direction Direction,
+	amount float64,
+	reason string,
+) (*Transaction, error) {
+	if amount <= 0 || !WholeCents(amount) {
+		return nil, ErrInvalidAmount
+	}
+
+	transaction := &Transaction{
+		WalletID:  id,
+		Type:      TransactionAdjustment,
+		Direction: direction,
+		Amount:    amount,
+		Reason:    reason,
+	}
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		return s.move(ctx, transaction, ledger.AccountSuspense)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		s.observer.Adjusted(transaction.Currency, amount)
+
+		logging.FromContext(ctx, nil).Info(
+			"Adjustment booked",
+			zap.String("wallet_id", id),
+			zap.String("transaction_id", transaction.ID),
+			zap.String("direction", string(direction)),
+			zap.Float64("amount", amount),
+			zap.String("currency", transaction.Currency),
+		)
+	})
+
+	return transaction, nil
+}
+
+func (s *service) Withdraw(ctx context.Context, id string, amount float64) (*Transaction, error) {
+	if amount <= 0 || !WholeCents(amount) {
+		return nil, ErrInvalidAmount
+	}
+
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Limits.MaxWithdrawal > 0 && amount > t.Limits.MaxWithdrawal {
+		return nil, ErrLimitExceeded
+	}
+
+	transaction := &Transaction{
+		WalletID:  id,
+		Type:      TransactionWithdrawal,
+		Direction: DirectionDebit,
+		Amount:    amount,
+	}
+
+	err = s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		return s.move(ctx, transaction, ledger.AccountCashOutClearing)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		s.observer.Withdrawn(transaction.Currency, amount)
+
+		logging.FromContext(ctx, nil).Info(
+			"Withdrawal completed",
+			zap.String("wallet_id", id),
+			zap.String("transaction_id", transaction.ID),
+			zap.Float64("amount", amount),
+			zap.String("currency", transaction.Currency),
+		)
+	})
+
+	return transaction, nil
+}
+
+// Reverse compensates amount of a deposit or withdrawal with a reversal transaction moving the money back.
+// A zero amount reverses everything not reversed yet. The reversal is subject to the same balance rules
+// as a withdrawal or deposit of the amount.
+func (s *service) Reverse(ctx context.Context, transactionID string, amount float64, reason string) (*Transaction, error) {
+	if amount < 0 || !WholeCents(amount) {
+		return nil, ErrInvalidAmount
+	}
+
+	var reversal *Transaction
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		original, err := s.repo.GetTransaction(ctx, transactionID)
+		if err != nil {
+			return err
+		}
+
+		if !original.Reversible() {
+			return ErrNotReversible
+		}
+
+		remaining := original.ReversibleAmount()
+		if remaining <= 0 {
+			return ErrAlreadyReversed
+		}
+
+		reversedAmount := amount
+		if reversedAmount == 0 {
+			reversedAmount = remaining
+		}
+
+		// Guards against concurrent reversals of the same transaction by locking its row.
+		if err := s.repo.AddReversedAmount(ctx, original.ID, reversedAmount); err != nil {
+			return err
+		}
+
+		reversal = &Transaction{
+			WalletID:   original.WalletID,
+			Type:       TransactionReversal,
+			Direction:  DirectionCredit,
+			Amount:     reversedAmount,
+			ReversalOf: original.ID,
+			Reason:     reason,
+		}
+
+		if original.Direction == DirectionCredit {
+			reversal.Direction = DirectionDebit
+		}
+
+		return s.move(ctx, reversal, counterAccount(original.Type))
+	})
+	if err != nil {
+		return nil, err
 	}
-	return s.repo.UpdateBalance(ctx, id, amount)
+
+	s.repo.AfterCommit(ctx, func() {
+		s.observer.Reversed(reversal.Currency, reversal.Amount)
+
+		logging.FromContext(ctx, nil).Info(
+			"Transaction reversed",
+			zap.String("wallet_id", reversal.WalletID),
+			zap.String("transaction_id", reversal.ID),
+			zap.String("reversal_of", reversal.ReversalOf),
+			zap.Float64("amount", reversal.Amount),
+			zap.String("currency", reversal.Currency),
+		)
+	})
+
+	return reversal, nil
 }
 
-func (s *service) Withdraw(ctx context.Context, id string, amount float64) error {
-	if amount <= 0 {
-		return ErrInvalidAmount
+// Transfer posts both sides of the transfer in one journal, so the money never leaves the wallets of the tenant.
+// The transfer is subject to the balance rules of a withdrawal from the source and a deposit to the destination,
+// not to the deposit and withdrawal limits meant for money entering or leaving the tenant.
+func (s *service) Transfer(
+	ctx context.Context,
+	fromID string,
+	toID string,
+	amount float64,
+	reference string,
+) (*Transaction, error) {
+	if amount <= 0 || !WholeCents(amount) {
+		return nil, ErrInvalidAmount
+	}
+
+	if fromID == toID {
+		return nil, ErrSameWallet
 	}
 
+	debit := &Transaction{
+		WalletID:  fromID,
+		Type:      TransactionTransferOut,
+		Direction: DirectionDebit,
+		Amount:    amount,
+		Reference: reference,
+	}
+
+	credit := &Transaction{
+		WalletID:  toID,
+		Type:      TransactionTransferIn,
+		Direction: DirectionCredit,
+		Amount:    amount,
+		Reference: reference,
+	}
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		from, to, err := s.lockPair(ctx, fromID, toID)
+		if err != nil {
+			return err
+		}
+
+		if from.Currency != to.Currency {
+			return ErrCurrencyMismatch
+		}
+
+		if from.Status == StatusFrozen || to.Status == StatusFrozen {
+			return ErrWalletFrozen
+		}
+
+		if err := s.checkFunds(ctx, from, debit); err != nil {
+			return err
+		}
+
+		if err := checkMaxBalance(ctx, to, credit); err != nil {
+			return err
+		}
+
+		fromAccount, err := s.ledger.WalletAccount(ctx, from.ID, from.Currency)
+		if err != nil {
+			return err
+		}
+
+		toAccount, err := s.ledger.WalletAccount(ctx, to.ID, to.Currency)
+		if err != nil {
+			return err
+		}
+
+		journal := &ledger.Journal{
+			Currency:    from.Currency,
+			Description: "transfer",
+			Postings: []ledger.Posting{
+				{AccountID: fromAccount.ID, Amount: debit.SignedAmount()},
+				{AccountID: toAccount.ID, Amount: credit.SignedAmount()},
+			},
+		}
+
+		if err := s.ledger.Post(ctx, journal); err != nil {
+			return err
+		}
+
+		if err := s.record(ctx, from, debit, journal.ID); err != nil {
+			return err
+		}
+
+		return s.record(ctx, to, credit, journal.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		s.observer.Transferred(debit.Currency, amount)
+
+		logging.FromContext(ctx, nil).Info(
+			"Transfer completed",
+			zap.String("wallet_id", fromID),
+			zap.String("to_wallet_id", toID),
+			zap.String("transaction_id", debit.ID),
+			zap.Float64("amount", amount),
+			zap.String("currency", debit.Currency),
+		)
+	})
+
+	return debit, nil
+}
+
+func (s *service) Statement(ctx context.Context, id string, from time.Time, to time.Time) (*Statement, error) {
+	if !from.Before(to) || to.Sub(from) > MaxStatementPeriod {
+		return nil, ErrInvalidPeriod
+	}
+
+	wallet, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.repo.ListTransactions(ctx, wallet.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: The reads do not share a snapshot, so the opening balance is taken from the balance recorded with the
+	// first movement listed rather than read apart, which could already include movements recorded in between.
+	if len(transactions) > 0 {
+		first := transactions[0]
+
+		return newStatement(wallet, from, to, first.BalanceAfter-first.SignedAmount(), transactions), nil
+	}
+
+	openingBalance, err := s.repo.BalanceAt(ctx, wallet.ID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return newStatement(wallet, from, to, openingBalance, nil), nil
+}
+
+func (s *service) Freeze(ctx context.Context, id string, reason string) (*Wallet, error) {
+	wallet, err := s.setStatus(ctx, id, StatusFrozen, reason, AuditActionFrozen)
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		logging.FromContext(ctx, nil).Warn(
+			"Wallet frozen",
+			zap.String("wallet_id", id),
+			zap.String("reason", reason),
+		)
+	})
+
+	return wallet, nil
+}
+
+func (s *service) Unfreeze(ctx context.Context, id string) (*Wallet, error) {
+	wallet, err := s.setStatus(ctx, id, StatusActive, "", AuditActionUnfrozen)
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		logging.FromContext(ctx, nil).Info(
+			"Wallet unfrozen",
+			zap.String("wallet_id", id),
+		)
+	})
+
+	return wallet, nil
+}
+
+func (s *service) SetCreditLimit(ctx context.Context, id string, creditLimit float64) (*Wallet, error) {
+	if creditLimit < 0 || !WholeCents(creditLimit) {
+		return nil, ErrInvalidCreditLimit
+	}
+
+	var wallet *Wallet
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.lock(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		wallet, err = s.repo.SetCreditLimit(ctx, id, creditLimit)
+		if err != nil {
+			return err
+		}
+
+		if err := s.auditLog.Record(ctx, AuditActionCreditLimitSet, ResourceType, wallet.ID, before, wallet); err != nil {
+			return err
+		}
+
+		return s.alert(ctx, before, wallet, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.AfterCommit(ctx, func() {
+		logging.FromContext(ctx, nil).Info(
+			"Wallet credit limit set",
+			zap.String("wallet_id", id),
+			zap.Float64("credit_limit", creditLimit),
+		)
+	})
+
+	return wallet, nil
+}
+
+func (s *service) Overdraft(ctx context.Context, id string) (*Overdraft, error) {
 	wallet, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := s.repo.ListOverdraftAlerts(ctx, wallet.ID, maxOverdraftAlerts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Overdraft{
+		WalletID:         wallet.ID,
+		Currency:         wallet.Currency,
+		Balance:          wallet.Balance,
+		CreditLimit:      wallet.CreditLimit,
+		AvailableBalance: wallet.AvailableBalance(),
+		Utilisation:      wallet.Utilisation(),
+		Alerts:           alerts,
+	}, nil
+}
+
+// alert records an overdraft alert for every alert threshold of the tenant the utilisation of the credit limit of
+// the wallet crossed upwards since before.
+func (s *service) alert(ctx context.Context, before *Wallet, after *Wallet, transactionID string) error {
+	t, err := tenant.FromContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	if wallet.Balance < amount {
-		return ErrInsufficientFunds
+	for _, threshold := range t.Overdraft.AlertThresholds {
+		if before.Utilisation() >= threshold || after.Utilisation() < threshold {
+			continue
+		}
+
+		alert := &OverdraftAlert{
+			WalletID:      after.ID,
+			Threshold:     threshold,
+			Utilisation:   after.Utilisation(),
+			Balance:       after.Balance,
+			CreditLimit:   after.CreditLimit,
+			TransactionID: transactionID,
+		}
+
+		if err := s.repo.CreateOverdraftAlert(ctx, alert); err != nil {
+			return err
+		}
+
+		s.repo.AfterCommit(ctx, func() {
+			logging.FromContext(ctx, nil).Warn(
+				"Overdraft alert threshold crossed",
+				zap.String("wallet_id", alert.WalletID),
+				zap.Float64("threshold", alert.Threshold),
+				zap.Float64("utilisation", alert.Utilisation),
+			)
+		})
 	}
 
-	return s.repo.UpdateBalance(ctx, id, -amount)
+	return nil
+}
+
+func (s *service) setStatus(
+	ctx context.Context,
+	id string,
+	status Status,
+	reason string,
+	action string,
+) (*Wallet, error) {
+	var wallet *Wallet
+
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.lock(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		wallet, err = s.repo.SetStatus(ctx, id, status, reason)
+		if err != nil {
+			return err
+		}
+
+		return s.auditLog.Record(ctx, action, ResourceType, wallet.ID, before, wallet)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// move posts the transaction to the ledger against the counter account, applies it to the wallet balance,
+// records the transaction and audits the change. It must run within a database transaction,
+// so the balance never changes without the movement being recorded.
+func (s *service) move(ctx context.Context, transaction *Transaction, counterAccountType ledger.AccountType) error {
+	before, err := s.lock(ctx, transaction.WalletID)
+	if err != nil {
+		return err
+	}
+
+	if before.Status == StatusFrozen {
+		return ErrWalletFrozen
+	}
+
+	if err := s.checkFunds(ctx, before, transaction); err != nil {
+		return err
+	}
+
+	if err := checkMaxBalance(ctx, before, transaction); err != nil {
+		return err
+	}
+
+	walletAccount, err := s.ledger.WalletAccount(ctx, before.ID, before.Currency)
+	if err != nil {
+		return err
+	}
+
+	counterAccount, err := s.ledger.SystemAccount(ctx, counterAccountType, before.Currency)
+	if err != nil {
+		return err
+	}
+
+	journal := &ledger.Journal{
+		Currency:    before.Currency,
+		Description: string(transaction.Type),
+		Postings: []ledger.Posting{
+			{AccountID: walletAccount.ID, Amount: transaction.SignedAmount()},
+			{AccountID: counterAccount.ID, Amount: -transaction.SignedAmount()},
+		},
+	}
+
+	if err := s.ledger.Post(ctx, journal); err != nil {
+		return err
+	}
+
+	return s.record(ctx, before, transaction, journal.ID)
+}
+
+// lock returns the wallet locked until the end of the transaction of ctx, so concurrent movements are checked
+// against its balance one after the other. It fails with ErrVersionMismatch when ctx expects another version, or
+// any version of a wallet that does not exist.
+func (s *service) lock(ctx context.Context, id string) (*Wallet, error) {
+	wallet, err := s.repo.GetForUpdate(ctx, id)
+	if err != nil {
+		return nil, CheckFound(ctx, err)
+	}
+
+	if err := checkVersion(ctx, wallet); err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// lockPair locks both wallets of a transfer, see lock.
+func (s *service) lockPair(ctx context.Context, fromID string, toID string) (*Wallet, *Wallet, error) {
+	// NOTE: The wallets are locked in ID order, so opposite transfers between them cannot deadlock.
+	if toID < fromID {
+		to, from, err := s.lockPair(ctx, toID, fromID)
+
+		return from, to, err
+	}
+
+	from, err := s.lock(ctx, fromID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	to, err := s.lock(ctx, toID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return from, to, nil
+}
+
+// checkFunds rejects a debit beyond the available balance of the locked wallet, unless the transaction may
+// overdraw it.
+func (s *service) checkFunds(ctx context.Context, wallet *Wallet, transaction *Transaction) error {
+	if transaction.Direction != DirectionDebit || transaction.overdraws() ||
+		wallet.AvailableBalance() >= transaction.Amount {
+		return nil
+	}
+
+	s.observer.InsufficientFunds(wallet.Currency)
+
+	logging.FromContext(ctx, nil).Info(
+		"Debit rejected for insufficient funds",
+		zap.String("wallet_id", wallet.ID),
+		zap.String("type", string(transaction.Type)),
+		zap.Float64("amount", transaction.Amount),
+	)
+
+	return ErrInsufficientFunds
+}
+
+// checkMaxBalance rejects a credit taking the balance of the locked wallet beyond the maximum balance of the
+// tenant, unless the limits of the tenant do not apply to the transaction.
+func checkMaxBalance(ctx context.Context, wallet *Wallet, transaction *Transaction) error {
+	if transaction.Direction != DirectionCredit || !transaction.limited() {
+		return nil
+	}
+
+	t, err := tenant.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if t.Limits.MaxBalance > 0 && wallet.Balance+transaction.Amount > t.Limits.MaxBalance {
+		return ErrLimitExceeded
+	}
+
+	return nil
+}
+
+// record applies the transaction to the balance of the wallet once its journal is posted, records the
+// transaction and audits the change from before.
+func (s *service) record(ctx context.Context, before *Wallet, transaction *Transaction, journalID string) error {
+	wallet, err := s.repo.AddToBalance(ctx, transaction.WalletID, transaction.SignedAmount(), transaction.overdraws())
+	if err != nil {
+		return err
+	}
+
+	transaction.Currency = wallet.Currency
+	transaction.BalanceAfter = wallet.Balance
+	transaction.JournalID = journalID
+	transaction.WalletVersion = wallet.Version
+
+	if err := s.repo.CreateTransaction(ctx, transaction); err != nil {
+		return err
+	}
+
+	action := "wallet." + string(transaction.Type)
+
+	if err := s.auditLog.Record(ctx, action, ResourceType, wallet.ID, before, wallet); err != nil {
+		return err
+	}
+
+	return s.alert(ctx, before, wallet, transaction.ID)
+}
+
+// counterAccount returns the ledger account money of a transaction type comes from or goes to.
+// Reversals use the counter account of the transaction they reverse.
+func counterAccount(transactionType TransactionType) ledger.AccountType {
+	switch transactionType {
+	case TransactionDeposit:
+		return ledger.AccountCashInClearing
+	case TransactionWithdrawal:
+		return ledger.AccountCashOutClearing
+	case TransactionInterest:
+		return ledger.AccountInterestExpense
+	case TransactionFee:
+		return ledger.AccountFees
+	case TransactionEscrowHold, TransactionEscrowRelease, TransactionEscrowRefund:
+		return ledger.AccountEscrow
+	case TransactionAdjustment:
+		return ledger.AccountSuspense
+	default:
+		return ledger.AccountSuspense
+	}
 }
@@ -0,0 +1,95 @@
+package wallet
+
+import (
+	"time"
+)
+
+// MaxStatementPeriod is the longest period of a statement.
+const MaxStatementPeriod = 366 * 24 * time.Hour
+
+// Statement lists the movements of a wallet in the period [From, To) with the balance after each of them.
+type Statement struct {
+	WalletID       string           `json:"wallet_id"`
+	Currency       string           `json:"currency"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	OpeningBalance float64          `json:"opening_balance"`
+	ClosingBalance float64          `json:"closing_balance"`
+	TotalCredits   float64          `json:"total_credits"`
+	TotalDebits    float64          `json:"total_debits"`
+	Entries        []StatementEntry `json:"entries"`
+	GeneratedAt    time.Time        `json:"generated_at"`
+}
+
+// StatementEntry is a movement of a statement and the running balance after it.
+type StatementEntry struct {
+	TransactionID string          `json:"transaction_id"`
+	Type          TransactionType `json:"type"`
+	Direction     Direction       `json:"direction"`
+	Amount        float64         `json:"amount"`
+	Balance       float64         `json:"balance"`
+	ReversalOf    string          `json:"reversal_of,omitempty"`
+	Reference     string          `json:"reference,omitempty"`
+	Reason        string          `json:"reason,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// ParseBound parses a bound of a period, either a date (`YYYY-MM-DD`, UTC) or an RFC 3339 timestamp. A date
+// ending a period includes the whole day, so it stands for the start of the next day.
+func ParseBound(value string, end bool) (time.Time, error) {
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		if end {
+			return date.AddDate(0, 0, 1), nil
+		}
+
+		return date, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return timestamp.UTC(), nil
+}
+
+// newStatement builds the statement from the balance before the period and the transactions of the period.
+func newStatement(wallet *Wallet, from, to time.Time, openingBalance float64, transactions []*Transaction) *Statement {
+	statement := &Statement{
+		WalletID:       wallet.ID,
+		Currency:       wallet.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: RoundCents(openingBalance),
+		Entries:        make([]StatementEntry, 0, len(transactions)),
+		GeneratedAt:    time.Now().UTC(),
+	}
+
+	balance := statement.OpeningBalance
+
+	for _, transaction := range transactions {
+		balance = RoundCents(balance + transaction.SignedAmount())
+
+		if transaction.Direction == DirectionCredit {
+			statement.TotalCredits = RoundCents(statement.TotalCredits + transaction.Amount)
+		} else {
+			statement.TotalDebits = RoundCents(statement.TotalDebits + transaction.Amount)
+		}
+
+		statement.Entries = append(statement.Entries, StatementEntry{
+			TransactionID: transaction.ID,
+			Type:          transaction.Type,
+			Direction:     transaction.Direction,
+			Amount:        transaction.Amount,
+			Balance:       balance,
+			ReversalOf:    transaction.ReversalOf,
+			Reference:     transaction.Reference,
+			Reason:        transaction.Reason,
+			CreatedAt:     transaction.CreatedAt,
+		})
+	}
+
+	statement.ClosingBalance = balance
+
+	return statement
+}
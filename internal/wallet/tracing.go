@@ -0,0 +1,214 @@
+package wallet
+
+import (
+	"context"
+	"time"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/tracing"
+)
+
+type tracingService struct {
+	next Service
+}
+
+// NewTracingService decorates the Service with a span per method.
+func NewTracingService(next Service) Service {
+	return &tracingService{next: next}
+}
+
+func (s *tracingService) CreateWallet(
+	ctx context.Context,
+	currency string,
+	product string,
+) (wallet *Wallet, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/CreateWallet")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.CreateWallet(ctx, currency, product)
+}
+
+func (s *tracingService) GetWallet(ctx context.Context, id string) (wallet *Wallet, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/GetWallet")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.GetWallet(ctx, id)
+}
+
+func (s *tracingService) GetWalletAsOf(
+	ctx context.Context,
+	id string,
+	asOf time.Time,
+) (wallet *Wallet, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/GetWalletAsOf")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.GetWalletAsOf(ctx, id, asOf)
+}
+
+func (s *tracingService) Deposit(
+	ctx context.Context,
+	id string,
+	amount float64,
+	reference string,
+) (transaction *Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/Deposit")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.Deposit(ctx, id, amount, reference)
+}
+
+func (s *tracingService) Adjust(
+	ctx context.Context,
+	id string,
+	direction Direction,
+	amount float64,
+	reason string,
+) (transaction *Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/Adjust")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.Adjust(ctx, id, direction, amount, reason)
+}
+
+func (s *tracingService) Withdraw(ctx context.Context, id string, amount float64) (transaction *Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/Withdraw")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.Withdraw(ctx, id, amount)
+}
+
+func (s *tracingService) GetTransaction(ctx context.Context, id string) (transaction *Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/GetTransaction")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.GetTransaction(ctx, id)
+}
+
+func (s *tracingService) Reverse(
+	ctx context.Context,
+	transactionID string,
+	amount float64,
+	reason string,
+) (transaction *Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/Reverse")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.Reverse(ctx, transactionID, amount, reason)
+}
+
+func (s *tracingService) Transfer(
+	ctx context.Context,
+	fromID string,
+	toID string,
+	amount float64,
+	reference string,
+) (transaction *Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/Transfer")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.Transfer(ctx, fromID, toID, amount, reference)
+}
+
+func (s *tracingService) PayInterest(
+	ctx context.Context,
+	id string,
+	amount float64,
+	reason string,
+) (transaction *Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/PayInterest")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.PayInterest(ctx, id, amount, reason)
+}
+
+func (s *tracingService) ChargeFee(
+	ctx context.Context,
+	id string,
+	amount float64,
+	reason string,
+) (transaction *Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/ChargeFee")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.ChargeFee(ctx, id, amount, reason)
+}
+
+func (s *tracingService) HoldEscrow(
+	ctx context.Context,
+	id string,
+	amount float64,
+	reason string,
+) (transaction *Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/HoldEscrow")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.HoldEscrow(ctx, id, amount, reason)
+}
+
+func (s *tracingService) ReleaseEscrow(
+	ctx context.Context,
+	id string,
+	amount float64,
+	reason string,
+) (transaction *Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/ReleaseEscrow")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.ReleaseEscrow(ctx, id, amount, reason)
+}
+
+func (s *tracingService) RefundEscrow(
+	ctx context.Context,
+	id string,
+	amount float64,
+	reason string,
+) (transaction *Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/RefundEscrow")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.RefundEscrow(ctx, id, amount, reason)
+}
+
+func (s *tracingService) Statement(
+	ctx context.Context,
+	id string,
+	from time.Time,
+	to time.Time,
+) (statement *Statement, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/Statement")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.Statement(ctx, id, from, to)
+}
+
+func (s *tracingService) Freeze(ctx context.Context, id string, reason string) (wallet *Wallet, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/Freeze")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.Freeze(ctx, id, reason)
+}
+
+func (s *tracingService) Unfreeze(ctx context.Context, id string) (wallet *Wallet, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/Unfreeze")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.Unfreeze(ctx, id)
+}
+
+func (s *tracingService) SetCreditLimit(
+	ctx context.Context,
+	id string,
+	creditLimit float64,
+) (wallet *Wallet, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/SetCreditLimit")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.SetCreditLimit(ctx, id, creditLimit)
+}
+
+func (s *tracingService) Overdraft(ctx context.Context, id string) (overdraft *Overdraft, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.Service/Overdraft")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.Overdraft(ctx, id)
+}
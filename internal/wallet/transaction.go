@@ -0,0 +1,129 @@
+package wallet
+
+import (
+	"math"
+	"time"
+	"unicode/utf8"
+
+	"tribe-payments-wallet-golang-interview-assignment/internal/ledger"
+)
+
+// TransactionType is the kind of movement recorded for a wallet.
+type TransactionType string
+
+const (
+	TransactionDeposit    TransactionType = "deposit"
+	TransactionWithdrawal TransactionType = "withdrawal"
+	// TransactionReversal compensates all or part of a deposit or withdrawal, referenced by ReversalOf.
+	TransactionReversal TransactionType = "reversal"
+	// TransactionOpeningBalance carries the balances of wallets created before movements were recorded.
+	TransactionOpeningBalance TransactionType = "opening_balance"
+	// TransactionTransferOut and TransactionTransferIn are the two sides of a transfer between wallets,
+	// sharing their journal.
+	TransactionTransferOut TransactionType = "transfer_out"
+	TransactionTransferIn  TransactionType = "transfer_in"
+	// TransactionInterest pays the interest accrued by a savings wallet.
+	TransactionInterest TransactionType = "interest"
+	// TransactionFee charges a fee, e.g. the daily interest and fee of an overdraft.
+	TransactionFee TransactionType = "fee"
+	// TransactionEscrowHold locks money of the payer of an escrow, TransactionEscrowRelease pays it to the payee
+	// and TransactionEscrowRefund pays it back to the payer.
+	TransactionEscrowHold    TransactionType = "escrow_hold"
+	TransactionEscrowRelease TransactionType = "escrow_release"
+	TransactionEscrowRefund  TransactionType = "escrow_refund"
+	// TransactionAdjustment books a balance correction approved by operators against the suspense account.
+	TransactionAdjustment TransactionType = "adjustment"
+)
+
+// MaxReferenceLength is the length of the remittance information of ISO 20022 credit transfers, the longest
+// payment reference of a transaction.
+const MaxReferenceLength = 140
+
+// Transaction is a movement of a wallet balance. Amount is always positive, Direction tells whether the
+// movement credited or debited the wallet.
+type Transaction struct {
+	ID             string          `json:"id" db:"id"`
+	TenantID       string          `json:"tenant_id" db:"tenant_id"`
+	WalletID       string          `json:"wallet_id" db:"wallet_id"`
+	Type           TransactionType `json:"type" db:"type"`
+	Direction      Direction       `json:"direction" db:"direction"`
+	Amount         float64         `json:"amount" db:"amount"`
+	Currency       string          `json:"currency" db:"currency"`
+	BalanceAfter   float64         `json:"balance_after" db:"balance_after"`
+	ReversalOf     string          `json:"reversal_of,omitempty" db:"reversal_of"`
+	ReversedAmount float64         `json:"reversed_amount" db:"reversed_amount"`
+	Reason         string          `json:"reason,omitempty" db:"reason"`
+	// Reference is the payment reference of a deposit given by the client, e.g. the end-to-end ID of the bank
+	// transfer, used to match the deposit against the settlement files of the bank.
+	Reference string    `json:"reference,omitempty" db:"reference"`
+	JournalID string    `json:"journal_id,omitempty" db:"journal_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// WalletVersion is the version of the wallet once the transaction is recorded. It is only set on the
+	// transactions returned by the movements of the Service, not stored.
+	WalletVersion int64 `json:"-" db:"-"`
+}
+
+// Direction tells whether a transaction credits or debits the wallet.
+type Direction string
+
+const (
+	DirectionCredit Direction = "credit"
+	DirectionDebit  Direction = "debit"
+)
+
+// SignedAmount returns the amount added to the wallet balance by the transaction.
+func (t *Transaction) SignedAmount() float64 {
+	if t.Direction == DirectionDebit {
+		return -t.Amount
+	}
+
+	return t.Amount
+}
+
+// Reversible tells whether the transaction type can be reversed at all.
+func (t *Transaction) Reversible() bool {
+	return t.Type == TransactionDeposit || t.Type == TransactionWithdrawal
+}
+
+// limited tells whether the transaction is subject to the limits of the tenant, unlike e.g. interest and escrow
+// payouts.
+func (t *Transaction) limited() bool {
+	switch t.Type {
+	case TransactionDeposit, TransactionWithdrawal, TransactionReversal, TransactionTransferIn, TransactionTransferOut:
+		return true
+	default:
+		return false
+	}
+}
+
+// overdraws tells whether the transaction may debit the wallet beyond its credit limit, like the fees charged
+// to overdrawn wallets.
+func (t *Transaction) overdraws() bool {
+	return t.Type == TransactionFee
+}
+
+// ReversibleAmount returns the part of the transaction not reversed yet.
+func (t *Transaction) ReversibleAmount() float64 {
+	return RoundCents(t.Amount - t.ReversedAmount)
+}
+
+// RoundCents rounds amount to the cents stored by the database, so differences of amounts stored as DECIMAL(20,2)
+// do not accumulate floating point errors.
+func RoundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// WholeCents tells whether amount has at most two decimals, up to floating point precision, like the postings of
+// the ledger.
+func WholeCents(amount float64) bool {
+	return ledger.WholeCents(amount)
+}
+
+// Truncate shortens value to at most length characters, e.g. to fit an error message in its column.
+func Truncate(value string, length int) string {
+	if utf8.RuneCountInString(value) <= length {
+		return value
+	}
+
+	return string([]rune(value)[:length])
+}
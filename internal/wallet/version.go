@@ -0,0 +1,44 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+)
+
+// AnyVersion is the version matching any existing wallet, the `*` of If-Match.
+const AnyVersion int64 = -1
+
+type versionContextKey struct{}
+
+// NewVersionContext returns a copy of ctx making the operations on a wallet fail with ErrVersionMismatch unless
+// the wallet they update is at version, e.g. the version a client read before deciding to act, or exists at all
+// for AnyVersion.
+func NewVersionContext(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, versionContextKey{}, version)
+}
+
+// expectedVersion returns the version stored in ctx, if any.
+func expectedVersion(ctx context.Context) (int64, bool) {
+	version, ok := ctx.Value(versionContextKey{}).(int64)
+
+	return version, ok
+}
+
+// checkVersion returns ErrVersionMismatch when ctx expects another version of the wallet.
+func checkVersion(ctx context.Context, wallet *Wallet) error {
+	if version, ok := expectedVersion(ctx); ok && version != AnyVersion && version != wallet.Version {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// CheckFound returns ErrVersionMismatch instead of ErrWalletNotFound when ctx expects a version of the wallet, as
+// a wallet that does not exist matches no ETag, not even `*`. Other errors are returned as is.
+func CheckFound(ctx context.Context, err error) error {
+	if _, ok := expectedVersion(ctx); ok && errors.Is(err, ErrWalletNotFound) {
+		return ErrVersionMismatch
+	}
+
+	return err
+}
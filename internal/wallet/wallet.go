@@ -1,13 +1,48 @@
 package wallet
 
 import (
+	"math"
 	"time"
 )
 
+// Status tells whether a wallet accepts movements.
+type Status string
+
+const (
+	StatusActive Status = "active"
+	// StatusFrozen wallets reject every movement until they are unfrozen, e.g. while a balance
+	// drift found by the reconciliation is investigated.
+	StatusFrozen Status = "frozen"
+)
+
 type Wallet struct {
-	ID        string    `json:"id" db:"id"`
-	Balance   float64   `json:"balance" db:"balance"`
-	Currency  string    `json:"currency" db:"currency"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID           string  `json:"id" db:"id"`
+	TenantID     string  `json:"tenant_id" db:"tenant_id"`
+	Balance      float64 `json:"balance" db:"balance"`
+	Currency     string  `json:"currency" db:"currency"`
+	Status       Status  `json:"status" db:"status"`
+	StatusReason string  `json:"status_reason,omitempty" db:"status_reason"`
+	// Product is the product of the tenant the wallet was opened with, empty for wallets earning no interest.
+	Product string `json:"product,omitempty" db:"product"`
+	// CreditLimit is how far below zero the balance may go, 0 for wallets without overdraft.
+	CreditLimit float64   `json:"credit_limit" db:"credit_limit"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	// Version is incremented on every update of the wallet.
+	Version int64 `json:"version" db:"version"`
+}
+
+// AvailableBalance returns how much the wallet can spend, its balance plus its credit limit.
+func (w *Wallet) AvailableBalance() float64 {
+	return w.Balance + w.CreditLimit
+}
+
+// Utilisation returns the part of the credit limit in use, in percent with two decimals. It exceeds 100 when fees
+// overdraw the wallet beyond its limit, and is 0 for wallets without credit limit.
+func (w *Wallet) Utilisation() float64 {
+	if w.CreditLimit <= 0 || w.Balance >= 0 {
+		return 0
+	}
+
+	return math.Round(-w.Balance/w.CreditLimit*10000) / 100
 }
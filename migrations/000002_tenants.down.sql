@@ -0,0 +1,5 @@
+DROP INDEX IX_wallets_tenant_id ON wallets;
+
+ALTER TABLE wallets DROP CONSTRAINT DF_wallets_tenant_id;
+
+ALTER TABLE wallets DROP COLUMN tenant_id;
@@ -0,0 +1,4 @@
+ALTER TABLE wallets ADD tenant_id VARCHAR(64) NOT NULL
+    CONSTRAINT DF_wallets_tenant_id DEFAULT 'default';
+
+CREATE INDEX IX_wallets_tenant_id ON wallets (tenant_id, id);
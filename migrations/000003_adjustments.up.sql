@@ -0,0 +1,26 @@
+CREATE TABLE adjustments (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    wallet_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_adjustments_wallet_id REFERENCES wallets (id),
+    direction VARCHAR(6) NOT NULL
+        CONSTRAINT CK_adjustments_direction CHECK (direction IN ('credit', 'debit')),
+    amount DECIMAL(20,2) NOT NULL
+        CONSTRAINT CK_adjustments_amount CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    reason NVARCHAR(500) NOT NULL,
+    evidence_reference NVARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL
+        CONSTRAINT CK_adjustments_status CHECK (status IN ('pending', 'approved', 'rejected', 'failed')),
+    proposed_by NVARCHAR(128) NOT NULL,
+    proposed_at DATETIME NOT NULL,
+    reviewed_by NVARCHAR(128) NULL,
+    reviewed_at DATETIME NULL,
+    review_comment NVARCHAR(500) NULL,
+    failure_reason NVARCHAR(500) NULL,
+    CONSTRAINT CK_adjustments_four_eyes CHECK (reviewed_by IS NULL OR reviewed_by <> proposed_by)
+);
+
+CREATE INDEX IX_adjustments_tenant_id ON adjustments (tenant_id, status, proposed_at);
+
+CREATE INDEX IX_adjustments_wallet_id ON adjustments (tenant_id, wallet_id, proposed_at);
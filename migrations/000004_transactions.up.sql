@@ -0,0 +1,31 @@
+CREATE TABLE transactions (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    wallet_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_transactions_wallet_id REFERENCES wallets (id),
+    type VARCHAR(32) NOT NULL,
+    direction VARCHAR(6) NOT NULL
+        CONSTRAINT CK_transactions_direction CHECK (direction IN ('credit', 'debit')),
+    amount DECIMAL(20,2) NOT NULL
+        CONSTRAINT CK_transactions_amount CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    balance_after DECIMAL(20,2) NOT NULL,
+    reversal_of VARCHAR(36) NULL
+        CONSTRAINT FK_transactions_reversal_of REFERENCES transactions (id),
+    reversed_amount DECIMAL(20,2) NOT NULL
+        CONSTRAINT DF_transactions_reversed_amount DEFAULT 0,
+    reason NVARCHAR(500) NULL,
+    created_at DATETIME NOT NULL,
+    CONSTRAINT CK_transactions_reversed_amount CHECK (reversed_amount >= 0 AND reversed_amount <= amount)
+);
+
+CREATE INDEX IX_transactions_wallet_id ON transactions (tenant_id, wallet_id, created_at);
+
+CREATE INDEX IX_transactions_reversal_of ON transactions (reversal_of);
+
+-- Wallets created before movements were recorded start with their current balance.
+INSERT INTO transactions (id, tenant_id, wallet_id, type, direction, amount, currency, balance_after, created_at)
+SELECT LOWER(CONVERT(VARCHAR(36), NEWID())), tenant_id, id, 'opening_balance', 'credit', balance, currency, balance,
+       GETUTCDATE()
+FROM wallets
+WHERE balance > 0;
@@ -0,0 +1,3 @@
+DROP TRIGGER TR_audit_log_append_only;
+
+DROP TABLE audit_log;
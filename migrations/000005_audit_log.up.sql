@@ -0,0 +1,26 @@
+CREATE TABLE audit_log (
+    id BIGINT IDENTITY(1,1) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    sequence BIGINT NOT NULL,
+    occurred_at DATETIME2(6) NOT NULL,
+    actor_type VARCHAR(16) NOT NULL,
+    actor_id NVARCHAR(128) NULL,
+    actor_ip VARCHAR(45) NULL,
+    action VARCHAR(64) NOT NULL,
+    resource_type VARCHAR(32) NOT NULL,
+    resource_id VARCHAR(36) NOT NULL,
+    before_state NVARCHAR(MAX) NULL,
+    after_state NVARCHAR(MAX) NULL,
+    previous_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL,
+    CONSTRAINT UQ_audit_log_tenant_sequence UNIQUE (tenant_id, sequence)
+);
+
+CREATE INDEX IX_audit_log_resource ON audit_log (tenant_id, resource_type, resource_id);
+
+-- The audit log is append-only: updates and deletes are refused. The hash chain detects changes made
+-- by anyone able to drop the trigger.
+EXEC('CREATE TRIGGER TR_audit_log_append_only ON audit_log INSTEAD OF UPDATE, DELETE AS
+BEGIN
+    THROW 51000, ''audit_log is append-only'', 1;
+END');
@@ -0,0 +1,9 @@
+ALTER TABLE transactions DROP CONSTRAINT FK_transactions_journal_id;
+
+ALTER TABLE transactions DROP COLUMN journal_id;
+
+DROP TABLE postings;
+
+DROP TABLE journals;
+
+DROP TABLE ledger_accounts;
@@ -0,0 +1,81 @@
+CREATE TABLE ledger_accounts (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    wallet_id VARCHAR(36) NULL
+        CONSTRAINT FK_ledger_accounts_wallet_id REFERENCES wallets (id),
+    created_at DATETIME NOT NULL,
+    CONSTRAINT CK_ledger_accounts_wallet CHECK (
+        (type = 'wallet' AND wallet_id IS NOT NULL) OR (type <> 'wallet' AND wallet_id IS NULL)
+    )
+);
+
+CREATE UNIQUE INDEX UX_ledger_accounts_wallet_id ON ledger_accounts (wallet_id) WHERE wallet_id IS NOT NULL;
+
+CREATE UNIQUE INDEX UX_ledger_accounts_system ON ledger_accounts (tenant_id, type, currency) WHERE wallet_id IS NULL;
+
+CREATE TABLE journals (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    description NVARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE postings (
+    id BIGINT IDENTITY(1,1) PRIMARY KEY,
+    journal_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_postings_journal_id REFERENCES journals (id),
+    account_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_postings_account_id REFERENCES ledger_accounts (id),
+    amount DECIMAL(20,2) NOT NULL
+        CONSTRAINT CK_postings_amount CHECK (amount <> 0)
+);
+
+CREATE INDEX IX_postings_account_id ON postings (account_id) INCLUDE (amount);
+
+CREATE INDEX IX_postings_journal_id ON postings (journal_id) INCLUDE (amount);
+
+ALTER TABLE transactions ADD journal_id VARCHAR(36) NULL
+    CONSTRAINT FK_transactions_journal_id REFERENCES journals (id);
+
+-- Existing wallets and movements are carried over: every wallet gets its account and every transaction
+-- a journal, with the same identifier, against the clearing account of the movement it records.
+SELECT t.id, t.tenant_id, t.wallet_id, t.currency, t.type, t.created_at,
+       CASE t.direction WHEN 'credit' THEN t.amount ELSE -t.amount END AS amount,
+       CASE COALESCE(o.type, t.type)
+           WHEN 'deposit' THEN 'cash_in_clearing'
+           WHEN 'withdrawal' THEN 'cash_out_clearing'
+           ELSE 'suspense'
+       END AS counter_type
+INTO #movements
+FROM transactions t
+LEFT JOIN transactions o ON o.id = t.reversal_of;
+
+INSERT INTO ledger_accounts (id, tenant_id, type, currency, wallet_id, created_at)
+SELECT LOWER(CONVERT(VARCHAR(36), NEWID())), tenant_id, 'wallet', currency, id, GETUTCDATE()
+FROM wallets;
+
+INSERT INTO ledger_accounts (id, tenant_id, type, currency, wallet_id, created_at)
+SELECT LOWER(CONVERT(VARCHAR(36), NEWID())), tenant_id, counter_type, currency, NULL, GETUTCDATE()
+FROM (SELECT DISTINCT tenant_id, counter_type, currency FROM #movements) AS counters;
+
+INSERT INTO journals (id, tenant_id, currency, description, created_at)
+SELECT id, tenant_id, currency, type, created_at
+FROM #movements;
+
+INSERT INTO postings (journal_id, account_id, amount)
+SELECT m.id, a.id, m.amount
+FROM #movements m
+JOIN ledger_accounts a ON a.wallet_id = m.wallet_id;
+
+INSERT INTO postings (journal_id, account_id, amount)
+SELECT m.id, a.id, -m.amount
+FROM #movements m
+JOIN ledger_accounts a ON a.tenant_id = m.tenant_id AND a.type = m.counter_type AND a.currency = m.currency
+    AND a.wallet_id IS NULL;
+
+UPDATE transactions SET journal_id = id;
+
+DROP TABLE #movements;
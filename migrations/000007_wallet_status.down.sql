@@ -0,0 +1,7 @@
+ALTER TABLE wallets DROP COLUMN status_reason;
+
+ALTER TABLE wallets DROP CONSTRAINT CK_wallets_status;
+
+ALTER TABLE wallets DROP CONSTRAINT DF_wallets_status;
+
+ALTER TABLE wallets DROP COLUMN status;
@@ -0,0 +1,5 @@
+ALTER TABLE wallets ADD status VARCHAR(16) NOT NULL
+    CONSTRAINT DF_wallets_status DEFAULT 'active'
+    CONSTRAINT CK_wallets_status CHECK (status IN ('active', 'frozen'));
+
+ALTER TABLE wallets ADD status_reason NVARCHAR(500) NULL;
@@ -0,0 +1,7 @@
+DROP TABLE settlement_items;
+
+DROP TABLE settlement_imports;
+
+DROP INDEX IX_transactions_reference ON transactions;
+
+ALTER TABLE transactions DROP COLUMN reference;
@@ -0,0 +1,46 @@
+ALTER TABLE transactions ADD reference NVARCHAR(140) NULL;
+
+CREATE INDEX IX_transactions_reference ON transactions (tenant_id, reference) WHERE reference IS NOT NULL;
+
+CREATE TABLE settlement_imports (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    format VARCHAR(16) NOT NULL
+        CONSTRAINT CK_settlement_imports_format CHECK (format IN ('csv', 'camt053')),
+    file_name NVARCHAR(255) NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    lines INT NOT NULL,
+    imported_by NVARCHAR(128) NOT NULL,
+    imported_at DATETIME NOT NULL,
+    CONSTRAINT UQ_settlement_imports_checksum UNIQUE (tenant_id, checksum)
+);
+
+CREATE INDEX IX_settlement_imports_tenant_id ON settlement_imports (tenant_id, imported_at);
+
+CREATE TABLE settlement_items (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    import_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_settlement_items_import_id REFERENCES settlement_imports (id),
+    line INT NOT NULL,
+    reference NVARCHAR(140) NULL,
+    amount DECIMAL(20,2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    booking_date DATE NOT NULL,
+    description NVARCHAR(500) NULL,
+    status VARCHAR(16) NOT NULL
+        CONSTRAINT CK_settlement_items_status
+            CHECK (status IN ('matched', 'unmatched', 'ambiguous', 'resolved', 'dismissed')),
+    transaction_id VARCHAR(36) NULL
+        CONSTRAINT FK_settlement_items_transaction_id REFERENCES transactions (id),
+    candidate_ids VARCHAR(MAX) NULL,
+    resolved_by NVARCHAR(128) NULL,
+    resolved_at DATETIME NULL,
+    resolution_note NVARCHAR(500) NULL
+);
+
+CREATE INDEX IX_settlement_items_import_id ON settlement_items (tenant_id, import_id, line);
+
+-- A deposit settles at most one line of the bank statements.
+CREATE UNIQUE INDEX UX_settlement_items_transaction_id ON settlement_items (transaction_id)
+    WHERE transaction_id IS NOT NULL;
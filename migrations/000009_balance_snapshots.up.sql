@@ -0,0 +1,13 @@
+-- The balance of a wallet at the end of balance_date (UTC), i.e. of its transactions created before the next day.
+CREATE TABLE balance_snapshots (
+    tenant_id VARCHAR(64) NOT NULL,
+    wallet_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_balance_snapshots_wallet_id REFERENCES wallets (id),
+    balance_date DATE NOT NULL,
+    balance DECIMAL(20,2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    taken_at DATETIME NOT NULL,
+    CONSTRAINT PK_balance_snapshots PRIMARY KEY (tenant_id, wallet_id, balance_date)
+);
+
+CREATE INDEX IX_balance_snapshots_balance_date ON balance_snapshots (tenant_id, balance_date);
@@ -0,0 +1,3 @@
+ALTER TABLE wallets DROP CONSTRAINT DF_wallets_version;
+
+ALTER TABLE wallets DROP COLUMN version;
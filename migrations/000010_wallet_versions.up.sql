@@ -0,0 +1,2 @@
+-- Incremented on every update of the wallet, enforced by conditional updates for optimistic concurrency.
+ALTER TABLE wallets ADD version BIGINT NOT NULL CONSTRAINT DF_wallets_version DEFAULT 1;
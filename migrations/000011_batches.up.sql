@@ -0,0 +1,37 @@
+CREATE TABLE batches (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL
+        CONSTRAINT CK_batches_status CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    atomic BIT NOT NULL,
+    operations INT NOT NULL,
+    error NVARCHAR(500) NULL,
+    actor_type VARCHAR(16) NOT NULL,
+    actor_id NVARCHAR(128) NULL,
+    actor_ip VARCHAR(45) NULL,
+    created_at DATETIME NOT NULL,
+    started_at DATETIME NULL,
+    heartbeat_at DATETIME NULL,
+    finished_at DATETIME NULL
+);
+
+CREATE INDEX IX_batches_status ON batches (status, created_at);
+
+CREATE TABLE batch_items (
+    batch_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_batch_items_batch_id REFERENCES batches (id),
+    line INT NOT NULL,
+    tenant_id VARCHAR(64) NOT NULL,
+    type VARCHAR(16) NOT NULL
+        CONSTRAINT CK_batch_items_type CHECK (type IN ('deposit', 'withdraw')),
+    wallet_id VARCHAR(36) NOT NULL,
+    amount DECIMAL(20,2) NOT NULL,
+    reference NVARCHAR(140) NULL,
+    status VARCHAR(16) NOT NULL
+        CONSTRAINT CK_batch_items_status CHECK (status IN ('pending', 'succeeded', 'failed', 'rolled_back')),
+    transaction_id VARCHAR(36) NULL
+        CONSTRAINT FK_batch_items_transaction_id REFERENCES transactions (id),
+    error NVARCHAR(500) NULL,
+    processed_at DATETIME NULL,
+    CONSTRAINT PK_batch_items PRIMARY KEY (batch_id, line)
+);
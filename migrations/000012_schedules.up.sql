@@ -0,0 +1,51 @@
+CREATE TABLE schedules (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    from_wallet_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_schedules_from_wallet_id REFERENCES wallets (id),
+    to_wallet_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_schedules_to_wallet_id REFERENCES wallets (id),
+    amount DECIMAL(20,2) NOT NULL
+        CONSTRAINT CK_schedules_amount CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    reference NVARCHAR(140) NULL,
+    recurrence VARCHAR(100) NULL,
+    on_insufficient_funds VARCHAR(8) NOT NULL
+        CONSTRAINT CK_schedules_on_insufficient_funds CHECK (on_insufficient_funds IN ('skip', 'retry')),
+    status VARCHAR(16) NOT NULL
+        CONSTRAINT CK_schedules_status CHECK (status IN ('active', 'completed', 'cancelled')),
+    next_run_at DATETIME NULL,
+    end_at DATETIME NULL,
+    attempts INT NOT NULL
+        CONSTRAINT DF_schedules_attempts DEFAULT 0,
+    retry_at DATETIME NULL,
+    last_run_at DATETIME NULL,
+    last_error NVARCHAR(500) NULL,
+    actor_type VARCHAR(16) NOT NULL,
+    actor_id NVARCHAR(128) NULL,
+    actor_ip VARCHAR(45) NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IX_schedules_due ON schedules (status, next_run_at);
+
+CREATE INDEX IX_schedules_tenant_id ON schedules (tenant_id, created_at);
+
+CREATE TABLE schedule_runs (
+    schedule_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_schedule_runs_schedule_id REFERENCES schedules (id),
+    due_at DATETIME NOT NULL,
+    attempt INT NOT NULL,
+    tenant_id VARCHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL
+        CONSTRAINT CK_schedule_runs_status CHECK (status IN ('succeeded', 'failed', 'skipped')),
+    transaction_id VARCHAR(36) NULL
+        CONSTRAINT FK_schedule_runs_transaction_id REFERENCES transactions (id),
+    error NVARCHAR(500) NULL,
+    executed_at DATETIME NOT NULL,
+    CONSTRAINT PK_schedule_runs PRIMARY KEY (schedule_id, due_at, attempt)
+);
+
+-- Every occurrence of a schedule transfers the money at most once.
+CREATE UNIQUE INDEX UX_schedule_runs_succeeded ON schedule_runs (schedule_id, due_at) WHERE status = 'succeeded';
@@ -0,0 +1,5 @@
+DROP TABLE interest_accruals;
+
+DROP TABLE interest_capitalisations;
+
+ALTER TABLE wallets DROP COLUMN product;
@@ -0,0 +1,39 @@
+-- The product of the tenant the wallet was opened with, NULL for wallets earning no interest.
+ALTER TABLE wallets ADD product VARCHAR(64) NULL;
+
+CREATE TABLE interest_capitalisations (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    wallet_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_interest_capitalisations_wallet_id REFERENCES wallets (id),
+    period_end DATE NOT NULL,
+    -- The accruals of the period plus the remainder carried from the previous capitalisation.
+    accrued DECIMAL(28,12) NOT NULL,
+    paid DECIMAL(20,2) NOT NULL,
+    -- The fraction of a cent left unpaid, carried to the next capitalisation.
+    carried DECIMAL(28,12) NOT NULL,
+    transaction_id VARCHAR(36) NULL
+        CONSTRAINT FK_interest_capitalisations_transaction_id REFERENCES transactions (id),
+    created_at DATETIME NOT NULL
+);
+
+-- Every period of a wallet is capitalised at most once.
+CREATE UNIQUE INDEX UX_interest_capitalisations_period
+    ON interest_capitalisations (tenant_id, wallet_id, period_end);
+
+CREATE TABLE interest_accruals (
+    tenant_id VARCHAR(64) NOT NULL,
+    wallet_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_interest_accruals_wallet_id REFERENCES wallets (id),
+    accrual_date DATE NOT NULL,
+    product VARCHAR(64) NOT NULL,
+    balance DECIMAL(20,2) NOT NULL,
+    amount DECIMAL(28,12) NOT NULL,
+    -- NULL until the accrual is paid into the wallet.
+    capitalisation_id VARCHAR(36) NULL
+        CONSTRAINT FK_interest_accruals_capitalisation_id REFERENCES interest_capitalisations (id),
+    created_at DATETIME NOT NULL,
+    CONSTRAINT PK_interest_accruals PRIMARY KEY (tenant_id, wallet_id, accrual_date)
+);
+
+CREATE INDEX IX_interest_accruals_unpaid ON interest_accruals (tenant_id, capitalisation_id, accrual_date);
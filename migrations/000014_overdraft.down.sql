@@ -0,0 +1,9 @@
+DROP TABLE overdraft_charges;
+
+DROP TABLE overdraft_alerts;
+
+ALTER TABLE wallets DROP CONSTRAINT CK_wallets_credit_limit;
+
+ALTER TABLE wallets DROP CONSTRAINT DF_wallets_credit_limit;
+
+ALTER TABLE wallets DROP COLUMN credit_limit;
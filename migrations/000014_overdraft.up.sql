@@ -0,0 +1,37 @@
+-- How far below zero the balance of the wallet may go, 0 for wallets without overdraft.
+ALTER TABLE wallets ADD credit_limit DECIMAL(20,2) NOT NULL
+    CONSTRAINT DF_wallets_credit_limit DEFAULT 0
+    CONSTRAINT CK_wallets_credit_limit CHECK (credit_limit >= 0);
+
+CREATE TABLE overdraft_alerts (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    wallet_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_overdraft_alerts_wallet_id REFERENCES wallets (id),
+    threshold DECIMAL(9,2) NOT NULL,
+    utilisation DECIMAL(9,2) NOT NULL,
+    balance DECIMAL(20,2) NOT NULL,
+    credit_limit DECIMAL(20,2) NOT NULL,
+    -- The movement that crossed the threshold, NULL when the credit limit was lowered.
+    transaction_id VARCHAR(36) NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IX_overdraft_alerts_wallet_id ON overdraft_alerts (tenant_id, wallet_id, created_at);
+
+CREATE TABLE overdraft_charges (
+    tenant_id VARCHAR(64) NOT NULL,
+    wallet_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_overdraft_charges_wallet_id REFERENCES wallets (id),
+    charge_date DATE NOT NULL,
+    balance DECIMAL(20,2) NOT NULL,
+    interest DECIMAL(28,12) NOT NULL,
+    fee DECIMAL(20,2) NOT NULL,
+    charged DECIMAL(20,2) NOT NULL,
+    -- The fraction of a cent, or the whole charge of a frozen wallet, left to charge the next time.
+    carried DECIMAL(28,12) NOT NULL,
+    transaction_id VARCHAR(36) NULL
+        CONSTRAINT FK_overdraft_charges_transaction_id REFERENCES transactions (id),
+    created_at DATETIME NOT NULL,
+    CONSTRAINT PK_overdraft_charges PRIMARY KEY (tenant_id, wallet_id, charge_date)
+);
@@ -0,0 +1,3 @@
+DROP TABLE escrow_events;
+
+DROP TABLE escrows;
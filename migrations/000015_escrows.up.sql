@@ -0,0 +1,59 @@
+CREATE TABLE escrows (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    payer_wallet_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_escrows_payer_wallet_id REFERENCES wallets (id),
+    payee_wallet_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_escrows_payee_wallet_id REFERENCES wallets (id),
+    amount DECIMAL(20,2) NOT NULL
+        CONSTRAINT CK_escrows_amount CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    reference NVARCHAR(140) NULL,
+    status VARCHAR(16) NOT NULL
+        CONSTRAINT CK_escrows_status CHECK (status IN ('held', 'released', 'refunded', 'split', 'expired')),
+    released DECIMAL(20,2) NOT NULL
+        CONSTRAINT DF_escrows_released DEFAULT 0,
+    refunded DECIMAL(20,2) NOT NULL
+        CONSTRAINT DF_escrows_refunded DEFAULT 0,
+    expires_at DATETIME NULL,
+    on_expiry VARCHAR(8) NOT NULL
+        CONSTRAINT CK_escrows_on_expiry CHECK (on_expiry IN ('refund', 'release')),
+    -- An expiry failing, e.g. on a frozen wallet, is tried again at retry_at.
+    retry_at DATETIME NULL,
+    last_error NVARCHAR(500) NULL,
+    hold_transaction_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_escrows_hold_transaction_id REFERENCES transactions (id),
+    actor_type VARCHAR(16) NOT NULL,
+    actor_id NVARCHAR(128) NULL,
+    actor_ip VARCHAR(45) NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    CONSTRAINT CK_escrows_settled CHECK (released + refunded IN (0, amount))
+);
+
+CREATE INDEX IX_escrows_due ON escrows (status, expires_at);
+
+CREATE INDEX IX_escrows_tenant_id ON escrows (tenant_id, created_at);
+
+CREATE TABLE escrow_events (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    escrow_id VARCHAR(36) NOT NULL
+        CONSTRAINT FK_escrow_events_escrow_id REFERENCES escrows (id),
+    -- NULL for the creation of the escrow.
+    from_status VARCHAR(16) NULL,
+    to_status VARCHAR(16) NOT NULL,
+    released DECIMAL(20,2) NOT NULL,
+    refunded DECIMAL(20,2) NOT NULL,
+    payer_transaction_id VARCHAR(36) NULL
+        CONSTRAINT FK_escrow_events_payer_transaction_id REFERENCES transactions (id),
+    payee_transaction_id VARCHAR(36) NULL
+        CONSTRAINT FK_escrow_events_payee_transaction_id REFERENCES transactions (id),
+    reason NVARCHAR(500) NULL,
+    actor_type VARCHAR(16) NOT NULL,
+    actor_id NVARCHAR(128) NULL,
+    actor_ip VARCHAR(45) NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IX_escrow_events_escrow_id ON escrow_events (escrow_id, created_at);
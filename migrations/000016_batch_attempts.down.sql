@@ -0,0 +1,3 @@
+ALTER TABLE batches DROP CONSTRAINT DF_batches_attempts;
+
+ALTER TABLE batches DROP COLUMN attempts;
@@ -0,0 +1,2 @@
+-- How many times a processor claimed the batch, it fails once it reaches the maximum attempts.
+ALTER TABLE batches ADD attempts INT NOT NULL CONSTRAINT DF_batches_attempts DEFAULT 0;
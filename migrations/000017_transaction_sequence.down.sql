@@ -0,0 +1,7 @@
+DROP INDEX IX_transactions_sequence ON transactions;
+
+ALTER TABLE transactions DROP CONSTRAINT DF_transactions_sequence;
+
+ALTER TABLE transactions DROP COLUMN sequence;
+
+DROP SEQUENCE transactions_sequence;
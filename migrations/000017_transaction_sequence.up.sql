@@ -0,0 +1,12 @@
+-- The movements of a wallet are ordered by sequence: created_at only has the precision of DATETIME, and the random
+-- IDs cannot tell the order of movements recorded within the same tick.
+CREATE SEQUENCE transactions_sequence AS BIGINT START WITH 1 INCREMENT BY 1;
+
+ALTER TABLE transactions ADD sequence BIGINT NOT NULL
+    CONSTRAINT DF_transactions_sequence DEFAULT NEXT VALUE FOR transactions_sequence;
+
+-- The existing movements got the first numbers in no particular order, they keep the order they were listed in.
+EXEC('WITH ordered AS (SELECT sequence, ROW_NUMBER() OVER (ORDER BY created_at, id) AS position FROM transactions)
+UPDATE ordered SET sequence = position');
+
+CREATE INDEX IX_transactions_sequence ON transactions (tenant_id, wallet_id, sequence);
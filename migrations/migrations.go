@@ -0,0 +1,41 @@
+// Package migrations embeds the golang-migrate SQL migrations of the database schema.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion returns the version of the newest migration, i.e. the version
+// golang-migrate records in `schema_migrations` once every migration is applied.
+func LatestVersion() (uint, error) {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+
+	for _, entry := range entries {
+		rawVersion, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+
+		version, err := strconv.ParseUint(rawVersion, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if uint(version) > latest {
+			latest = uint(version)
+		}
+	}
+
+	return latest, nil
+}